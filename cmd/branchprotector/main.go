@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/jenkins-x/lighthouse/pkg/branchprotector"
+	"github.com/jenkins-x/lighthouse/pkg/config"
+	configutil "github.com/jenkins-x/lighthouse/pkg/config/util"
+	"github.com/jenkins-x/lighthouse/pkg/logrusutil"
+	"github.com/jenkins-x/lighthouse/pkg/util"
+	"github.com/sirupsen/logrus"
+)
+
+type options struct {
+	configPath    string
+	jobConfigPath string
+	dryRun        bool
+}
+
+func gatherOptions(fs *flag.FlagSet, args ...string) options {
+	logrusutil.ComponentInit("lighthouse-branchprotector")
+
+	var o options
+	fs.StringVar(&o.configPath, "config-path", "", "Path to config.yaml.")
+	fs.StringVar(&o.jobConfigPath, "job-config-path", "", "Path to the job configs.")
+	fs.BoolVar(&o.dryRun, "dry-run", false, "Only report the branch protection changes which would be made, as JSON on the standard output.")
+
+	err := fs.Parse(args)
+	if err != nil {
+		logrus.WithError(err).Fatal("Invalid options")
+	}
+	o.configPath = configutil.PathOrDefault(o.configPath)
+	return o
+}
+
+func main() {
+	o := gatherOptions(flag.NewFlagSet(os.Args[0], flag.ExitOnError), os.Args[1:]...)
+
+	cfg, err := config.Load(o.configPath, o.jobConfigPath)
+	if err != nil {
+		logrus.WithError(err).Fatalf("failed to load config from %s", o.configPath)
+	}
+	configAgent := &config.Agent{}
+	configAgent.Set(cfg)
+
+	// branch protection is only available through the GitHub REST API so fail before doing anything else
+	if kind := util.GitKind(configAgent.Config); kind != "github" {
+		logrus.Fatalf("branch protection is only supported for github, not for the %s git kind", kind)
+	}
+	_, scmClient, _, _, err := util.GetSCMClient("", configAgent.Config)
+	if err != nil {
+		logrus.WithError(err).Fatal("failed to create scm client")
+	}
+	client, err := branchprotector.NewSCMClient(scmClient)
+	if err != nil {
+		logrus.WithError(err).Fatal("failed to create branch protection client")
+	}
+
+	changes, err := branchprotector.NewProtector(client, cfg, o.dryRun).Protect()
+	if o.dryRun {
+		data, jsonErr := json.MarshalIndent(changes, "", "  ")
+		if jsonErr != nil {
+			logrus.WithError(jsonErr).Fatal("failed to marshal the changes")
+		}
+		fmt.Println(string(data))
+	}
+	if err != nil {
+		logrus.WithError(err).Fatal("failed to protect branches")
+	}
+	if o.dryRun {
+		logrus.Infof("would make %d branch protection changes", len(changes))
+		return
+	}
+	logrus.Infof("made %d branch protection changes", len(changes))
+}
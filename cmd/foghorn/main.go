@@ -8,6 +8,7 @@ import (
 	"github.com/jenkins-x/lighthouse/pkg/clients"
 	"github.com/jenkins-x/lighthouse/pkg/foghorn"
 	"github.com/jenkins-x/lighthouse/pkg/logrusutil"
+	"github.com/jenkins-x/lighthouse/pkg/util"
 	"github.com/sirupsen/logrus"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -84,7 +85,13 @@ func main() {
 
 	defer reconciler.ConfigMapWatcher.Stop()
 
-	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
+	ctx := ctrl.SetupSignalHandler()
+	// lets stop retrying the deliveries of the activity records to the external plugins on shutdown
+	go func() {
+		<-ctx.Done()
+		util.DefaultExternalPluginDispatcher().Shutdown()
+	}()
+	if err := mgr.Start(ctx); err != nil {
 		logrus.WithError(err).Fatal("Problem running manager")
 	}
 }
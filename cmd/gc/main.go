@@ -11,14 +11,21 @@ import (
 	clientset "github.com/jenkins-x/lighthouse/pkg/client/clientset/versioned"
 	lhclient "github.com/jenkins-x/lighthouse/pkg/client/clientset/versioned/typed/lighthouse/v1alpha1"
 	"github.com/jenkins-x/lighthouse/pkg/clients"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/gc"
+	"github.com/jenkins-x/lighthouse/pkg/jobutil"
 	"github.com/jenkins-x/lighthouse/pkg/logrusutil"
 	"github.com/sirupsen/logrus"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 type options struct {
-	namespace string
-	maxAge    time.Duration
+	namespace      string
+	maxAge         time.Duration
+	maxAgePerKind  string
+	maxAgePerState string
+	keepLast       int
+	dryRun         bool
 }
 
 func (o *options) Validate() error {
@@ -28,11 +35,40 @@ func (o *options) Validate() error {
 	return nil
 }
 
+// Policy returns the retention policy configured by the options
+func (o *options) Policy() (*gc.Policy, error) {
+	policy := &gc.Policy{
+		MaxAge:         o.maxAge,
+		MaxAgePerKind:  map[job.PipelineKind]time.Duration{},
+		MaxAgePerState: map[v1alpha1.PipelineState]time.Duration{},
+		KeepLast:       o.keepLast,
+	}
+	kinds, err := gc.ParseMaxAges(o.maxAgePerKind)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --max-age-per-kind: %w", err)
+	}
+	for kind, age := range kinds {
+		policy.MaxAgePerKind[job.PipelineKind(kind)] = age
+	}
+	states, err := gc.ParseMaxAges(o.maxAgePerState)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --max-age-per-state: %w", err)
+	}
+	for state, age := range states {
+		policy.MaxAgePerState[v1alpha1.PipelineState(state)] = age
+	}
+	return policy, policy.Validate()
+}
+
 func gatherOptions(fs *flag.FlagSet, args ...string) options {
 	logrusutil.ComponentInit("lighthouse-gc-jobs")
 
 	var o options
 	fs.DurationVar(&o.maxAge, "max-age", 7*24*time.Hour, "Maximum age to keep LighthouseJobs.")
+	fs.StringVar(&o.maxAgePerKind, "max-age-per-kind", "", "Comma separated list of kind=duration overriding --max-age for the given job kinds, e.g. presubmit=72h,periodic=720h.")
+	fs.StringVar(&o.maxAgePerState, "max-age-per-state", "", "Comma separated list of state=duration overriding --max-age and --max-age-per-kind for jobs in the given states, e.g. failure=336h.")
+	fs.IntVar(&o.keepLast, "keep-last", 1, "Number of most recent LighthouseJobs always kept per org/repo/branch/context. The most recent one is always kept.")
+	fs.BoolVar(&o.dryRun, "dry-run", false, "Only report the LighthouseJobs that would be deleted.")
 	fs.StringVar(&o.namespace, "namespace", "", "The namespace to listen in")
 
 	err := fs.Parse(args)
@@ -48,6 +84,10 @@ func main() {
 	if err := o.Validate(); err != nil {
 		logrus.WithError(err).Fatal("Invalid options")
 	}
+	policy, err := o.Policy()
+	if err != nil {
+		logrus.WithError(err).Fatal("Invalid options")
+	}
 
 	cfg, err := clients.GetConfig("", "")
 	if err != nil {
@@ -65,25 +105,36 @@ func main() {
 		logrus.WithError(err).Fatalf("Could not list LighthouseJobs in namespace %s", o.namespace)
 	}
 
-	now := time.Now()
-
-	for _, job := range jobList.Items {
-		j := job
-		completionTime := j.Status.CompletionTime
-		if completionTime != nil && completionTime.Add(o.maxAge).Before(now) {
-			// The job completed at least maxAge ago, so delete it.
-			err = deleteLighthouseJob(lhInterface, &j)
-			if err != nil {
-				logrus.WithError(err).Fatalf("Failed to delete LighthouseJob %s", j.Name)
-			}
-		} else if completionTime == nil && j.Status.StartTime.Add(o.maxAge).Before(now) {
-			// The job never completed, but was created at least maxAge ago, so delete it.
-			err = deleteLighthouseJob(lhInterface, &j)
-			if err != nil {
-				logrus.WithError(err).Fatalf("Failed to delete LighthouseJob %s", j.Name)
-			}
+	toDelete := policy.Select(jobList.Items, time.Now())
+
+	deleted, failed := 0, 0
+	for i := range toDelete {
+		j := &toDelete[i]
+		if o.dryRun {
+			logrus.WithFields(jobutil.LighthouseJobFields(j)).
+				WithField("maxAge", policy.MaxAgeFor(j)).
+				Infof("Would delete LighthouseJob %s", j.Name)
+			continue
+		}
+		if err := deleteLighthouseJob(lhInterface, j); err != nil {
+			logrus.WithError(err).Errorf("Failed to delete LighthouseJob %s", j.Name)
+			failed++
+			continue
 		}
+		deleted++
+	}
+
+	summary := logrus.WithFields(logrus.Fields{
+		"total":    len(jobList.Items),
+		"selected": len(toDelete),
+		"deleted":  deleted,
+		"failed":   failed,
+		"dryRun":   o.dryRun,
+	})
+	if failed > 0 {
+		summary.Fatalf("Failed to delete %d of %d LighthouseJobs", failed, len(toDelete))
 	}
+	summary.Info("Garbage collection of LighthouseJobs complete")
 }
 
 func deleteLighthouseJob(lhInterface lhclient.LighthouseJobInterface, lhJob *v1alpha1.LighthouseJob) error {
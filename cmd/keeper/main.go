@@ -23,9 +23,11 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/jenkins-x/lighthouse/pkg/clients"
 	"github.com/jenkins-x/lighthouse/pkg/config"
 	configutil "github.com/jenkins-x/lighthouse/pkg/config/util"
 	"github.com/jenkins-x/lighthouse/pkg/interrupts"
+	pkgio "github.com/jenkins-x/lighthouse/pkg/io"
 	"github.com/jenkins-x/lighthouse/pkg/jobutil"
 	"github.com/jenkins-x/lighthouse/pkg/keeper"
 	"github.com/jenkins-x/lighthouse/pkg/keeper/githubapp"
@@ -50,18 +52,26 @@ type options struct {
 
 	maxRecordsPerPool int
 	// historyURI where Keeper should store its action history.
-	// Can be a /local/path or gs://path/to/object.
-	// GCS writes will use the bucket's default acl for new objects. Ensure both that
-	// a) the gcs credentials can write to this bucket
+	// Can be a /local/path, s3://bucket/path/to/object or gs://bucket/path/to/object.
+	// Object store writes will use the bucket's default acl for new objects. Ensure both that
+	// a) the credentials can write to this bucket
 	// b) the default acls do not expose any private info
 	historyURI string
 
 	// statusURI where Keeper store status update state.
-	// Can be a /local/path or gs://path/to/object.
-	// GCS writes will use the bucket's default acl for new objects. Ensure both that
-	// a) the gcs credentials can write to this bucket
+	// Can be a /local/path, configmap://name/key, s3://bucket/path/to/object or gs://bucket/path/to/object.
+	// Object store writes will use the bucket's default acl for new objects. Ensure both that
+	// a) the credentials can write to this bucket
 	// b) the default acls do not expose any private info
 	statusURI string
+
+	// gcsCredentialsFile is the service account key used for the gs:// history and status.
+	// Defaults to the Application Default Credentials, e.g. Workload Identity.
+	gcsCredentialsFile string
+
+	// s3CredentialsFile is the JSON file containing the credentials of the S3 compatible object store
+	// used for the s3:// history and status. Defaults to the standard AWS_* environment variables.
+	s3CredentialsFile string
 }
 
 func (o *options) Validate() error {
@@ -79,8 +89,10 @@ func gatherOptions(fs *flag.FlagSet, args ...string) options {
 	fs.BoolVar(&o.runOnce, "run-once", false, "If true, run only once then quit.")
 
 	fs.IntVar(&o.maxRecordsPerPool, "max-records-per-pool", 1000, "The maximum number of history records stored for an individual Keeper pool.")
-	fs.StringVar(&o.historyURI, "history-uri", "", "The /local/path or gs://path/to/object to store keeper action history. GCS writes will use the default object ACL for the bucket")
-	fs.StringVar(&o.statusURI, "status-path", "", "The /local/path or gs://path/to/object to store status controller state. GCS writes will use the default object ACL for the bucket.")
+	fs.StringVar(&o.historyURI, "history-uri", "", "The /local/path, s3://bucket/path/to/object or gs://bucket/path/to/object to store keeper action history. Object store writes will use the default object ACL for the bucket")
+	fs.StringVar(&o.statusURI, "status-path", "", "The /local/path, configmap://name/key, s3://bucket/path/to/object or gs://bucket/path/to/object to store status controller state. The ConfigMap is created in the namespace if needed. Object store writes will use the default object ACL for the bucket.")
+	fs.StringVar(&o.gcsCredentialsFile, "gcs-credentials-file", "", "Path to the GCP service account key used by the gs:// URIs. Defaults to the Application Default Credentials.")
+	fs.StringVar(&o.s3CredentialsFile, "s3-credentials-file", "", "Path to the JSON credentials of the S3 compatible object store used by the s3:// URIs. Defaults to the AWS_* environment variables.")
 	fs.StringVar(&o.namespace, "namespace", "", "The namespace to listen in")
 
 	err := fs.Parse(args)
@@ -136,14 +148,25 @@ func main() {
 		logrus.WithError(err).Fatal("Error creating Keeper controller.")
 	}
 
+	_, kubeClient, _, _, err := clients.GetAPIClients()
+	if err != nil {
+		logrus.WithError(err).Fatal("Error creating kubernetes client.")
+	}
+	opener, err := pkgio.NewOpener(o.gcsCredentialsFile, o.s3CredentialsFile, kubeClient.CoreV1().ConfigMaps(o.namespace))
+	if err != nil {
+		logrus.WithError(err).Fatal("Error creating opener.")
+	}
+
 	cfg := configAgent.Config
-	c, err := githubapp.NewKeeperController(configAgent, botName, gitKind, gitToken, serverURL, o.maxRecordsPerPool, o.historyURI, o.statusURI, o.namespace)
+	c, err := githubapp.NewKeeperController(configAgent, botName, gitKind, gitToken, serverURL, o.maxRecordsPerPool, opener, o.historyURI, o.statusURI, o.namespace)
 	if err != nil {
 		logrus.WithError(err).Fatal("Error creating Keeper controller.")
 	}
 	defer c.Shutdown()
 	http.Handle("/", c)
-	http.Handle("/history", c.GetHistory())
+	http.HandleFunc("/history", func(w http.ResponseWriter, r *http.Request) {
+		c.GetHistory().ServeHTTP(w, r)
+	})
 	server := &http.Server{Addr: ":" + strconv.Itoa(o.port)}
 
 	start := time.Now()
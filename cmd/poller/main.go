@@ -17,8 +17,10 @@ import (
 	"github.com/jenkins-x/lighthouse/pkg/filebrowser"
 	gitv2 "github.com/jenkins-x/lighthouse/pkg/git/v2"
 	"github.com/jenkins-x/lighthouse/pkg/poller"
+	"github.com/jenkins-x/lighthouse/pkg/poller/pollstate"
 	"github.com/pkg/errors"
 
+	"github.com/jenkins-x/lighthouse/pkg/clients"
 	"github.com/jenkins-x/lighthouse/pkg/config"
 	configutil "github.com/jenkins-x/lighthouse/pkg/config/util"
 	"github.com/jenkins-x/lighthouse/pkg/interrupts"
@@ -50,9 +52,21 @@ type options struct {
 	pollPeriod             time.Duration
 	pollReleasePeriod      time.Duration
 	pollPullRequestPeriod  time.Duration
+	pollState              string
+	pollStateConfigMap     string
+	pollStateFile          string
 }
 
 func (o *options) Validate() error {
+	switch o.pollState {
+	case "memory", "configmap":
+	case "file":
+		if o.pollStateFile == "" {
+			return errors.New("no --poll-state-file given")
+		}
+	default:
+		return errors.Errorf("invalid --poll-state %q, expected one of memory, configmap or file", o.pollState)
+	}
 	if o.hmacToken == "" {
 		o.hmacToken = util.HMACToken()
 	}
@@ -77,6 +91,9 @@ func gatherOptions(fs *flag.FlagSet, args ...string) options {
 	fs.StringVar(&o.namespace, "namespace", "jx", "The namespace to listen in")
 	fs.StringVar(&o.repoNames, "repo", "", "The git repository names to poll. If not specified all the repositories are polled")
 	fs.StringVar(&o.hookEndpoint, "hook", os.Getenv("POLL_HOOK_ENDPOINT"), "The hook endpoint to post to")
+	fs.StringVar(&o.pollState, "poll-state", "memory", "Where to store the last seen SHA of each repository: memory, configmap (survives restarts and is shared by replicas) or file")
+	fs.StringVar(&o.pollStateConfigMap, "poll-state-configmap", pollstate.DefaultConfigMapName, "The name of the ConfigMap storing the poll state when using --poll-state=configmap")
+	fs.StringVar(&o.pollStateFile, "poll-state-file", "", "The local file storing the poll state when using --poll-state=file")
 
 	defaultPollPeriod := 20 * time.Second
 	defaultPollPeriod = parseEnvPollPeriod("POLL_PERIOD", defaultPollPeriod)
@@ -225,6 +242,12 @@ func main() {
 		logrus.WithError(err).Fatal("Error creating Poller controller.")
 	}
 
+	ps, err := o.createPollState()
+	if err != nil {
+		logrus.WithError(err).Fatal("failed to create poll state")
+	}
+	c.SetPollState(ps)
+
 	c.DisablePollPullRequest = o.disablePollPullRequest
 	c.DisablePollRelease = o.disablePollRelease
 
@@ -234,6 +257,7 @@ func main() {
 		"HookEndpint":            o.hookEndpoint,
 		"DisablePollRelease":     o.disablePollRelease,
 		"DisablePollPullRequest": o.disablePollPullRequest,
+		"PollState":              o.pollState,
 	}).Info("starting")
 
 	http.Handle("/", c)
@@ -264,6 +288,21 @@ func main() {
 	interrupts.WaitForGracefulShutdown()
 }
 
+func (o *options) createPollState() (pollstate.Interface, error) {
+	switch o.pollState {
+	case "configmap":
+		_, kubeClient, _, _, err := clients.GetAPIClients()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create kubernetes client")
+		}
+		return pollstate.NewConfigMapPollState(kubeClient, o.namespace, o.pollStateConfigMap), nil
+	case "file":
+		return pollstate.NewFilePollState(o.pollStateFile)
+	default:
+		return pollstate.NewMemoryPollState(), nil
+	}
+}
+
 func findAllRepoNames(c *config.Config) []string {
 	m := map[string]bool{}
 
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/jenkins-x/lighthouse/pkg/config"
+	configutil "github.com/jenkins-x/lighthouse/pkg/config/util"
+	"github.com/jenkins-x/lighthouse/pkg/logrusutil"
+	"github.com/jenkins-x/lighthouse/pkg/stale"
+	"github.com/jenkins-x/lighthouse/pkg/util"
+	"github.com/sirupsen/logrus"
+)
+
+type options struct {
+	configPath    string
+	jobConfigPath string
+	dryRun        bool
+}
+
+func gatherOptions(fs *flag.FlagSet, args ...string) options {
+	logrusutil.ComponentInit("lighthouse-stale")
+
+	var o options
+	fs.StringVar(&o.configPath, "config-path", "", "Path to config.yaml.")
+	fs.StringVar(&o.jobConfigPath, "job-config-path", "", "Path to the job configs.")
+	fs.BoolVar(&o.dryRun, "dry-run", false, "Only report the lifecycle changes which would be made, as JSON on the standard output.")
+
+	err := fs.Parse(args)
+	if err != nil {
+		logrus.WithError(err).Fatal("Invalid options")
+	}
+	o.configPath = configutil.PathOrDefault(o.configPath)
+	return o
+}
+
+func main() {
+	o := gatherOptions(flag.NewFlagSet(os.Args[0], flag.ExitOnError), os.Args[1:]...)
+
+	cfg, err := config.Load(o.configPath, o.jobConfigPath)
+	if err != nil {
+		logrus.WithError(err).Fatalf("failed to load config from %s", o.configPath)
+	}
+	configAgent := &config.Agent{}
+	configAgent.Set(cfg)
+
+	scmClient, _, _, _, err := util.GetSCMClient("", configAgent.Config)
+	if err != nil {
+		logrus.WithError(err).Fatal("failed to create scm client")
+	}
+
+	changes, err := stale.NewManager(scmClient, &cfg.Lifecycle, o.dryRun).Run()
+	if o.dryRun {
+		data, jsonErr := json.MarshalIndent(changes, "", "  ")
+		if jsonErr != nil {
+			logrus.WithError(jsonErr).Fatal("failed to marshal the changes")
+		}
+		fmt.Println(string(data))
+	}
+	if err != nil {
+		logrus.WithError(err).Fatal("failed to apply the lifecycle")
+	}
+	if o.dryRun {
+		logrus.Infof("would make %d lifecycle changes", len(changes))
+		return
+	}
+	logrus.Infof("made %d lifecycle changes", len(changes))
+}
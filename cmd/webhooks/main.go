@@ -30,6 +30,7 @@ type options struct {
 	pluginFilename string
 	configFilename string
 	botName        string
+	dryRun         bool
 }
 
 func (o *options) Validate() error {
@@ -50,6 +51,7 @@ func gatherOptions(fs *flag.FlagSet, args ...string) options {
 	fs.StringVar(&o.configFilename, "config-file", "", "Path to the config.yaml file. If not specified it is loaded from the 'config' ConfigMap")
 	fs.StringVar(&o.botName, "bot-name", "", "The name of the bot user to run as. Defaults to $GIT_USER if not specified.")
 	fs.StringVar(&o.namespace, "namespace", "", "The namespace to listen in")
+	fs.BoolVar(&o.dryRun, "dry-run", false, "Process events without triggering jobs or changing the git provider, responding with the changes which would have been made. A single request can also be processed in dry-run mode with the "+webhook.DryRunHeader+" header.")
 
 	err := fs.Parse(args)
 	if err != nil {
@@ -76,6 +78,10 @@ func main() {
 	if err != nil {
 		logrus.WithError(err).Fatal("failed to set up controller")
 	}
+	if o.dryRun {
+		logrus.Info("processing all events in dry-run mode")
+		controller.DryRun = true
+	}
 	defer func() {
 		controller.CleanupGitClientDir()
 		controller.ConfigMapWatcher.Stop()
@@ -88,6 +94,20 @@ func main() {
 	mux.Handle("/", http.HandlerFunc(controller.DefaultHandler))
 	mux.Handle(o.path, http.HandlerFunc(controller.HandleWebhookRequests))
 	mux.Handle(o.pollPath, http.HandlerFunc(controller.HandlePollingRequests))
+	mux.Handle(webhook.DeliveriesPath, http.HandlerFunc(controller.HandleDeliveries))
+	mux.Handle(webhook.DeliveriesPath+"/", http.HandlerFunc(controller.HandleDeliveries))
+	mux.Handle(webhook.RunPeriodicPath, http.HandlerFunc(controller.HandleRunPeriodic))
+	mux.Handle(webhook.DeadLettersPath, http.HandlerFunc(controller.HandleDeadLetters))
+	mux.Handle(webhook.DeadLettersPath+"/", http.HandlerFunc(controller.HandleDeadLetters))
+
+	// lets trigger the jobs requested over Pub/Sub
+	interrupts.Run(controller.PullPubSubMessages)
+
+	// lets launch the periodic jobs when they are due
+	interrupts.Run(controller.RunPeriodicScheduler)
+
+	// lets stop retrying the deliveries to the external plugins on shutdown
+	interrupts.Run(controller.StopExternalPluginDeliveries)
 
 	// lets serve metrics
 	metricsHandler := http.HandlerFunc(controller.Metrics)
@@ -78,6 +78,10 @@ const (
 	PullNumberEnv = "PULL_NUMBER"
 	// PullPullShaEnv is the pull request's sha
 	PullPullShaEnv = "PULL_PULL_SHA"
+	// TagNameEnv is the name of the tag which triggered the job
+	TagNameEnv = "TAG_NAME"
+	// ReleaseIDEnv is the ID of the published release which triggered the job
+	ReleaseIDEnv = "RELEASE_ID"
 )
 
 // +genclient
@@ -163,6 +167,10 @@ type LighthouseJobSpec struct {
 	PodSpec *corev1.PodSpec `json:"pod_spec,omitempty"`
 	// JenkinsSpec holds configuration specific to Jenkins jobs
 	JenkinsSpec *JenkinsSpec `json:"jenkins_spec,omitempty"`
+	// Tag is the name of the tag whose creation or release triggered the job, if any
+	Tag string `json:"tag,omitempty"`
+	// ReleaseID is the ID of the published release which triggered the job, if any
+	ReleaseID string `json:"release_id,omitempty"`
 }
 
 // Complete returns true if the prow job has finished
@@ -208,6 +216,12 @@ func (s *LighthouseJobSpec) GetEnvVars() map[string]string {
 		env[PullBaseShaEnv] = s.Refs.BaseSHA
 		env[PullRefsEnv] = s.Refs.String()
 	}
+	if s.Tag != "" {
+		env[TagNameEnv] = s.Tag
+	}
+	if s.ReleaseID != "" {
+		env[ReleaseIDEnv] = s.ReleaseID
+	}
 
 	if s.Type != job.PresubmitJob {
 		return env
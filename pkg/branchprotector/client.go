@@ -0,0 +1,211 @@
+package branchprotector
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/pkg/errors"
+)
+
+// Client reads and changes the protection of branches on the SCM provider
+type Client interface {
+	// ListRepositories returns the names of the repositories of the organisation which are not archived
+	ListRepositories(org string) ([]string, error)
+	// ListBranches returns the names of the branches of the repository
+	ListBranches(org, repo string) ([]string, error)
+	// GetBranchProtection returns the protection of the branch or nil if it is not protected
+	GetBranchProtection(org, repo, branch string) (*Protection, error)
+	// UpdateBranchProtection protects the branch
+	UpdateBranchProtection(org, repo, branch string, protection *Protection) error
+	// RemoveBranchProtection removes the protection of the branch
+	RemoveBranchProtection(org, repo, branch string) error
+}
+
+// scmClient implements Client with go-scm, using the GitHub REST API for the branch protection
+// as go-scm does not support it
+type scmClient struct {
+	client *scm.Client
+}
+
+// NewSCMClient creates a Client for the SCM provider. Only GitHub supports branch protection.
+func NewSCMClient(client *scm.Client) (Client, error) {
+	if client.Driver != scm.DriverGithub {
+		return nil, errors.Errorf("branch protection is not supported for %s", client.Driver.String())
+	}
+	return &scmClient{client: client}, nil
+}
+
+func (c *scmClient) ListRepositories(org string) ([]string, error) {
+	ctx := context.Background()
+	var names []string
+	opts := &scm.ListOptions{Page: 1, Size: 100}
+	for {
+		repos, resp, err := c.client.Repositories.ListOrganisation(ctx, org, opts)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to list the repositories of %s", org)
+		}
+		for _, r := range repos {
+			if !r.Archived {
+				names = append(names, r.Name)
+			}
+		}
+		if resp == nil || opts.Page >= resp.Page.Last {
+			return names, nil
+		}
+		opts.Page++
+	}
+}
+
+func (c *scmClient) ListBranches(org, repo string) ([]string, error) {
+	ctx := context.Background()
+	var names []string
+	opts := &scm.ListOptions{Page: 1, Size: 100}
+	for {
+		branches, resp, err := c.client.Git.ListBranches(ctx, scm.Join(org, repo), opts)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to list the branches of %s/%s", org, repo)
+		}
+		for _, b := range branches {
+			names = append(names, b.Name)
+		}
+		if resp == nil || opts.Page >= resp.Page.Last {
+			return names, nil
+		}
+		opts.Page++
+	}
+}
+
+type githubUser struct {
+	Login string `json:"login"`
+}
+
+type githubTeam struct {
+	Slug string `json:"slug"`
+}
+
+type githubRestrictions struct {
+	Users []githubUser `json:"users"`
+	Teams []githubTeam `json:"teams"`
+}
+
+func (r *githubRestrictions) toRestrictions() *Restrictions {
+	if r == nil {
+		return nil
+	}
+	answer := &Restrictions{}
+	for _, u := range r.Users {
+		answer.Users = append(answer.Users, u.Login)
+	}
+	for _, t := range r.Teams {
+		answer.Teams = append(answer.Teams, t.Slug)
+	}
+	return answer
+}
+
+// githubProtection is the branch protection returned by the GitHub API
+type githubProtection struct {
+	RequiredStatusChecks *StatusChecks `json:"required_status_checks"`
+	EnforceAdmins        struct {
+		Enabled bool `json:"enabled"`
+	} `json:"enforce_admins"`
+	RequiredPullRequestReviews *struct {
+		DismissalRestrictions        *githubRestrictions `json:"dismissal_restrictions"`
+		DismissStaleReviews          bool                `json:"dismiss_stale_reviews"`
+		RequireCodeOwnerReviews      bool                `json:"require_code_owner_reviews"`
+		RequiredApprovingReviewCount int                 `json:"required_approving_review_count"`
+	} `json:"required_pull_request_reviews"`
+	Restrictions *githubRestrictions `json:"restrictions"`
+}
+
+// githubProtectionRequest is the branch protection sent to the GitHub API, all fields are required
+type githubProtectionRequest struct {
+	RequiredStatusChecks       *StatusChecks       `json:"required_status_checks"`
+	EnforceAdmins              bool                `json:"enforce_admins"`
+	RequiredPullRequestReviews *PullRequestReviews `json:"required_pull_request_reviews"`
+	Restrictions               *Restrictions       `json:"restrictions"`
+}
+
+func (c *scmClient) GetBranchProtection(org, repo, branch string) (*Protection, error) {
+	gp := githubProtection{}
+	status, err := c.do(http.MethodGet, protectionPath(org, repo, branch), nil, &gp)
+	if status == http.StatusNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	p := &Protection{
+		RequiredStatusChecks: gp.RequiredStatusChecks,
+		EnforceAdmins:        gp.EnforceAdmins.Enabled,
+		Restrictions:         gp.Restrictions.toRestrictions(),
+	}
+	if rpr := gp.RequiredPullRequestReviews; rpr != nil {
+		p.RequiredPullRequestReviews = &PullRequestReviews{
+			DismissalRestrictions:        rpr.DismissalRestrictions.toRestrictions(),
+			DismissStaleReviews:          rpr.DismissStaleReviews,
+			RequireCodeOwnerReviews:      rpr.RequireCodeOwnerReviews,
+			RequiredApprovingReviewCount: rpr.RequiredApprovingReviewCount,
+		}
+	}
+	return p, nil
+}
+
+func (c *scmClient) UpdateBranchProtection(org, repo, branch string, protection *Protection) error {
+	request := githubProtectionRequest{
+		RequiredStatusChecks:       protection.RequiredStatusChecks,
+		EnforceAdmins:              protection.EnforceAdmins,
+		RequiredPullRequestReviews: protection.RequiredPullRequestReviews,
+		Restrictions:               protection.Restrictions,
+	}
+	_, err := c.do(http.MethodPut, protectionPath(org, repo, branch), request, nil)
+	return err
+}
+
+func (c *scmClient) RemoveBranchProtection(org, repo, branch string) error {
+	_, err := c.do(http.MethodDelete, protectionPath(org, repo, branch), nil, nil)
+	return err
+}
+
+func protectionPath(org, repo, branch string) string {
+	return fmt.Sprintf("repos/%s/%s/branches/%s/protection", org, repo, url.PathEscape(branch))
+}
+
+func (c *scmClient) do(method, path string, in, out interface{}) (int, error) {
+	req := &scm.Request{
+		Method: method,
+		Path:   path,
+		Header: http.Header{"Accept": []string{"application/vnd.github+json"}},
+	}
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return 0, err
+		}
+		req.Body = bytes.NewReader(data)
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.client.Do(context.Background(), req)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to %s %s", method, path)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp.Status, errors.Wrapf(err, "failed to read the response of %s %s", method, path)
+	}
+	if resp.Status < 200 || resp.Status > 299 {
+		return resp.Status, errors.Errorf("failed to %s %s: status %d: %s", method, path, resp.Status, string(body))
+	}
+	if out != nil {
+		if err := json.Unmarshal(body, out); err != nil {
+			return resp.Status, errors.Wrapf(err, "failed to decode the response of %s %s", method, path)
+		}
+	}
+	return resp.Status, nil
+}
@@ -0,0 +1,120 @@
+package branchprotector
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/jenkins-x/lighthouse/pkg/config/branchprotection"
+)
+
+// Protection is the protection of a branch on the SCM provider
+type Protection struct {
+	RequiredStatusChecks       *StatusChecks       `json:"required_status_checks,omitempty"`
+	EnforceAdmins              bool                `json:"enforce_admins"`
+	RequiredPullRequestReviews *PullRequestReviews `json:"required_pull_request_reviews,omitempty"`
+	Restrictions               *Restrictions       `json:"restrictions,omitempty"`
+}
+
+// StatusChecks are the contexts which must be green to merge
+type StatusChecks struct {
+	Strict   bool     `json:"strict"`
+	Contexts []string `json:"contexts"`
+}
+
+// PullRequestReviews are the reviews required to merge
+type PullRequestReviews struct {
+	DismissalRestrictions        *Restrictions `json:"dismissal_restrictions,omitempty"`
+	DismissStaleReviews          bool          `json:"dismiss_stale_reviews"`
+	RequireCodeOwnerReviews      bool          `json:"require_code_owner_reviews"`
+	RequiredApprovingReviewCount int           `json:"required_approving_review_count"`
+}
+
+// Restrictions are the users and teams allowed to merge or dismiss reviews
+type Restrictions struct {
+	Users []string `json:"users"`
+	Teams []string `json:"teams"`
+}
+
+// ProtectionForPolicy returns the protection to apply for a branch protection policy
+func ProtectionForPolicy(policy branchprotection.Policy) *Protection {
+	p := &Protection{
+		EnforceAdmins: boolValue(policy.Admins),
+		Restrictions:  restrictionsForPolicy(policy.Restrictions),
+	}
+	if rsc := policy.RequiredStatusChecks; rsc != nil {
+		p.RequiredStatusChecks = &StatusChecks{
+			Strict:   boolValue(rsc.Strict),
+			Contexts: sortedCopy(rsc.Contexts),
+		}
+	}
+	if rpr := policy.RequiredPullRequestReviews; rpr != nil {
+		p.RequiredPullRequestReviews = &PullRequestReviews{
+			DismissalRestrictions:   restrictionsForPolicy(rpr.DismissalRestrictions),
+			DismissStaleReviews:     boolValue(rpr.DismissStale),
+			RequireCodeOwnerReviews: boolValue(rpr.RequireOwners),
+		}
+		if rpr.Approvals != nil {
+			p.RequiredPullRequestReviews.RequiredApprovingReviewCount = *rpr.Approvals
+		}
+	}
+	return p
+}
+
+// Equal returns true if both protections have the same effect
+func (p *Protection) Equal(other *Protection) bool {
+	return reflect.DeepEqual(p.normalized(), other.normalized())
+}
+
+// normalized returns a copy of the protection with sorted lists and no empty restrictions
+func (p *Protection) normalized() *Protection {
+	if p == nil {
+		return nil
+	}
+	n := &Protection{
+		EnforceAdmins: p.EnforceAdmins,
+		Restrictions:  p.Restrictions.normalized(),
+	}
+	if p.RequiredStatusChecks != nil {
+		n.RequiredStatusChecks = &StatusChecks{
+			Strict:   p.RequiredStatusChecks.Strict,
+			Contexts: sortedCopy(p.RequiredStatusChecks.Contexts),
+		}
+	}
+	if p.RequiredPullRequestReviews != nil {
+		rpr := *p.RequiredPullRequestReviews
+		rpr.DismissalRestrictions = rpr.DismissalRestrictions.normalized()
+		n.RequiredPullRequestReviews = &rpr
+	}
+	return n
+}
+
+func (r *Restrictions) normalized() *Restrictions {
+	if r == nil {
+		return nil
+	}
+	return &Restrictions{
+		Users: sortedCopy(r.Users),
+		Teams: sortedCopy(r.Teams),
+	}
+}
+
+func restrictionsForPolicy(r *branchprotection.Restrictions) *Restrictions {
+	if r == nil {
+		return nil
+	}
+	return &Restrictions{
+		Users: sortedCopy(r.Users),
+		Teams: sortedCopy(r.Teams),
+	}
+}
+
+func sortedCopy(values []string) []string {
+	answer := make([]string, len(values))
+	copy(answer, values)
+	sort.Strings(answer)
+	return answer
+}
+
+func boolValue(b *bool) bool {
+	return b != nil && *b
+}
@@ -0,0 +1,225 @@
+package branchprotector
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/jenkins-x/lighthouse/pkg/config"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// Action is the change made to the protection of a branch
+type Action string
+
+const (
+	// UpdateAction protects the branch or changes its protection
+	UpdateAction Action = "update"
+	// RemoveAction removes the protection of the branch
+	RemoveAction Action = "remove"
+)
+
+// Change is a change of the protection of a branch
+type Change struct {
+	Org    string      `json:"org"`
+	Repo   string      `json:"repo"`
+	Branch string      `json:"branch"`
+	Action Action      `json:"action"`
+	Before *Protection `json:"before,omitempty"`
+	After  *Protection `json:"after,omitempty"`
+}
+
+// Protector applies the `branch-protection` configuration to the SCM provider
+type Protector struct {
+	Client Client
+	Config *config.Config
+	// DryRun only reports the changes without applying them
+	DryRun bool
+	Logger *logrus.Entry
+}
+
+// NewProtector creates a new protector
+func NewProtector(client Client, cfg *config.Config, dryRun bool) *Protector {
+	return &Protector{
+		Client: client,
+		Config: cfg,
+		DryRun: dryRun,
+		Logger: logrus.WithField("component", "branchprotector"),
+	}
+}
+
+// Protect reconciles the protection of the branches of all the configured repositories, returning the
+// changes which were made or, in dry-run mode, would have been made. The errors of each branch are
+// collected so that one failing repository does not prevent the others from being protected.
+func (p *Protector) Protect() ([]Change, error) {
+	var changes []Change
+	var errs []string
+	done := sets.NewString()
+
+	bp := p.Config.BranchProtection
+	orgs := make([]string, 0, len(bp.Orgs))
+	for org := range bp.Orgs {
+		orgs = append(orgs, org)
+	}
+	sort.Strings(orgs)
+	for _, org := range orgs {
+		repos, err := p.reposForOrg(org)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		for _, repo := range repos {
+			done.Insert(org + "/" + repo)
+			c, err := p.protectRepo(org, repo)
+			changes = append(changes, c...)
+			if err != nil {
+				errs = append(errs, err.Error())
+			}
+		}
+	}
+
+	if bp.ProtectTested {
+		// also protect the repositories with jobs, even if they are not configured
+		tested := sets.NewString()
+		for fullName := range p.Config.Presubmits {
+			tested.Insert(fullName)
+		}
+		for fullName := range p.Config.Postsubmits {
+			tested.Insert(fullName)
+		}
+		for _, fullName := range tested.List() {
+			if done.Has(fullName) {
+				continue
+			}
+			parts := strings.SplitN(fullName, "/", 2)
+			if len(parts) != 2 {
+				errs = append(errs, fmt.Sprintf("invalid repository %q", fullName))
+				continue
+			}
+			c, err := p.protectRepo(parts[0], parts[1])
+			changes = append(changes, c...)
+			if err != nil {
+				errs = append(errs, err.Error())
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return changes, errors.Errorf("failed to protect branches: %s", strings.Join(errs, ", "))
+	}
+	return changes, nil
+}
+
+// reposForOrg returns all the repositories of an org with a protection policy, or only the configured ones otherwise
+func (p *Protector) reposForOrg(org string) ([]string, error) {
+	o := p.Config.BranchProtection.GetOrg(org)
+	if o.Protect != nil {
+		return p.Client.ListRepositories(org)
+	}
+	var repos []string
+	for repo := range o.Repos {
+		repos = append(repos, repo)
+	}
+	sort.Strings(repos)
+	return repos, nil
+}
+
+func (p *Protector) protectRepo(org, repo string) ([]Change, error) {
+	r := p.Config.BranchProtection.GetOrg(org).GetRepo(repo)
+	var excludes []*regexp.Regexp
+	for _, e := range r.Exclude {
+		re, err := regexp.Compile(e)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid exclude %q for %s/%s", e, org, repo)
+		}
+		excludes = append(excludes, re)
+	}
+
+	branches, err := p.Client.ListBranches(org, repo)
+	if err != nil {
+		return nil, err
+	}
+	var changes []Change
+	var errs []string
+	for _, branch := range branches {
+		if _, configured := r.Branches[branch]; !configured && matchesAny(excludes, branch) {
+			p.Logger.Debugf("%s/%s=%s: excluded", org, repo, branch)
+			continue
+		}
+		change, err := p.protectBranch(org, repo, branch)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		if change != nil {
+			changes = append(changes, *change)
+		}
+	}
+	if len(errs) > 0 {
+		return changes, errors.New(strings.Join(errs, ", "))
+	}
+	return changes, nil
+}
+
+// protectBranch compares the protection of the branch with its policy and applies the difference
+func (p *Protector) protectBranch(org, repo, branch string) (*Change, error) {
+	b, err := p.Config.BranchProtection.GetOrg(org).GetRepo(repo).GetBranch(branch)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid policy for %s/%s=%s", org, repo, branch)
+	}
+	policy, err := p.Config.GetPolicy(org, repo, branch, *b)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid policy for %s/%s=%s", org, repo, branch)
+	}
+	if policy == nil || policy.Protect == nil {
+		// nothing is configured for this branch so it is left alone
+		return nil, nil
+	}
+
+	current, err := p.Client.GetBranchProtection(org, repo, branch)
+	if err != nil {
+		return nil, err
+	}
+	change := &Change{Org: org, Repo: repo, Branch: branch, Before: current}
+	if !*policy.Protect {
+		if current == nil {
+			return nil, nil
+		}
+		change.Action = RemoveAction
+	} else {
+		desired := ProtectionForPolicy(*policy)
+		if current != nil && current.Equal(desired) {
+			return nil, nil
+		}
+		change.Action = UpdateAction
+		change.After = desired
+	}
+
+	l := p.Logger.WithFields(logrus.Fields{"org": org, "repo": repo, "branch": branch, "action": change.Action})
+	if p.DryRun {
+		l.Info("Would change the branch protection.")
+		return change, nil
+	}
+	l.Info("Changing the branch protection.")
+	if change.Action == RemoveAction {
+		err = p.Client.RemoveBranchProtection(org, repo, branch)
+	} else {
+		err = p.Client.UpdateBranchProtection(org, repo, branch, change.After)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to %s the protection of %s/%s=%s", change.Action, org, repo, branch)
+	}
+	return change, nil
+}
+
+func matchesAny(res []*regexp.Regexp, s string) bool {
+	for _, re := range res {
+		if re.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,246 @@
+package branchprotector
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm/driver/github"
+	"github.com/jenkins-x/go-scm/scm/driver/gitlab"
+	"github.com/jenkins-x/lighthouse/pkg/config"
+	"github.com/jenkins-x/lighthouse/pkg/config/branchprotection"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/config/lighthouse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClient struct {
+	repos       map[string][]string
+	branches    map[string][]string
+	protections map[string]*Protection
+	updated     []string
+	removed     []string
+}
+
+func (f *fakeClient) ListRepositories(org string) ([]string, error) {
+	return f.repos[org], nil
+}
+
+func (f *fakeClient) ListBranches(org, repo string) ([]string, error) {
+	return f.branches[org+"/"+repo], nil
+}
+
+func (f *fakeClient) GetBranchProtection(org, repo, branch string) (*Protection, error) {
+	return f.protections[org+"/"+repo+"="+branch], nil
+}
+
+func (f *fakeClient) UpdateBranchProtection(org, repo, branch string, protection *Protection) error {
+	key := org + "/" + repo + "=" + branch
+	f.updated = append(f.updated, key)
+	f.protections[key] = protection
+	return nil
+}
+
+func (f *fakeClient) RemoveBranchProtection(org, repo, branch string) error {
+	key := org + "/" + repo + "=" + branch
+	f.removed = append(f.removed, key)
+	delete(f.protections, key)
+	return nil
+}
+
+func newFakeClient() *fakeClient {
+	return &fakeClient{
+		repos: map[string][]string{
+			"org": {"repo", "other"},
+		},
+		branches: map[string][]string{
+			"org/repo":     {"main", "release-1.0", "feature"},
+			"org/other":    {"main"},
+			"legacy/tools": {"main", "old"},
+		},
+		protections: map[string]*Protection{
+			"org/other=main": {
+				RequiredStatusChecks: &StatusChecks{Contexts: []string{"lint"}},
+			},
+			"legacy/tools=old": {
+				EnforceAdmins: true,
+			},
+		},
+	}
+}
+
+func newTestConfig() *config.Config {
+	yes := true
+	no := false
+	two := 2
+	return &config.Config{
+		JobConfig: config.JobConfig{
+			Presubmits: map[string][]job.Presubmit{
+				"org/repo": {
+					{
+						Base:      job.Base{Name: "unit"},
+						Reporter:  job.Reporter{Context: "unit"},
+						AlwaysRun: true,
+					},
+				},
+			},
+		},
+		ProwConfig: lighthouse.Config{
+			BranchProtection: branchprotection.Config{
+				Policy: branchprotection.Policy{
+					Exclude: []string{"^feature"},
+				},
+				Orgs: map[string]branchprotection.Org{
+					"org": {
+						Policy: branchprotection.Policy{
+							Protect: &yes,
+							Admins:  &yes,
+							RequiredPullRequestReviews: &branchprotection.ReviewPolicy{
+								Approvals: &two,
+							},
+						},
+					},
+					"legacy": {
+						Repos: map[string]branchprotection.Repo{
+							"tools": {
+								Branches: map[string]branchprotection.Branch{
+									"old": {Policy: branchprotection.Policy{Protect: &no}},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestProtect(t *testing.T) {
+	client := newFakeClient()
+	changes, err := NewProtector(client, newTestConfig(), false).Protect()
+	require.NoError(t, err)
+
+	expected := &Protection{
+		EnforceAdmins: true,
+		RequiredPullRequestReviews: &PullRequestReviews{
+			RequiredApprovingReviewCount: 2,
+		},
+	}
+	expectedWithContexts := &Protection{
+		RequiredStatusChecks: &StatusChecks{Contexts: []string{"unit"}},
+		EnforceAdmins:        true,
+		RequiredPullRequestReviews: &PullRequestReviews{
+			RequiredApprovingReviewCount: 2,
+		},
+	}
+
+	sort.Strings(client.updated)
+	assert.Equal(t, []string{"org/other=main", "org/repo=main", "org/repo=release-1.0"}, client.updated)
+	assert.Equal(t, []string{"legacy/tools=old"}, client.removed)
+	assert.True(t, expectedWithContexts.Equal(client.protections["org/repo=main"]))
+	assert.True(t, expectedWithContexts.Equal(client.protections["org/repo=release-1.0"]))
+	assert.True(t, expected.Equal(client.protections["org/other=main"]))
+	assert.NotContains(t, client.protections, "org/repo=feature")
+	assert.Len(t, changes, 4)
+
+	// once applied there is nothing left to change
+	client.updated = nil
+	client.removed = nil
+	changes, err = NewProtector(client, newTestConfig(), false).Protect()
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+	assert.Empty(t, client.updated)
+	assert.Empty(t, client.removed)
+}
+
+func TestProtectDryRun(t *testing.T) {
+	client := newFakeClient()
+	changes, err := NewProtector(client, newTestConfig(), true).Protect()
+	require.NoError(t, err)
+
+	assert.Empty(t, client.updated)
+	assert.Empty(t, client.removed)
+	require.Len(t, changes, 4)
+	byBranch := map[string]Change{}
+	for _, c := range changes {
+		byBranch[c.Org+"/"+c.Repo+"="+c.Branch] = c
+	}
+	assert.Equal(t, RemoveAction, byBranch["legacy/tools=old"].Action)
+	other := byBranch["org/other=main"]
+	assert.Equal(t, UpdateAction, other.Action)
+	assert.Equal(t, []string{"lint"}, other.Before.RequiredStatusChecks.Contexts)
+	assert.Nil(t, other.After.RequiredStatusChecks)
+}
+
+func TestSCMClientBranchProtection(t *testing.T) {
+	var put map[string]interface{}
+	deleted := false
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/org/repo/branches/main/protection", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			_, _ = io.WriteString(w, `{
+  "required_status_checks": {"strict": true, "contexts": ["unit"]},
+  "enforce_admins": {"enabled": true},
+  "required_pull_request_reviews": {"dismiss_stale_reviews": true, "required_approving_review_count": 1},
+  "restrictions": {"users": [{"login": "alice"}], "teams": [{"slug": "admins"}]}
+}`)
+		case http.MethodPut:
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&put))
+			_, _ = io.WriteString(w, `{}`)
+		case http.MethodDelete:
+			deleted = true
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+	mux.HandleFunc("/repos/org/repo/branches/dev/protection", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = io.WriteString(w, `{"message": "Branch not protected"}`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	scmClient, err := github.New(server.URL)
+	require.NoError(t, err)
+	client, err := NewSCMClient(scmClient)
+	require.NoError(t, err)
+
+	protection, err := client.GetBranchProtection("org", "repo", "main")
+	require.NoError(t, err)
+	assert.Equal(t, &Protection{
+		RequiredStatusChecks: &StatusChecks{Strict: true, Contexts: []string{"unit"}},
+		EnforceAdmins:        true,
+		RequiredPullRequestReviews: &PullRequestReviews{
+			DismissStaleReviews:          true,
+			RequiredApprovingReviewCount: 1,
+		},
+		Restrictions: &Restrictions{Users: []string{"alice"}, Teams: []string{"admins"}},
+	}, protection)
+
+	protection, err = client.GetBranchProtection("org", "repo", "dev")
+	require.NoError(t, err)
+	assert.Nil(t, protection)
+
+	require.NoError(t, client.UpdateBranchProtection("org", "repo", "main", &Protection{EnforceAdmins: true}))
+	assert.Equal(t, map[string]interface{}{
+		"required_status_checks":        nil,
+		"enforce_admins":                true,
+		"required_pull_request_reviews": nil,
+		"restrictions":                  nil,
+	}, put)
+
+	require.NoError(t, client.RemoveBranchProtection("org", "repo", "main"))
+	assert.True(t, deleted)
+}
+
+func TestNewSCMClientRequiresGitHub(t *testing.T) {
+	scmClient, err := gitlab.New("https://gitlab.com")
+	require.NoError(t, err)
+	_, err = NewSCMClient(scmClient)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not supported for gitlab")
+}
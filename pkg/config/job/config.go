@@ -20,7 +20,6 @@ import (
 	"fmt"
 
 	"github.com/jenkins-x/lighthouse/pkg/config/lighthouse"
-	"gopkg.in/robfig/cron.v2"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
 )
@@ -173,7 +172,7 @@ func (c *Config) Validate(lh lighthouse.Config) error {
 	// for child jobs.
 	for _, p := range c.Periodics {
 		if p.Cron != "" {
-			if _, err := cron.Parse(p.Cron); err != nil {
+			if _, err := p.Schedule(); err != nil {
 				return fmt.Errorf("invalid cron string %s in periodic %s: %v", p.Cron, p.Name, err)
 			}
 		} else {
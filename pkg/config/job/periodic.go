@@ -16,17 +16,55 @@ limitations under the License.
 
 package job
 
+import (
+	"strings"
+
+	"gopkg.in/robfig/cron.v2"
+)
+
 // Periodic runs on a timer.
 type Periodic struct {
 	Base
 	Reporter
-	// Cron representation of job trigger time
+	// Cron representation of job trigger time. It can be prefixed with CRON_TZ=<timezone> or
+	// TZ=<timezone> to use a timezone other than UTC, e.g. CRON_TZ=Europe/Paris 0 4 * * *
 	Cron string `json:"cron"`
 	// Branch to run job on. If not set default branch for repository is used
 	Branch string `json:"branch,omitempty"`
+	// the org/repo whose in-repo configuration defines the periodic
+	sourceRepo string
 }
 
 // SetDefaults initializes default values
 func (p *Periodic) SetDefaults(namespace string) {
 	p.Base.SetDefaults(namespace)
 }
+
+// SourceRepo returns the org/repo whose in-repo configuration defines the periodic, or an empty string
+// if it comes from the global configuration
+func (p *Periodic) SourceRepo() string {
+	return p.sourceRepo
+}
+
+// SetSourceRepo sets the org/repo whose in-repo configuration defines the periodic
+func (p *Periodic) SetSourceRepo(fullName string) {
+	p.sourceRepo = fullName
+}
+
+// Schedule parses the cron representation of the job trigger time, defaulting to the UTC timezone
+func (p *Periodic) Schedule() (cron.Schedule, error) {
+	return ParseSchedule(p.Cron)
+}
+
+// ParseSchedule parses a cron representation of a trigger time which can be prefixed with
+// CRON_TZ=<timezone> or TZ=<timezone>, defaulting to the UTC timezone
+func ParseSchedule(spec string) (cron.Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	switch {
+	case strings.HasPrefix(spec, "CRON_TZ="):
+		spec = strings.TrimPrefix(spec, "CRON_")
+	case !strings.HasPrefix(spec, "TZ="):
+		spec = "TZ=UTC " + spec
+	}
+	return cron.Parse(spec)
+}
@@ -16,7 +16,11 @@ limitations under the License.
 
 package job
 
-import "fmt"
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
 
 // Postsubmit runs on push events.
 type Postsubmit struct {
@@ -26,6 +30,14 @@ type Postsubmit struct {
 	// TODO(krzyzacy): Move existing `Report` into `Skip_Report` once this is deployed
 	Reporter
 	JenkinsSpec *JenkinsSpec `json:"jenkins_spec,omitempty"`
+	// Tags are regular expressions matching the names of the tags whose creation triggers this job.
+	// A job with tags or release set is only triggered by these events and not by pushes to branches.
+	Tags []string `json:"tags,omitempty"`
+	// Release triggers this job when a release is published.
+	Release bool `json:"release,omitempty"`
+
+	// We'll set this when we load it.
+	reTags *regexp.Regexp
 }
 
 // JenkinsSpec holds optional Jenkins job config
@@ -55,9 +67,38 @@ func (p *Postsubmit) SetRegexes() error {
 		return fmt.Errorf("could not set change regexes for %s: %v", p.Name, err)
 	}
 	p.RegexpChangeMatcher = c
+	if len(p.Tags) > 0 {
+		re, err := regexp.Compile(strings.Join(p.Tags, `|`))
+		if err != nil {
+			return fmt.Errorf("could not compile tag regex for %s: %v", p.Name, err)
+		}
+		p.reTags = re
+	}
 	return nil
 }
 
+// TriggeredByTagOrRelease returns true if the postsubmit is triggered by tag creations or published
+// releases instead of pushes to branches
+func (p Postsubmit) TriggeredByTagOrRelease() bool {
+	return len(p.Tags) > 0 || p.Release
+}
+
+// ShouldRunForTag determines if the postsubmit should run when the given tag is created
+func (p Postsubmit) ShouldRunForTag(tag string) bool {
+	if len(p.Tags) == 0 {
+		return false
+	}
+	re := p.reTags
+	if re == nil {
+		var err error
+		re, err = regexp.Compile(strings.Join(p.Tags, `|`))
+		if err != nil {
+			return false
+		}
+	}
+	return re.MatchString(tag)
+}
+
 // CouldRun determines if the postsubmit could run against a specific
 // base ref
 func (p Postsubmit) CouldRun(baseRef string) bool {
@@ -67,7 +108,7 @@ func (p Postsubmit) CouldRun(baseRef string) bool {
 // ShouldRun determines if the postsubmit should run in response to a
 // set of changes. This is evaluated lazily, if necessary.
 func (p Postsubmit) ShouldRun(baseRef string, changes ChangedFilesProvider) (bool, error) {
-	if !p.CouldRun(baseRef) {
+	if p.TriggeredByTagOrRelease() || !p.CouldRun(baseRef) {
 		return false, nil
 	}
 	if determined, shouldRun, err := p.RegexpChangeMatcher.ShouldRun(changes); err != nil {
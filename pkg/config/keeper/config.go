@@ -78,8 +78,8 @@ type Config struct {
 	// define their own options in case branch protection is not used.
 	ContextOptions ContextPolicyOptions `json:"context_options,omitempty"`
 	// BatchSizeLimitMap is a key/value pair of an org or org/repo as the key and
-	// integer batch size limit as the value. The empty string key can be used as
-	// a global default.
+	// integer batch size limit as the value. The "*" key can be used as
+	// a global default. Batch merging is disabled for repos without a limit.
 	// Special values:
 	//  0 => unlimited batch size
 	// -1 => batch merging disabled :(
@@ -103,17 +103,18 @@ func (c *Config) MergeMethod(org, repo string) PullRequestMergeType {
 	return v
 }
 
-// BatchSizeLimit return the batch size limit for the given repo
+// BatchSizeLimit return the batch size limit for the given repo, -1 if batch merging is disabled
 func (c *Config) BatchSizeLimit(org, repo string) int {
-	// TODO: Remove once #564 is fixed and batch builds can work again. (APB)
+	if limit, ok := c.BatchSizeLimitMap[fmt.Sprintf("%s/%s", org, repo)]; ok {
+		return limit
+	}
+	if limit, ok := c.BatchSizeLimitMap[org]; ok {
+		return limit
+	}
+	if limit, ok := c.BatchSizeLimitMap["*"]; ok {
+		return limit
+	}
 	return -1
-	//if limit, ok := t.BatchSizeLimitMap[fmt.Sprintf("%s/%s", org, repo)]; ok {
-	//	return limit
-	//}
-	//if limit, ok := t.BatchSizeLimitMap[org]; ok {
-	//	return limit
-	//}
-	//return t.BatchSizeLimitMap["*"]
 }
 
 // MergeCommitTemplate returns a struct with Go template string(s) or nil
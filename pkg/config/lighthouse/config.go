@@ -68,6 +68,9 @@ type Config struct {
 	GitHubOptions GitHubOptions `json:"github,omitempty"`
 	// ProviderConfig contains optional SCM provider information
 	ProviderConfig *ProviderConfig `json:"providerConfig,omitempty"`
+	// Lifecycle configures the automation of the stale, rotten and closed
+	// lifecycle of inactive issues and pull requests
+	Lifecycle Lifecycle `json:"lifecycle,omitempty"`
 }
 
 // Parse initializes and validates the Config
@@ -93,6 +96,9 @@ func (c *Config) Parse() error {
 	if err := c.Keeper.Parse(); err != nil {
 		return err
 	}
+	if err := c.Lifecycle.Parse(); err != nil {
+		return err
+	}
 	if c.LighthouseJobNamespace == "" {
 		c.LighthouseJobNamespace = "default"
 	}
@@ -0,0 +1,190 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lighthouse
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+)
+
+const (
+	defaultStaleAfter  = 90 * 24 * time.Hour
+	defaultRottenAfter = 30 * 24 * time.Hour
+	defaultCloseAfter  = 30 * 24 * time.Hour
+
+	// DefaultStaleComment is the comment left when an issue or pull request is marked as stale
+	DefaultStaleComment = `Inactive {{.Kind}}s go stale after {{.StaleAfter}} of inactivity.
+Mark this {{.Kind}} as fresh with ` + "`/remove-lifecycle stale`" + `.
+Stale {{.Kind}}s rot after an additional {{.RottenAfter}} of inactivity and eventually close.
+
+If this {{.Kind}} is safe to close now please do so with ` + "`/close`" + `.
+To prevent this {{.Kind}} from ever going stale, mark it with ` + "`/lifecycle frozen`" + `.`
+
+	// DefaultRottenComment is the comment left when a stale issue or pull request is marked as rotten
+	DefaultRottenComment = `Stale {{.Kind}}s rot after {{.RottenAfter}} of inactivity.
+Mark this {{.Kind}} as fresh with ` + "`/remove-lifecycle rotten`" + `.
+Rotten {{.Kind}}s close after an additional {{.CloseAfter}} of inactivity.
+
+If this {{.Kind}} is safe to close now please do so with ` + "`/close`" + `.`
+
+	// DefaultCloseComment is the comment left when a rotten issue or pull request is closed
+	DefaultCloseComment = `Rotten {{.Kind}}s close after {{.CloseAfter}} of inactivity.
+Reopen this {{.Kind}} with ` + "`/reopen`" + `.
+Mark this {{.Kind}} as fresh with ` + "`/remove-lifecycle rotten`" + `.`
+)
+
+// Lifecycle configures the periodic automation which marks inactive issues and
+// pull requests as stale, then as rotten and eventually closes them.
+type Lifecycle struct {
+	// Queries select the issues and pull requests managed by the lifecycle automation.
+	Queries []LifecycleQuery `json:"queries,omitempty"`
+}
+
+// LifecycleQuery selects a set of issues and pull requests and configures how long
+// they may stay inactive in each lifecycle stage. Anything labelled lifecycle/frozen
+// is always skipped.
+type LifecycleQuery struct {
+	Orgs          []string `json:"orgs,omitempty"`
+	Repos         []string `json:"repos,omitempty"`
+	ExcludedRepos []string `json:"excludedRepos,omitempty"`
+	// Query contains additional search qualifiers, e.g. "is:issue" or "-label:kind/bug".
+	// See: https://help.github.com/articles/searching-issues-and-pull-requests/
+	Query string `json:"query,omitempty"`
+
+	// StaleAfterString compiles into StaleAfter at load time.
+	StaleAfterString string `json:"stale_after,omitempty"`
+	// StaleAfter is how long an issue or pull request may be inactive before
+	// being marked as stale. Defaults to 90 days.
+	StaleAfter time.Duration `json:"-"`
+	// RottenAfterString compiles into RottenAfter at load time.
+	RottenAfterString string `json:"rotten_after,omitempty"`
+	// RottenAfter is how long a stale issue or pull request may be inactive
+	// before being marked as rotten. Defaults to 30 days.
+	RottenAfter time.Duration `json:"-"`
+	// CloseAfterString compiles into CloseAfter at load time.
+	CloseAfterString string `json:"close_after,omitempty"`
+	// CloseAfter is how long a rotten issue or pull request may be inactive
+	// before being closed. Defaults to 30 days.
+	CloseAfter time.Duration `json:"-"`
+
+	// StaleCommentTemplate, RottenCommentTemplate and CloseCommentTemplate are Go
+	// templates of the comments left when an issue or pull request changes stage.
+	// The templates can use the .Org, .Repo, .Number, .Title, .Author, .Kind,
+	// .StaleAfter, .RottenAfter and .CloseAfter fields.
+	StaleCommentTemplate  string `json:"stale_comment,omitempty"`
+	RottenCommentTemplate string `json:"rotten_comment,omitempty"`
+	CloseCommentTemplate  string `json:"close_comment,omitempty"`
+
+	StaleComment  *template.Template `json:"-"`
+	RottenComment *template.Template `json:"-"`
+	CloseComment  *template.Template `json:"-"`
+}
+
+// Parse initializes and validates the Config
+func (c *Lifecycle) Parse() error {
+	for i := range c.Queries {
+		if err := c.Queries[i].Parse(); err != nil {
+			return fmt.Errorf("lifecycle query (index %d) is invalid: %v", i, err)
+		}
+	}
+	return nil
+}
+
+// Parse initializes and validates the query
+func (q *LifecycleQuery) Parse() error {
+	if len(q.Orgs) == 0 && len(q.Repos) == 0 {
+		return errors.New("at least one org or repo is required")
+	}
+	for _, o := range q.Orgs {
+		if o == "" || strings.Contains(o, "/") {
+			return fmt.Errorf("orgs[] entries must not be empty or contain slashes: %q", o)
+		}
+	}
+	for _, r := range append(append([]string{}, q.Repos...), q.ExcludedRepos...) {
+		if parts := strings.Split(r, "/"); len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return fmt.Errorf("repos[] and excludedRepos[] entries must be of the form org/repo: %q", r)
+		}
+	}
+
+	var err error
+	if q.StaleAfter, err = parseLifecycleDuration("stale_after", q.StaleAfterString, defaultStaleAfter); err != nil {
+		return err
+	}
+	if q.RottenAfter, err = parseLifecycleDuration("rotten_after", q.RottenAfterString, defaultRottenAfter); err != nil {
+		return err
+	}
+	if q.CloseAfter, err = parseLifecycleDuration("close_after", q.CloseAfterString, defaultCloseAfter); err != nil {
+		return err
+	}
+
+	if q.StaleComment, err = parseLifecycleTemplate("stale_comment", q.StaleCommentTemplate, DefaultStaleComment); err != nil {
+		return err
+	}
+	if q.RottenComment, err = parseLifecycleTemplate("rotten_comment", q.RottenCommentTemplate, DefaultRottenComment); err != nil {
+		return err
+	}
+	if q.CloseComment, err = parseLifecycleTemplate("close_comment", q.CloseCommentTemplate, DefaultCloseComment); err != nil {
+		return err
+	}
+	return nil
+}
+
+// SearchQuery returns the search string matching the open issues and pull requests selected by the query.
+func (q *LifecycleQuery) SearchQuery() string {
+	toks := []string{"state:open"}
+	for _, o := range q.Orgs {
+		toks = append(toks, fmt.Sprintf("org:\"%s\"", o))
+	}
+	for _, r := range q.Repos {
+		toks = append(toks, fmt.Sprintf("repo:\"%s\"", r))
+	}
+	for _, r := range q.ExcludedRepos {
+		toks = append(toks, fmt.Sprintf("-repo:\"%s\"", r))
+	}
+	if q.Query != "" {
+		toks = append(toks, q.Query)
+	}
+	return strings.Join(toks, " ")
+}
+
+func parseLifecycleDuration(field, value string, defaultValue time.Duration) (time.Duration, error) {
+	if value == "" {
+		return defaultValue, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("cannot parse duration for lifecycle %s: %v", field, err)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("lifecycle %s must be positive", field)
+	}
+	return d, nil
+}
+
+func parseLifecycleTemplate(field, value, defaultValue string) (*template.Template, error) {
+	if value == "" {
+		value = defaultValue
+	}
+	t, err := template.New(field).Parse(value)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse template for lifecycle %s: %v", field, err)
+	}
+	return t, nil
+}
@@ -16,5 +16,5 @@ limitations under the License.
 
 package lighthouse
 
-// PubsubSubscriptions maps GCP projects to a list of Topics.
+// PubsubSubscriptions maps GCP projects to a list of subscriptions whose messages trigger LighthouseJobs.
 type PubsubSubscriptions map[string][]string
@@ -149,6 +149,8 @@ func convertTektonStatus(cond *apis.Condition, start, finished *metav1.Time) v1a
 	switch {
 	case cond.Status == corev1.ConditionTrue:
 		return v1alpha1.SuccessState
+	case cond.Status == corev1.ConditionFalse && cond.Reason == pipelinev1.PipelineRunReasonCancelled.String():
+		return v1alpha1.AbortedState
 	case cond.Status == corev1.ConditionFalse:
 		return v1alpha1.FailureState
 	case start.IsZero():
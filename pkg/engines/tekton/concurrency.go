@@ -0,0 +1,103 @@
+package tekton
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	lighthousev1alpha1 "github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	configjob "github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/pkg/errors"
+	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// concurrencyRequeueInterval is how long a job held back by max_concurrency waits
+// before the reconciler checks again whether it can be admitted
+const concurrencyRequeueInterval = 15 * time.Second
+
+// concurrencyStatus describes where a triggered job stands with respect to the
+// max_concurrency limit of its job
+type concurrencyStatus struct {
+	// running is the number of admitted instances of the job whose PipelineRun has not finished yet
+	running int
+	// position is the zero based position of the job amongst the triggered instances of the job, by creation order
+	position int
+}
+
+// canStart returns true if the job can be admitted without exceeding the given limit
+func (s concurrencyStatus) canStart(max int) bool {
+	return s.running+s.position < max
+}
+
+// description returns the status description for a job that is being held back
+func (s concurrencyStatus) description(job *lighthousev1alpha1.LighthouseJob) string {
+	return fmt.Sprintf("Waiting for a free slot: %d of %d instances of %s running, position %d in queue.", s.running, job.Spec.MaxConcurrency, job.Spec.Job, s.position+1)
+}
+
+// getConcurrencyStatus computes how many instances of the same job are running and the queue position
+// of the given triggered job amongst the other triggered instances
+func (r *LighthouseJobReconciler) getConcurrencyStatus(ctx context.Context, job *lighthousev1alpha1.LighthouseJob) (concurrencyStatus, error) {
+	status := concurrencyStatus{}
+
+	var jobList lighthousev1alpha1.LighthouseJobList
+	if err := r.client.List(ctx, &jobList, client.InNamespace(job.Namespace)); err != nil {
+		return status, errors.Wrapf(err, "failed to list LighthouseJobs")
+	}
+
+	var queued []lighthousev1alpha1.LighthouseJob
+	for i := range jobList.Items {
+		other := &jobList.Items[i]
+		if other.Spec.Job != job.Spec.Job || other.Spec.Agent != configjob.TektonPipelineAgent {
+			continue
+		}
+		switch {
+		case other.Status.State == lighthousev1alpha1.TriggeredState:
+			queued = append(queued, *other)
+		case other.Complete() || lighthousev1alpha1.IsTerminalPipelineState(other.Status.State):
+			continue
+		default:
+			running, err := r.isRunning(ctx, other)
+			if err != nil {
+				return status, err
+			}
+			if running {
+				status.running++
+			}
+		}
+	}
+
+	sort.SliceStable(queued, func(i, j int) bool {
+		ti, tj := queued[i].CreationTimestamp, queued[j].CreationTimestamp
+		if !ti.Equal(&tj) {
+			return ti.Before(&tj)
+		}
+		return queued[i].Name < queued[j].Name
+	})
+	for i := range queued {
+		if queued[i].Name == job.Name {
+			status.position = i
+			break
+		}
+	}
+	return status, nil
+}
+
+// isRunning returns true if the admitted job still has an unfinished PipelineRun, or has been admitted
+// but its PipelineRun is not visible yet
+func (r *LighthouseJobReconciler) isRunning(ctx context.Context, job *lighthousev1alpha1.LighthouseJob) (bool, error) {
+	var pipelineRunList pipelinev1.PipelineRunList
+	if err := r.client.List(ctx, &pipelineRunList, client.InNamespace(job.Namespace), client.MatchingFields{jobOwnerKey: job.Name}); err != nil {
+		return false, errors.Wrapf(err, "failed to list pipeline runs of LighthouseJob %s", job.Name)
+	}
+	if len(pipelineRunList.Items) == 0 {
+		return job.Status.State == lighthousev1alpha1.PendingState, nil
+	}
+	for i := range pipelineRunList.Items {
+		if !pipelineRunList.Items[i].IsDone() {
+			return true, nil
+		}
+	}
+	return false, nil
+}
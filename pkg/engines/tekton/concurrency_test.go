@@ -0,0 +1,142 @@
+package tekton
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	lighthousev1alpha1 "github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	configjob "github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	tektonfake "github.com/tektoncd/pipeline/pkg/client/clientset/versioned/fake"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestReconcileMaxConcurrency(t *testing.T) {
+	ns := "jx"
+	now := time.Now()
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, lighthousev1alpha1.AddToScheme(scheme))
+	require.NoError(t, pipelinev1.AddToScheme(scheme))
+
+	newJob := func(name string, state lighthousev1alpha1.PipelineState, created time.Time) *lighthousev1alpha1.LighthouseJob {
+		return &lighthousev1alpha1.LighthouseJob{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         ns,
+				CreationTimestamp: metav1.NewTime(created),
+			},
+			Spec: lighthousev1alpha1.LighthouseJobSpec{
+				Type:           configjob.PostsubmitJob,
+				Agent:          configjob.TektonPipelineAgent,
+				Job:            "e2e",
+				Context:        "e2e",
+				MaxConcurrency: 1,
+				Refs: &lighthousev1alpha1.Refs{
+					Org:     "myorg",
+					Repo:    "myrepo",
+					BaseRef: "main",
+					BaseSHA: "abc",
+				},
+				PipelineRunSpec: &pipelinev1.PipelineRunSpec{
+					PipelineSpec: &pipelinev1.PipelineSpec{},
+				},
+			},
+			Status: lighthousev1alpha1.LighthouseJobStatus{
+				State: state,
+			},
+		}
+	}
+
+	running := newJob("running", lighthousev1alpha1.PendingState, now.Add(-3*time.Minute))
+	first := newJob("first", lighthousev1alpha1.TriggeredState, now.Add(-2*time.Minute))
+	second := newJob("second", lighthousev1alpha1.TriggeredState, now.Add(-1*time.Minute))
+	other := newJob("other", lighthousev1alpha1.TriggeredState, now)
+	other.Spec.Job = "other"
+
+	runningPR := &pipelinev1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "running-pr",
+			Namespace: ns,
+		},
+	}
+	require.NoError(t, ctrl.SetControllerReference(running, runningPR, scheme))
+
+	c := fake.NewClientBuilder().WithScheme(scheme).
+		WithStatusSubresource(&lighthousev1alpha1.LighthouseJob{}).
+		WithObjects(running, first, second, other, runningPR).
+		Build()
+	fake.AddIndex(c, &pipelinev1.PipelineRun{}, jobOwnerKey, tektonControllerIndexFunc)
+
+	reconciler := NewLighthouseJobReconciler(c, c, scheme, tektonfake.NewSimpleClientset(), dashboardBaseURL, dashboardTemplate, ns, false, 1)
+	reconciler.idGenerator = &seededRandIDGenerator{}
+	reconciler.disableLogging = true
+
+	reconcile := func(name string) ctrl.Result {
+		result, err := reconciler.Reconcile(context.TODO(), ctrl.Request{
+			NamespacedName: types.NamespacedName{Namespace: ns, Name: name},
+		})
+		require.NoError(t, err)
+		return result
+	}
+	getJob := func(name string) *lighthousev1alpha1.LighthouseJob {
+		job := &lighthousev1alpha1.LighthouseJob{}
+		require.NoError(t, c.Get(context.TODO(), types.NamespacedName{Namespace: ns, Name: name}, job))
+		return job
+	}
+	countPipelineRuns := func(name string) int {
+		var pipelineRunList pipelinev1.PipelineRunList
+		require.NoError(t, c.List(context.TODO(), &pipelineRunList, client.InNamespace(ns), client.MatchingFields{jobOwnerKey: name}))
+		return len(pipelineRunList.Items)
+	}
+
+	// both triggered jobs are held while the running one has not finished
+	result := reconcile("second")
+	assert.Equal(t, concurrencyRequeueInterval, result.RequeueAfter)
+	assert.Equal(t, lighthousev1alpha1.TriggeredState, getJob("second").Status.State)
+	assert.Equal(t, "Waiting for a free slot: 1 of 1 instances of e2e running, position 2 in queue.", getJob("second").Status.Description)
+	assert.Equal(t, 0, countPipelineRuns("second"))
+
+	reconcile("first")
+	assert.Equal(t, lighthousev1alpha1.TriggeredState, getJob("first").Status.State)
+	assert.Equal(t, "Waiting for a free slot: 1 of 1 instances of e2e running, position 1 in queue.", getJob("first").Status.Description)
+
+	// jobs with a different name are not affected
+	reconcile("other")
+	assert.Equal(t, lighthousev1alpha1.PendingState, getJob("other").Status.State)
+	assert.Equal(t, 1, countPipelineRuns("other"))
+
+	// once the running pipeline completes the oldest triggered job is admitted
+	runningPR.Status.Conditions = duckv1.Conditions{{
+		Type:   apis.ConditionSucceeded,
+		Status: corev1.ConditionTrue,
+	}}
+	require.NoError(t, c.Update(context.TODO(), runningPR))
+
+	result = reconcile("second")
+	assert.Equal(t, concurrencyRequeueInterval, result.RequeueAfter)
+	assert.Equal(t, "Waiting for a free slot: 0 of 1 instances of e2e running, position 2 in queue.", getJob("second").Status.Description)
+
+	result = reconcile("first")
+	assert.Zero(t, result.RequeueAfter)
+	firstJob := getJob("first")
+	assert.Equal(t, lighthousev1alpha1.PendingState, firstJob.Status.State)
+	assert.Empty(t, firstJob.Status.Description)
+	assert.Equal(t, 1, countPipelineRuns("first"))
+
+	// the admitted job now occupies the only slot
+	reconcile("second")
+	assert.Equal(t, lighthousev1alpha1.TriggeredState, getJob("second").Status.State)
+	assert.Equal(t, "Waiting for a free slot: 1 of 1 instances of e2e running, position 1 in queue.", getJob("second").Status.Description)
+}
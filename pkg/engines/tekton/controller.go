@@ -132,6 +132,33 @@ func (r *LighthouseJobReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 	// if pipeline run does not exist, create it
 	if len(pipelineRunList.Items) == 0 {
 		if job.Status.State == lighthousev1alpha1.TriggeredState {
+			// hold the job in the triggered state while too many instances of it are running
+			if job.Spec.MaxConcurrency > 0 {
+				concurrency, err := r.getConcurrencyStatus(ctx, &job)
+				if err != nil {
+					r.logger.Errorf("Failed to check concurrency of job %s: %s", job.Spec.Job, err)
+					return ctrl.Result{}, err
+				}
+				if !concurrency.canStart(job.Spec.MaxConcurrency) {
+					description := concurrency.description(&job)
+					r.logger.Infof("Not starting LighthouseJob %s: %s", job.Name, description)
+					if job.Status.Description != description {
+						f := func(job *lighthousev1alpha1.LighthouseJob) error {
+							job.Status.Description = description
+							if err := r.client.Status().Update(ctx, job); err != nil {
+								r.logger.Errorf("Failed to update LighthouseJob status: %s", err)
+								return err
+							}
+							return nil
+						}
+						if err := r.retryModifyJob(ctx, req.NamespacedName, &job, f); err != nil {
+							return ctrl.Result{}, err
+						}
+					}
+					return ctrl.Result{RequeueAfter: concurrencyRequeueInterval}, nil
+				}
+			}
+
 			// construct a pipeline run
 			pipelineRun, err := makePipelineRun(ctx, job, r.namespace, r.logger, r.idGenerator, r.apiReader)
 			if err != nil {
@@ -176,6 +203,19 @@ func (r *LighthouseJobReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 				r.logger.Errorf("Failed to create pipeline run: %s", err)
 				return ctrl.Result{}, err
 			}
+		} else if job.Status.State == lighthousev1alpha1.AbortedState && !job.Complete() {
+			// the job was aborted before its pipeline run got created, there is nothing to cancel
+			f := func(job *lighthousev1alpha1.LighthouseJob) error {
+				job.SetComplete()
+				if err := r.client.Status().Update(ctx, job); err != nil {
+					r.logger.Errorf("Failed to update LighthouseJob status: %s", err)
+					return err
+				}
+				return nil
+			}
+			if err := r.retryModifyJob(ctx, req.NamespacedName, &job, f); err != nil {
+				return ctrl.Result{}, err
+			}
 		}
 	} else if len(pipelineRunList.Items) == 1 {
 		// if pipeline run exists, create pipelineactivity and update lighthousejob status
@@ -198,6 +238,17 @@ func (r *LighthouseJobReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 			}
 		}
 
+		// cancel the pipeline run of an aborted job
+		if job.Status.State == lighthousev1alpha1.AbortedState && !pipelineRun.IsDone() && !pipelineRun.IsCancelled() {
+			r.logger.Infof("Cancelling PipelineRun %s of aborted LighthouseJob %s", pipelineRun.Name, job.Name)
+			patch := client.MergeFrom(pipelineRun.DeepCopy())
+			pipelineRun.Spec.Status = pipelinev1.PipelineRunSpecStatusCancelled
+			if err := r.client.Patch(ctx, &pipelineRun, patch); err != nil {
+				r.logger.Errorf("Failed to cancel pipeline run: %s", err)
+				return ctrl.Result{}, err
+			}
+		}
+
 		// When terminal shortcut is enabled, skip ConvertPipelineRun + status update if the PipelineRun is
 		// done and Activity already matches (covers Owns(PipelineRun) events; same flag as For() predicate).
 		if r.skipTerminatedReconciles {
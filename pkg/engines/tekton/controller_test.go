@@ -311,3 +311,50 @@ func TestReconcileEmptyStateNoPipelineRun(t *testing.T) {
 	require.NoError(t, err)
 	assert.Len(t, pipelineRunList.Items, 0, "No PipelineRun should be created when LighthouseJob state is empty")
 }
+
+func TestReconcileAbortedJobCancelsPipelineRun(t *testing.T) {
+	ns := "jx"
+	jobName := "myorg-myrepo-pr-1-abc12"
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, lighthousev1alpha1.AddToScheme(scheme))
+	require.NoError(t, pipelinev1.AddToScheme(scheme))
+
+	job := &lighthousev1alpha1.LighthouseJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      jobName,
+			Namespace: ns,
+			Labels:    map[string]string{},
+		},
+		Spec: lighthousev1alpha1.LighthouseJobSpec{
+			Type:    configjob.PresubmitJob,
+			Agent:   configjob.TektonPipelineAgent,
+			Context: "pr-build",
+		},
+		Status: lighthousev1alpha1.LighthouseJobStatus{
+			State: lighthousev1alpha1.AbortedState,
+		},
+	}
+	pipelineRun := &pipelinev1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      jobName + "-1",
+			Namespace: ns,
+		},
+	}
+	require.NoError(t, ctrl.SetControllerReference(job, pipelineRun, scheme))
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(&lighthousev1alpha1.LighthouseJob{}).WithObjects(job, pipelineRun).Build()
+	fake.AddIndex(c, &pipelinev1.PipelineRun{}, jobOwnerKey, tektonControllerIndexFunc)
+
+	reconciler := NewLighthouseJobReconciler(c, c, scheme, tektonfake.NewSimpleClientset(), dashboardBaseURL, dashboardTemplate, ns, false, 1)
+	reconciler.disableLogging = true
+
+	_, err := reconciler.Reconcile(context.TODO(), ctrl.Request{
+		NamespacedName: types.NamespacedName{Namespace: ns, Name: jobName},
+	})
+	require.NoError(t, err)
+
+	updatedPR := &pipelinev1.PipelineRun{}
+	require.NoError(t, c.Get(context.TODO(), types.NamespacedName{Namespace: ns, Name: pipelineRun.Name}, updatedPR))
+	assert.Equal(t, pipelinev1.PipelineRunSpecStatus(pipelinev1.PipelineRunSpecStatusCancelled), updatedPR.Spec.Status)
+}
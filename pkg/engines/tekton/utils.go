@@ -90,7 +90,9 @@ func makePipelineRun(ctx context.Context, lj v1alpha1.LighthouseJob, namespace s
 	}
 	if len(lj.Spec.PipelineRunParams) > 0 {
 		payload := map[string]interface{}{
-			"Refs": lj.Spec.Refs,
+			"Refs":      lj.Spec.Refs,
+			"Tag":       lj.Spec.Tag,
+			"ReleaseID": lj.Spec.ReleaseID,
 		}
 		for _, param := range lj.Spec.PipelineRunParams {
 			parsedTemplate, err := template.New(param.Name).Parse(param.ValueTemplate)
@@ -182,7 +182,9 @@ func (r *LighthouseJobReconciler) retryModifyJob(ctx context.Context, ns client.
 }
 
 func (r *LighthouseJobReconciler) updateJobStatusForActivity(activity *lighthousev1alpha1.ActivityRecord, job *lighthousev1alpha1.LighthouseJob) {
-	if activity.Status != job.Status.State {
+	// an aborted job keeps its state until its pipeline has actually stopped
+	aborting := job.Status.State == lighthousev1alpha1.AbortedState && !lighthousev1alpha1.IsTerminalPipelineState(activity.Status)
+	if activity.Status != job.Status.State && !aborting {
 		job.Status.State = activity.Status
 	}
 	if activity.LastCommitSHA != job.Status.LastCommitSHA {
@@ -262,6 +264,13 @@ func (r *LighthouseJobReconciler) reportStatus(activity *lighthousev1alpha1.Acti
 		go util.CallExternalPluginsWithActivityRecord(r.logger, external, activity, util.HMACToken(), r.wg)
 	}
 
+	// batch jobs test several pull requests at once, their result is only used by keeper and must not
+	// be reported as the status of the first pull request of the batch
+	if j.Spec.Type == job.BatchJob {
+		r.logger.WithFields(fields).Debug("not reporting the git status of a batch job")
+		return
+	}
+
 	pipelineContext := activity.Context
 	if pipelineContext == "" {
 		pipelineContext = "jenkins-x"
@@ -321,8 +330,8 @@ func toScmStatusDescriptionRunningStages(activity *lighthousev1alpha1.ActivityRe
 		info.scmStatus = scm.StateRunning
 		info.description = "Pipeline running"
 	case lighthousev1alpha1.AbortedState:
-		info.scmStatus = scm.StateError
-		info.description = "Error executing pipeline"
+		info.scmStatus = scm.StateCanceled
+		info.description = "Pipeline aborted"
 	case lighthousev1alpha1.FailureState:
 		info.scmStatus = scm.StateFailure
 		info.description = "Pipeline failed"
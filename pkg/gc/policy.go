@@ -0,0 +1,141 @@
+package gc
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+)
+
+// Policy describes which LighthouseJobs should be garbage collected
+type Policy struct {
+	// MaxAge is the age after which jobs are deleted, unless overridden by kind or state
+	MaxAge time.Duration
+	// MaxAgePerKind overrides MaxAge for jobs of a given kind
+	MaxAgePerKind map[job.PipelineKind]time.Duration
+	// MaxAgePerState overrides MaxAge and MaxAgePerKind for jobs in a given state
+	MaxAgePerState map[v1alpha1.PipelineState]time.Duration
+	// KeepLast is the number of most recent jobs always kept per org/repo/branch/context.
+	// The most recent job of each context is always kept as keeper relies on it.
+	KeepLast int
+}
+
+// Validate validates the policy
+func (p *Policy) Validate() error {
+	if p.MaxAge <= 0 {
+		return fmt.Errorf("max age must be positive, got %s", p.MaxAge)
+	}
+	if p.KeepLast < 0 {
+		return fmt.Errorf("keep last must be a non-negative number, got %d", p.KeepLast)
+	}
+	for kind, age := range p.MaxAgePerKind {
+		if age <= 0 {
+			return fmt.Errorf("max age for kind %s must be positive, got %s", kind, age)
+		}
+	}
+	for state, age := range p.MaxAgePerState {
+		if age <= 0 {
+			return fmt.Errorf("max age for state %s must be positive, got %s", state, age)
+		}
+	}
+	return nil
+}
+
+// MaxAgeFor returns the age after which the given job can be deleted
+func (p *Policy) MaxAgeFor(lhjob *v1alpha1.LighthouseJob) time.Duration {
+	if age, ok := p.MaxAgePerState[lhjob.Status.State]; ok {
+		return age
+	}
+	if age, ok := p.MaxAgePerKind[lhjob.Spec.Type]; ok {
+		return age
+	}
+	return p.MaxAge
+}
+
+// Select returns the jobs which should be deleted according to the policy
+func (p *Policy) Select(jobs []v1alpha1.LighthouseJob, now time.Time) []v1alpha1.LighthouseJob {
+	keepLast := p.KeepLast
+	if keepLast < 1 {
+		keepLast = 1
+	}
+
+	groups := map[string][]v1alpha1.LighthouseJob{}
+	var keys []string
+	for _, j := range jobs {
+		key := groupKey(&j)
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], j)
+	}
+	sort.Strings(keys)
+
+	var answer []v1alpha1.LighthouseJob
+	for _, key := range keys {
+		group := groups[key]
+		sort.SliceStable(group, func(i, j int) bool {
+			return startTime(&group[j]).Before(startTime(&group[i]))
+		})
+		for i := range group {
+			if i < keepLast {
+				continue
+			}
+			if p.expired(&group[i], now) {
+				answer = append(answer, group[i])
+			}
+		}
+	}
+	return answer
+}
+
+// expired returns true if the job completed, or started when it never completed, longer than its max age ago
+func (p *Policy) expired(lhjob *v1alpha1.LighthouseJob, now time.Time) bool {
+	reference := startTime(lhjob)
+	if lhjob.Status.CompletionTime != nil {
+		reference = lhjob.Status.CompletionTime.Time
+	}
+	return reference.Add(p.MaxAgeFor(lhjob)).Before(now)
+}
+
+// startTime returns the time the job started or, failing that, was created
+func startTime(lhjob *v1alpha1.LighthouseJob) time.Time {
+	if !lhjob.Status.StartTime.IsZero() {
+		return lhjob.Status.StartTime.Time
+	}
+	return lhjob.CreationTimestamp.Time
+}
+
+// groupKey returns the org/repo/branch/context a job reports to
+func groupKey(lhjob *v1alpha1.LighthouseJob) string {
+	context := lhjob.Spec.Context
+	if context == "" {
+		context = lhjob.Spec.Job
+	}
+	if lhjob.Spec.Refs == nil {
+		return fmt.Sprintf("%s:%s", lhjob.Spec.Type, context)
+	}
+	return fmt.Sprintf("%s/%s/%s:%s", strings.ToLower(lhjob.Spec.Refs.Org), lhjob.Spec.Refs.Repo, lhjob.Spec.GetBranch(), context)
+}
+
+// ParseMaxAges parses a comma separated list of name=duration pairs
+func ParseMaxAges(value string) (map[string]time.Duration, error) {
+	answer := map[string]time.Duration{}
+	if value == "" {
+		return answer, nil
+	}
+	for _, pair := range strings.Split(value, ",") {
+		parts := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid max age %q, expected name=duration", pair)
+		}
+		age, err := time.ParseDuration(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid max age %q: %w", pair, err)
+		}
+		answer[parts[0]] = age
+	}
+	return answer, nil
+}
@@ -0,0 +1,104 @@
+package gc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSelect(t *testing.T) {
+	now := time.Now()
+	day := 24 * time.Hour
+
+	newJob := func(name string, kind job.PipelineKind, context string, state v1alpha1.PipelineState, age time.Duration) v1alpha1.LighthouseJob {
+		started := metav1.NewTime(now.Add(-age - time.Hour))
+		completed := metav1.NewTime(now.Add(-age))
+		j := v1alpha1.LighthouseJob{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: v1alpha1.LighthouseJobSpec{
+				Type:    kind,
+				Job:     context,
+				Context: context,
+				Refs: &v1alpha1.Refs{
+					Org:     "org",
+					Repo:    "repo",
+					BaseRef: "main",
+				},
+			},
+			Status: v1alpha1.LighthouseJobStatus{
+				State:     state,
+				StartTime: started,
+			},
+		}
+		if v1alpha1.IsTerminalPipelineState(state) {
+			j.Status.CompletionTime = &completed
+		}
+		return j
+	}
+
+	jobs := []v1alpha1.LighthouseJob{
+		newJob("release-latest", job.PostsubmitJob, "release", v1alpha1.SuccessState, 30*day),
+		newJob("release-old", job.PostsubmitJob, "release", v1alpha1.SuccessState, 40*day),
+		newJob("build-latest", job.PresubmitJob, "build", v1alpha1.SuccessState, 1*day),
+		newJob("build-recent", job.PresubmitJob, "build", v1alpha1.SuccessState, 2*day),
+		newJob("build-old", job.PresubmitJob, "build", v1alpha1.SuccessState, 4*day),
+		newJob("build-old-failure", job.PresubmitJob, "build", v1alpha1.FailureState, 5*day),
+		newJob("build-ancient-failure", job.PresubmitJob, "build", v1alpha1.FailureState, 20*day),
+		newJob("build-stuck", job.PresubmitJob, "build", v1alpha1.PendingState, 10*day),
+	}
+
+	testCases := []struct {
+		name     string
+		policy   Policy
+		expected []string
+	}{
+		{
+			name:     "single max age keeps the latest job per context",
+			policy:   Policy{MaxAge: 7 * day},
+			expected: []string{"build-ancient-failure", "build-stuck", "release-old"},
+		},
+		{
+			name: "per kind and per state ages",
+			policy: Policy{
+				MaxAge:         7 * day,
+				MaxAgePerKind:  map[job.PipelineKind]time.Duration{job.PresubmitJob: 3 * day},
+				MaxAgePerState: map[v1alpha1.PipelineState]time.Duration{v1alpha1.FailureState: 14 * day},
+			},
+			expected: []string{"build-ancient-failure", "build-old", "build-stuck", "release-old"},
+		},
+		{
+			name: "keep last",
+			policy: Policy{
+				MaxAge:   time.Hour,
+				KeepLast: 3,
+			},
+			expected: []string{"build-old-failure", "build-stuck", "build-ancient-failure"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.NoError(t, tc.policy.Validate())
+			var names []string
+			for _, j := range tc.policy.Select(jobs, now) {
+				names = append(names, j.Name)
+			}
+			assert.ElementsMatch(t, tc.expected, names)
+		})
+	}
+}
+
+func TestParseMaxAges(t *testing.T) {
+	ages, err := ParseMaxAges("presubmit=72h, periodic=720h")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]time.Duration{"presubmit": 72 * time.Hour, "periodic": 720 * time.Hour}, ages)
+
+	_, err = ParseMaxAges("presubmit")
+	assert.Error(t, err)
+	_, err = ParseMaxAges("presubmit=3 days")
+	assert.Error(t, err)
+}
@@ -218,6 +218,9 @@ func (c *clientFactory) bootstrapClients(org, repo, dir string) (cacher, cloner,
 			remotes: remotes{
 				publishRemote: c.remotes.PublishRemote(org, repo),
 				centralRemote: c.remotes.CentralRemote(org, repo),
+				forkRemote: func(forkName string) RemoteResolver {
+					return c.remotes.PublishRemote(org, forkName)
+				},
 			},
 			executor: executor,
 			info:     c.gitUser,
@@ -55,6 +55,8 @@ type Interactor interface {
 	Fetch() error
 	// FetchRef fetches the refspec
 	FetchRef(refspec string) error
+	// FetchUnshallow fetches the refspec with its complete history
+	FetchUnshallow(refspec string) error
 	// FetchFromRemote fetches the branch of the given remote
 	FetchFromRemote(remote RemoteResolver, branch string) error
 	// CheckoutPullRequest fetches and checks out the synthetic refspec from GitHub for a pull request HEAD
@@ -331,6 +333,27 @@ func (i *interactor) FetchRef(refspec string) error {
 	return nil
 }
 
+// FetchUnshallow fetches a refspec from the remote and leaves it as FETCH_HEAD like FetchRef, also fetching
+// the history missing from a shallow clone so that merge bases older than the clone depth can be found.
+func (i *interactor) FetchUnshallow(refspec string) error {
+	out, err := i.executor.Run("rev-parse", "--is-shallow-repository")
+	if err != nil {
+		return fmt.Errorf("error checking if the repository is shallow: %v %v", err, string(out))
+	}
+	if strings.TrimSpace(string(out)) != "true" {
+		return i.FetchRef(refspec)
+	}
+	remote, err := i.remote()
+	if err != nil {
+		return fmt.Errorf("could not resolve remote for fetching: %v", err)
+	}
+	i.logger.Debugf("Fetching %q with its complete history from %s", refspec, remote)
+	if out, err := i.executor.Run("fetch", "--tags", "--force", "--unshallow", remote, refspec); err != nil {
+		return fmt.Errorf("error fetching %q: %v %v", refspec, err, string(out))
+	}
+	return nil
+}
+
 // FetchFromRemote fetches all update from a specific remote and branch and leaves it as FETCH_HEAD.
 func (i *interactor) FetchFromRemote(remote RemoteResolver, branch string) error {
 	r, err := remote()
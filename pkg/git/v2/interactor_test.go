@@ -1236,6 +1236,102 @@ func TestInteractor_FetchRef(t *testing.T) {
 	}
 }
 
+func TestInteractor_FetchUnshallow(t *testing.T) {
+	var testCases = []struct {
+		name          string
+		refspec       string
+		remote        RemoteResolver
+		responses     map[string]execResponse
+		expectedCalls [][]string
+		expectedErr   bool
+	}{
+		{
+			name:    "shallow repository",
+			refspec: "shasum",
+			remote: func() (string, error) {
+				return "someone.com", nil
+			},
+			responses: map[string]execResponse{
+				"rev-parse --is-shallow-repository": {
+					out: []byte("true\n"),
+				},
+				"fetch --tags --force --unshallow someone.com shasum": {
+					out: []byte(`ok`),
+				},
+			},
+			expectedCalls: [][]string{
+				{"rev-parse", "--is-shallow-repository"},
+				{"fetch", "--tags", "--force", "--unshallow", "someone.com", "shasum"},
+			},
+			expectedErr: false,
+		},
+		{
+			name:    "complete repository",
+			refspec: "shasum",
+			remote: func() (string, error) {
+				return "someone.com", nil
+			},
+			responses: map[string]execResponse{
+				"rev-parse --is-shallow-repository": {
+					out: []byte("false\n"),
+				},
+				"fetch --tags --force someone.com shasum": {
+					out: []byte(`ok`),
+				},
+			},
+			expectedCalls: [][]string{
+				{"rev-parse", "--is-shallow-repository"},
+				{"fetch", "--tags", "--force", "someone.com", "shasum"},
+			},
+			expectedErr: false,
+		},
+		{
+			name:    "fetch fails",
+			refspec: "shasum",
+			remote: func() (string, error) {
+				return "someone.com", nil
+			},
+			responses: map[string]execResponse{
+				"rev-parse --is-shallow-repository": {
+					out: []byte("true\n"),
+				},
+				"fetch --tags --force --unshallow someone.com shasum": {
+					err: errors.New("oops"),
+				},
+			},
+			expectedCalls: [][]string{
+				{"rev-parse", "--is-shallow-repository"},
+				{"fetch", "--tags", "--force", "--unshallow", "someone.com", "shasum"},
+			},
+			expectedErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			e := fakeExecutor{
+				records:   [][]string{},
+				responses: testCase.responses,
+			}
+			i := interactor{
+				executor: &e,
+				remote:   testCase.remote,
+				logger:   logrus.WithField("test", testCase.name),
+			}
+			actualErr := i.FetchUnshallow(testCase.refspec)
+			if testCase.expectedErr && actualErr == nil {
+				t.Errorf("%s: expected an error but got none", testCase.name)
+			}
+			if !testCase.expectedErr && actualErr != nil {
+				t.Errorf("%s: expected no error but got one: %v", testCase.name, actualErr)
+			}
+			if actual, expected := e.records, testCase.expectedCalls; !reflect.DeepEqual(actual, expected) {
+				t.Errorf("%s: got incorrect git calls: %v", testCase.name, cmp.Diff(actual, expected))
+			}
+		})
+	}
+}
+
 func TestInteractor_FetchFromRemote(t *testing.T) {
 	var testCases = []struct {
 		name          string
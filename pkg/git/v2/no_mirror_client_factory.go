@@ -85,6 +85,9 @@ func (c *noMirrorClientFactory) bootstrapClients(org, repo, dir string) (cacher,
 			remotes: remotes{
 				publishRemote: c.remotes.PublishRemote(org, repo),
 				centralRemote: c.remotes.CentralRemote(org, repo),
+				forkRemote: func(forkName string) RemoteResolver {
+					return c.remotes.PublishRemote(org, forkName)
+				},
 			},
 			executor: executor,
 			info:     c.gitUser,
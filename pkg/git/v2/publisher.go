@@ -28,6 +28,8 @@ type Publisher interface {
 	Commit(title, body string) error
 	// PushToFork pushes the local state to the fork remote
 	PushToFork(branch string, force bool) error
+	// PushToNamedFork pushes the local state to the fork remote, for forks not named after the repository
+	PushToNamedFork(forkName, branch string, force bool) error
 	// PushToCentral pushes the local state to the central remote
 	PushToCentral(branch string, force bool) error
 }
@@ -38,6 +40,8 @@ type UserGetter func() (name, email string, err error)
 type remotes struct {
 	publishRemote RemoteResolver
 	centralRemote RemoteResolver
+	// forkRemote returns the resolver of the publish remote of the fork of the given name
+	forkRemote func(forkName string) RemoteResolver
 }
 
 type publisher struct {
@@ -68,27 +72,24 @@ func (p *publisher) Commit(title, body string) error {
 
 // PublishPush pushes the local state to the publish remote
 func (p *publisher) PushToFork(branch string, force bool) error {
-	remote, err := p.remotes.publishRemote()
-	if err != nil {
-		return err
-	}
-
-	args := []string{"push"}
-	if force {
-		args = append(args, "--force")
-	}
-	args = append(args, []string{remote, branch}...)
+	return p.push(p.remotes.publishRemote, branch, force)
+}
 
-	p.logger.Infof("Pushing branch %q to %q", branch, remote)
-	if out, err := p.executor.Run(args...); err != nil {
-		return fmt.Errorf("error pushing %q: %v %v", branch, err, string(out))
+// PushToNamedFork pushes the local state to the publish remote of the fork of the given name
+func (p *publisher) PushToNamedFork(forkName, branch string, force bool) error {
+	if p.remotes.forkRemote == nil {
+		return fmt.Errorf("no remote available to push to the %s fork", forkName)
 	}
-	return nil
+	return p.push(p.remotes.forkRemote(forkName), branch, force)
 }
 
 // CentralPush pushes the local state to the central remote
 func (p *publisher) PushToCentral(branch string, force bool) error {
-	remote, err := p.remotes.centralRemote()
+	return p.push(p.remotes.centralRemote, branch, force)
+}
+
+func (p *publisher) push(resolve RemoteResolver, branch string, force bool) error {
+	remote, err := resolve()
 	if err != nil {
 		return err
 	}
@@ -346,3 +346,28 @@ func TestPublisher_PushToCentral(t *testing.T) {
 		})
 	}
 }
+
+func TestPublisher_PushToNamedFork(t *testing.T) {
+	e := fakeExecutor{
+		records: [][]string{},
+		responses: map[string]execResponse{
+			"push --force https://github.com/bot/repo-1 master": {
+				out: []byte("ok"),
+			},
+		},
+	}
+	p := publisher{
+		executor: &e,
+		remotes: remotes{forkRemote: func(forkName string) RemoteResolver {
+			r := fakeResolver{out: "https://github.com/bot/" + forkName}
+			return r.Resolve
+		}},
+		logger: logrus.WithField("test", t.Name()),
+	}
+	if err := p.PushToNamedFork("repo-1", "master", true); err != nil {
+		t.Errorf("expected no error but got one: %v", err)
+	}
+	if actual, expected := e.records, [][]string{{"push", "--force", "https://github.com/bot/repo-1", "master"}}; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("got incorrect git calls: %v", cmp.Diff(actual, expected))
+	}
+}
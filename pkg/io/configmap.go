@@ -0,0 +1,91 @@
+package io
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/util/retry"
+)
+
+// configMapObject returns the name and key of the ConfigMap entry identified by a configmap://name/key URI
+func configMapObject(path string) (string, string, error) {
+	parts := strings.SplitN(strings.TrimPrefix(path, ConfigMapScheme), "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", errors.Errorf("invalid ConfigMap URI %s, it should be of the form %sname/key", path, ConfigMapScheme)
+	}
+	if errs := validation.IsConfigMapKey(parts[1]); len(errs) > 0 {
+		return "", "", errors.Errorf("invalid ConfigMap key in %s: %s", path, strings.Join(errs, ", "))
+	}
+	return parts[0], parts[1], nil
+}
+
+func getConfigMapEntry(ctx context.Context, configMaps corev1client.ConfigMapInterface, name, key string) ([]byte, error) {
+	cm, err := configMaps.Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, errors.Wrapf(errObjectNotFound, "getting ConfigMap %s", name)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting ConfigMap %s", name)
+	}
+	text, ok := cm.Data[key]
+	if !ok {
+		return nil, errors.Wrapf(errObjectNotFound, "getting key %s of ConfigMap %s", key, name)
+	}
+	return []byte(text), nil
+}
+
+// configMapWriter buffers the content of the ConfigMap entry until it is closed
+type configMapWriter struct {
+	ctx        context.Context
+	configMaps corev1client.ConfigMapInterface
+	name       string
+	key        string
+	data       []byte
+	closed     bool
+}
+
+func (w *configMapWriter) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, errors.New("writer is closed")
+	}
+	w.data = append(w.data, p...)
+	return len(p), nil
+}
+
+// Close creates or updates the ConfigMap, leaving its other entries untouched
+func (w *configMapWriter) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		cm, err := w.configMaps.Get(w.ctx, w.name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			cm = &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: w.name},
+				Data:       map[string]string{w.key: string(w.data)},
+			}
+			_, err = w.configMaps.Create(w.ctx, cm, metav1.CreateOptions{})
+			if apierrors.IsAlreadyExists(err) {
+				// someone else created it first so lets retry as an update
+				return apierrors.NewConflict(v1.Resource("configmaps"), w.name, err)
+			}
+			return errors.Wrapf(err, "creating ConfigMap %s", w.name)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "getting ConfigMap %s", w.name)
+		}
+		if cm.Data == nil {
+			cm.Data = map[string]string{}
+		}
+		cm.Data[w.key] = string(w.data)
+		_, err = w.configMaps.Update(w.ctx, cm, metav1.UpdateOptions{})
+		return err
+	})
+}
@@ -0,0 +1,121 @@
+package io
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+const (
+	gcsEndpoint = "https://storage.googleapis.com"
+	gcsScope    = "https://www.googleapis.com/auth/devstorage.read_write"
+)
+
+// gcsClient gets and puts objects using the JSON API of Google Cloud Storage, authenticated with the
+// service account key file if specified, otherwise with the Application Default Credentials such as
+// Workload Identity or the GOOGLE_APPLICATION_CREDENTIALS environment variable
+type gcsClient struct {
+	endpoint        string
+	credentialsFile string
+
+	lock       sync.Mutex
+	httpClient *http.Client
+}
+
+func newGCSClient(credentialsFile string) *gcsClient {
+	return &gcsClient{endpoint: gcsEndpoint, credentialsFile: credentialsFile}
+}
+
+// client lazily creates the authenticated HTTP client so that the credentials are only required
+// once a gs:// URI is used
+func (c *gcsClient) client() (*http.Client, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.httpClient != nil {
+		return c.httpClient, nil
+	}
+	if c.credentialsFile == "" {
+		client, err := google.DefaultClient(context.Background(), gcsScope)
+		if err != nil {
+			return nil, errors.Wrap(err, "finding the default Google Cloud credentials")
+		}
+		client.Timeout = time.Minute
+		c.httpClient = client
+		return client, nil
+	}
+	data, err := os.ReadFile(c.credentialsFile)
+	if err != nil {
+		// not wrapped so that a missing credentials file is not mistaken for a missing object
+		return nil, errors.Errorf("reading GCS credentials file %s: %v", c.credentialsFile, err)
+	}
+	creds, err := google.CredentialsFromJSON(context.Background(), data, gcsScope)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing GCS credentials file %s", c.credentialsFile)
+	}
+	client := oauth2.NewClient(context.Background(), creds.TokenSource)
+	client.Timeout = time.Minute
+	c.httpClient = client
+	return client, nil
+}
+
+func (c *gcsClient) get(ctx context.Context, bucket, key string) ([]byte, error) {
+	u := c.endpoint + "/storage/v1/b/" + url.PathEscape(bucket) + "/o/" + url.PathEscape(key) + "?alt=media"
+	resp, err := c.do(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading object %s/%s", bucket, key)
+	}
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, errors.Wrapf(errObjectNotFound, "getting object %s/%s", bucket, key)
+	case resp.StatusCode != http.StatusOK:
+		return nil, errors.Errorf("getting object %s/%s: status %d: %s", bucket, key, resp.StatusCode, string(data))
+	}
+	return data, nil
+}
+
+func (c *gcsClient) put(ctx context.Context, bucket, key string, data []byte) error {
+	u := c.endpoint + "/upload/storage/v1/b/" + url.PathEscape(bucket) + "/o?uploadType=media&name=" + url.QueryEscape(key)
+	resp, err := c.do(ctx, http.MethodPost, u, data)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return errors.Errorf("putting object %s/%s: status %d: %s", bucket, key, resp.StatusCode, string(body))
+	}
+	return nil
+}
+
+func (c *gcsClient) do(ctx context.Context, method, u string, body []byte) (*http.Response, error) {
+	client, err := c.client()
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/octet-stream")
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s %s", method, u)
+	}
+	return resp, nil
+}
@@ -0,0 +1,243 @@
+// Package io reads and writes objects stored either on the local file system, in a
+// ConfigMap, in Google Cloud Storage or in an S3 compatible object store, such as AWS S3 or MinIO.
+package io
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+)
+
+const (
+	// S3Scheme is the URI scheme of objects stored in AWS S3 or any other S3 compatible object store
+	S3Scheme = "s3://"
+	// GSScheme is the URI scheme of objects stored in Google Cloud Storage, which is accessed with
+	// the Google Cloud credentials
+	GSScheme = "gs://"
+	// FileScheme is the optional URI scheme of local files
+	FileScheme = "file://"
+	// ConfigMapScheme is the URI scheme of objects stored as an entry of a ConfigMap, e.g. configmap://name/key
+	ConfigMapScheme = "configmap://"
+
+	defaultS3Region = "us-east-1"
+)
+
+// Opener opens the objects identified by a /local/path, file://, configmap://name/key, s3://bucket/key or
+// gs://bucket/key URI
+type Opener interface {
+	// Reader returns a reader of the object, or an error satisfying IsNotExist if there is no such object
+	Reader(ctx context.Context, path string) (io.ReadCloser, error)
+	// Writer returns a writer replacing the content of the object. The object is only written once the
+	// writer is closed.
+	Writer(ctx context.Context, path string) (io.WriteCloser, error)
+}
+
+// S3Credentials configures the access to an S3 compatible object store
+type S3Credentials struct {
+	// Region of the bucket. Defaults to us-east-1.
+	Region string `json:"region,omitempty"`
+	// Endpoint is the host[:port] of the object store. Defaults to the AWS S3 endpoint of the region.
+	Endpoint string `json:"endpoint,omitempty"`
+	// Insecure uses http rather than https to access the object store
+	Insecure bool `json:"insecure,omitempty"`
+	// AccessKey is the access key id
+	AccessKey string `json:"access_key"`
+	// SecretKey is the secret access key
+	SecretKey string `json:"secret_key"`
+	// SessionToken is the optional token of temporary credentials
+	SessionToken string `json:"session_token,omitempty"`
+}
+
+// objectClient gets and puts the objects of a bucket
+type objectClient interface {
+	get(ctx context.Context, bucket, key string) ([]byte, error)
+	put(ctx context.Context, bucket, key string, data []byte) error
+}
+
+type opener struct {
+	credentials *S3Credentials
+	gcs         *gcsClient
+	configMaps  corev1client.ConfigMapInterface
+}
+
+// NewOpener creates an opener of local files, of the entries of the ConfigMaps if configMaps is not nil,
+// of objects in Google Cloud Storage and of objects in S3 compatible object stores.
+// The Google Cloud Storage objects are accessed with the service account key gcsCredentialsFile if
+// specified, otherwise with the Application Default Credentials.
+// The S3 credentials are read from the JSON s3CredentialsFile if specified, otherwise from the standard
+// AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, AWS_SESSION_TOKEN, AWS_REGION and AWS_ENDPOINT_URL
+// environment variables.
+func NewOpener(gcsCredentialsFile, s3CredentialsFile string, configMaps corev1client.ConfigMapInterface) (Opener, error) {
+	gcs := newGCSClient(gcsCredentialsFile)
+	if s3CredentialsFile == "" {
+		return &opener{credentials: credentialsFromEnv(), gcs: gcs, configMaps: configMaps}, nil
+	}
+	data, err := os.ReadFile(s3CredentialsFile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading S3 credentials file %s", s3CredentialsFile)
+	}
+	creds := &S3Credentials{}
+	if err := json.Unmarshal(data, creds); err != nil {
+		return nil, errors.Wrapf(err, "parsing S3 credentials file %s", s3CredentialsFile)
+	}
+	return &opener{credentials: creds, gcs: gcs, configMaps: configMaps}, nil
+}
+
+func credentialsFromEnv() *S3Credentials {
+	creds := &S3Credentials{
+		Region:       os.Getenv("AWS_REGION"),
+		AccessKey:    os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretKey:    os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken: os.Getenv("AWS_SESSION_TOKEN"),
+	}
+	if endpoint := os.Getenv("AWS_ENDPOINT_URL"); endpoint != "" {
+		creds.Insecure = strings.HasPrefix(endpoint, "http://")
+		creds.Endpoint = strings.TrimPrefix(strings.TrimPrefix(endpoint, "http://"), "https://")
+	}
+	return creds
+}
+
+// IsNotExist returns true if the error indicates that the object does not exist
+func IsNotExist(err error) bool {
+	return os.IsNotExist(errors.Cause(err)) || errors.Cause(err) == errObjectNotFound
+}
+
+// Reader returns a reader of the object
+func (o *opener) Reader(ctx context.Context, path string) (io.ReadCloser, error) {
+	if strings.HasPrefix(path, ConfigMapScheme) {
+		name, key, err := o.configMapObject(path)
+		if err != nil {
+			return nil, err
+		}
+		data, err := getConfigMapEntry(ctx, o.configMaps, name, key)
+		if err != nil {
+			return nil, err
+		}
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+	client, bucket, key, err := o.objectClient(path)
+	if err != nil {
+		return nil, err
+	}
+	if client == nil {
+		return os.Open(strings.TrimPrefix(path, FileScheme))
+	}
+	data, err := client.get(ctx, bucket, key)
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+// Writer returns a writer replacing the content of the object
+func (o *opener) Writer(ctx context.Context, path string) (io.WriteCloser, error) {
+	if strings.HasPrefix(path, ConfigMapScheme) {
+		name, key, err := o.configMapObject(path)
+		if err != nil {
+			return nil, err
+		}
+		return &configMapWriter{ctx: ctx, configMaps: o.configMaps, name: name, key: key}, nil
+	}
+	client, bucket, key, err := o.objectClient(path)
+	if err != nil {
+		return nil, err
+	}
+	if client == nil {
+		return newFileWriter(strings.TrimPrefix(path, FileScheme))
+	}
+	return &objectWriter{ctx: ctx, client: client, bucket: bucket, key: key}, nil
+}
+
+func (o *opener) configMapObject(path string) (string, string, error) {
+	if o.configMaps == nil {
+		return "", "", errors.Errorf("cannot open %s as ConfigMaps are not supported by this opener", path)
+	}
+	return configMapObject(path)
+}
+
+// objectClient returns the client, bucket and key of an object store URI, or a nil client for local files
+func (o *opener) objectClient(path string) (objectClient, string, string, error) {
+	if !strings.HasPrefix(path, S3Scheme) && !strings.HasPrefix(path, GSScheme) {
+		return nil, "", "", nil
+	}
+	parts := strings.SplitN(path[strings.Index(path, "://")+3:], "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil, "", "", errors.Errorf("invalid object URI %s, it should be of the form scheme://bucket/path/to/object", path)
+	}
+	if strings.HasPrefix(path, GSScheme) {
+		return o.gcs, parts[0], parts[1], nil
+	}
+	region := defaultS3Region
+	if o.credentials.Region != "" {
+		region = o.credentials.Region
+	}
+	endpoint := "s3.amazonaws.com"
+	if region != defaultS3Region {
+		endpoint = "s3." + region + ".amazonaws.com"
+	}
+	if o.credentials.Endpoint != "" {
+		endpoint = o.credentials.Endpoint
+	}
+	scheme := "https"
+	if o.credentials.Insecure {
+		scheme = "http"
+	}
+	return newS3Client(scheme+"://"+endpoint, region, o.credentials), parts[0], parts[1], nil
+}
+
+// objectWriter buffers the content of the object until it is closed
+type objectWriter struct {
+	ctx    context.Context
+	client objectClient
+	bucket string
+	key    string
+	data   []byte
+	closed bool
+}
+
+func (w *objectWriter) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, errors.New("writer is closed")
+	}
+	w.data = append(w.data, p...)
+	return len(p), nil
+}
+
+func (w *objectWriter) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	return w.client.put(w.ctx, w.bucket, w.key, w.data)
+}
+
+// fileWriter writes to a temporary file which is renamed on close so that readers never see partial content
+type fileWriter struct {
+	*os.File
+	path string
+}
+
+func newFileWriter(path string) (*fileWriter, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, errors.Wrapf(err, "creating the directory of %s", path)
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return nil, err
+	}
+	return &fileWriter{File: f, path: path}, nil
+}
+
+func (w *fileWriter) Close() error {
+	if err := w.File.Close(); err != nil {
+		return err
+	}
+	return os.Rename(w.Name(), w.path)
+}
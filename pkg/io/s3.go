@@ -0,0 +1,158 @@
+package io
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	amzDateFormat  = "20060102T150405Z"
+	amzShortFormat = "20060102"
+)
+
+var errObjectNotFound = errors.New("object not found")
+
+// s3Client gets and puts objects using the S3 REST API with path-style addressing and
+// AWS signature version 4, which are supported by most S3 compatible object stores
+type s3Client struct {
+	endpoint    string
+	region      string
+	credentials *S3Credentials
+	httpClient  *http.Client
+
+	now func() time.Time
+}
+
+func newS3Client(endpoint, region string, credentials *S3Credentials) *s3Client {
+	return &s3Client{
+		endpoint:    endpoint,
+		region:      region,
+		credentials: credentials,
+		httpClient:  &http.Client{Timeout: time.Minute},
+		now:         time.Now,
+	}
+}
+
+func (c *s3Client) get(ctx context.Context, bucket, key string) ([]byte, error) {
+	resp, err := c.do(ctx, http.MethodGet, bucket, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading object %s/%s", bucket, key)
+	}
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, errors.Wrapf(errObjectNotFound, "getting object %s/%s", bucket, key)
+	case resp.StatusCode != http.StatusOK:
+		return nil, errors.Errorf("getting object %s/%s: status %d: %s", bucket, key, resp.StatusCode, string(data))
+	}
+	return data, nil
+}
+
+func (c *s3Client) put(ctx context.Context, bucket, key string, data []byte) error {
+	resp, err := c.do(ctx, http.MethodPut, bucket, key, data)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return errors.Errorf("putting object %s/%s: status %d: %s", bucket, key, resp.StatusCode, string(body))
+	}
+	return nil
+}
+
+func (c *s3Client) do(ctx context.Context, method, bucket, key string, body []byte) (*http.Response, error) {
+	u, err := url.Parse(c.endpoint)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing endpoint %s", c.endpoint)
+	}
+	u.Path = "/" + bucket + "/" + key
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	c.sign(req, body)
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s %s", method, u.String())
+	}
+	return resp, nil
+}
+
+// sign adds the AWS signature version 4 authorization headers to the request, signing the host and all
+// the headers already set on the request
+func (c *s3Client) sign(req *http.Request, body []byte) {
+	t := c.now().UTC()
+	payloadHash := sha256Hex(body)
+	req.Header.Set("X-Amz-Date", t.Format(amzDateFormat))
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if c.credentials.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", c.credentials.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.Query().Encode(),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := strings.Join([]string{t.Format(amzShortFormat), c.region, "s3", "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		t.Format(amzDateFormat),
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+c.credentials.SecretKey), t.Format(amzShortFormat))
+	key = hmacSHA256(key, c.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", c.credentials.AccessKey, scope, signedHeaders, signature))
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
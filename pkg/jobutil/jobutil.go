@@ -18,6 +18,8 @@ limitations under the License.
 package jobutil
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -227,6 +229,13 @@ func GenerateName(spec *v1alpha1.LighthouseJobSpec) string {
 	return name
 }
 
+// DeterministicName returns a name for the LighthouseJob which only depends on the spec's generated name,
+// the job name and the given key, so that creating the same job twice fails with an AlreadyExists error
+func DeterministicName(spec *v1alpha1.LighthouseJobSpec, key string) string {
+	sum := sha256.Sum256([]byte(spec.Job + "/" + key))
+	return GenerateName(spec) + hex.EncodeToString(sum[:])[:10]
+}
+
 func addNonEmptyParts(values ...string) string {
 	var parts []string
 	for _, v := range values {
@@ -304,6 +313,9 @@ func LabelsAndAnnotationsForSpec(spec v1alpha1.LighthouseJobSpec, extraLabels, e
 			labels[util.LastCommitSHALabel] = spec.Refs.BaseSHA
 		}
 	}
+	if spec.ReleaseID != "" {
+		labels[util.ReleaseIDLabel] = spec.ReleaseID
+	}
 
 	for k, v := range extraLabels {
 		labels[k] = v
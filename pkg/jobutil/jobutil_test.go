@@ -19,6 +19,7 @@ package jobutil
 import (
 	"os"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -28,6 +29,7 @@ import (
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
 
 	"github.com/jenkins-x/go-scm/scm"
 	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
@@ -810,3 +812,23 @@ func TestGenerateName(t *testing.T) {
 		assert.Equal(t, tc.expected, actual, "for spec %#v", spec)
 	}
 }
+
+func TestDeterministicName(t *testing.T) {
+	spec := &v1alpha1.LighthouseJobSpec{
+		Job: "release",
+		Refs: &v1alpha1.Refs{
+			Org:     "organisation-with-long-name",
+			Repo:    "repo-with-very-long-name",
+			BaseRef: "v1.2.3",
+		},
+	}
+	name := DeterministicName(spec, "42")
+	assert.Equal(t, name, DeterministicName(spec, "42"))
+	assert.NotEqual(t, name, DeterministicName(spec, "43"))
+	assert.True(t, strings.HasPrefix(name, GenerateName(spec)))
+	assert.Empty(t, validation.IsDNS1123Label(name))
+
+	other := *spec
+	other.Job = "publish"
+	assert.NotEqual(t, name, DeterministicName(&other, "42"))
+}
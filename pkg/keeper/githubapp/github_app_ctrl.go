@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"net/http"
 	"os"
+	"path"
+	"strings"
 	"sync"
 
 	"github.com/hashicorp/go-multierror"
@@ -13,6 +15,7 @@ import (
 	"github.com/jenkins-x/lighthouse/pkg/clients"
 	"github.com/jenkins-x/lighthouse/pkg/config"
 	"github.com/jenkins-x/lighthouse/pkg/git"
+	pkgio "github.com/jenkins-x/lighthouse/pkg/io"
 	"github.com/jenkins-x/lighthouse/pkg/keeper"
 	"github.com/jenkins-x/lighthouse/pkg/keeper/history"
 	"github.com/jenkins-x/lighthouse/pkg/launcher"
@@ -30,6 +33,7 @@ type gitHubAppKeeperController struct {
 	botName           string
 	gitKind           string
 	maxRecordsPerPool int
+	opener            pkgio.Opener
 	historyURI        string
 	statusURI         string
 	ns                string
@@ -39,7 +43,7 @@ type gitHubAppKeeperController struct {
 
 // NewGitHubAppKeeperController creates a GitHub App style controller which needs to process each github owner
 // using a separate git provider client due to the way GitHub App tokens work
-func NewGitHubAppKeeperController(githubAppSecretDir string, configAgent *config.Agent, botName string, gitKind string, maxRecordsPerPool int, historyURI string, statusURI string, ns string) (keeper.Controller, error) {
+func NewGitHubAppKeeperController(githubAppSecretDir string, configAgent *config.Agent, botName string, gitKind string, maxRecordsPerPool int, opener pkgio.Opener, historyURI string, statusURI string, ns string) (keeper.Controller, error) {
 	gitServer := util.GithubServer
 	return &gitHubAppKeeperController{
 		ownerTokenFinder:  util.NewOwnerTokensDir(gitServer, githubAppSecretDir),
@@ -48,6 +52,7 @@ func NewGitHubAppKeeperController(githubAppSecretDir string, configAgent *config
 		botName:           botName,
 		gitKind:           gitKind,
 		maxRecordsPerPool: maxRecordsPerPool,
+		opener:            opener,
 		historyURI:        historyURI,
 		statusURI:         statusURI,
 		ns:                ns,
@@ -103,7 +108,8 @@ func (g *gitHubAppKeeperController) ServeHTTP(w http.ResponseWriter, r *http.Req
 }
 
 func (g *gitHubAppKeeperController) GetHistory() *history.History {
-	answer, err := history.New(g.maxRecordsPerPool, g.historyURI)
+	// the history of each owner is persisted by its own controller
+	answer, err := history.New(g.maxRecordsPerPool, nil, "")
 	if err != nil {
 		return answer
 	}
@@ -172,10 +178,20 @@ func (g *gitHubAppKeeperController) createOwnerController(owner string, configGe
 		return nil, errors.Wrap(err, "Error creating kubernetes resource clients.")
 	}
 	launcherClient := launcher.NewLauncher(lhClient, g.ns)
-	c, err := keeper.NewController(gitproviderClient, gitproviderClient, nil, launcherClient, tektonClient, lhClient, g.ns, configGetter, gitClient, g.maxRecordsPerPool, g.historyURI, g.statusURI, nil)
+	c, err := keeper.NewController(gitproviderClient, gitproviderClient, nil, launcherClient, tektonClient, lhClient, g.ns, configGetter, gitClient, g.maxRecordsPerPool, g.opener, ownerURI(g.historyURI, owner), ownerURI(g.statusURI, owner), nil)
 	return c, err
 }
 
+// ownerURI returns the URI at which the controller of an owner stores its state, so that the
+// controllers of the different owners do not overwrite each other's state
+func ownerURI(uri, owner string) string {
+	if uri == "" {
+		return ""
+	}
+	ext := path.Ext(uri)
+	return strings.TrimSuffix(uri, ext) + "-" + owner + ext
+}
+
 func createKeeperGitHubAppScmClient(gitServer string, token string) (*scm.Client, error) {
 	botName := os.Getenv("GIT_USER")
 	client, err := factory.NewClient("github", gitServer, "", factory.SetUsername(botName))
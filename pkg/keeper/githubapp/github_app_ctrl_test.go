@@ -0,0 +1,13 @@
+package githubapp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOwnerURI(t *testing.T) {
+	assert.Equal(t, "", ownerURI("", "myorg"))
+	assert.Equal(t, "/data/history-myorg.json", ownerURI("/data/history.json", "myorg"))
+	assert.Equal(t, "gs://bucket/keeper/status-myorg", ownerURI("gs://bucket/keeper/status", "myorg"))
+}
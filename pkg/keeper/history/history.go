@@ -19,13 +19,17 @@ limitations under the License.
 package history
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
 	"sort"
 	"sync"
 	"time"
 
 	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	pkgio "github.com/jenkins-x/lighthouse/pkg/io"
 	"github.com/sirupsen/logrus"
 )
 
@@ -40,17 +44,58 @@ type History struct {
 	sync.Mutex
 	logSizeLimit int
 
-	path string
+	opener pkgio.Opener
+	path   string
 }
 
-func readHistory(maxRecordsPerKey int, path string) (map[string]*recordLog, error) {
-	// TODO: This all needs to be removed eventually but for the moment, we're just going to make history no-op. (apb)
-	return map[string]*recordLog{}, nil
+func readHistory(maxRecordsPerKey int, opener pkgio.Opener, path string) (map[string]*recordLog, error) {
+	reader, err := opener.Reader(context.Background(), path)
+	if pkgio.IsNotExist(err) {
+		// there is no history yet
+		return map[string]*recordLog{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	raw, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	var recordsByPool map[string][]*Record
+	if err := json.Unmarshal(raw, &recordsByPool); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal history from %s: %v", path, err)
+	}
+
+	logsByPool := make(map[string]*recordLog, len(recordsByPool))
+	for poolKey, records := range recordsByPool {
+		logsByPool[poolKey] = newRecordLog(maxRecordsPerKey)
+		// the records are sorted from the newest to the oldest, only keep the newest ones
+		limit := maxRecordsPerKey
+		if len(records) < limit {
+			limit = len(records)
+		}
+		for i := limit - 1; i >= 0; i-- {
+			logsByPool[poolKey].add(records[i])
+		}
+	}
+	return logsByPool, nil
 }
 
-func writeHistory(path string, hist map[string][]*Record) error {
-	// TODO: This all needs to be removed eventually but for the moment, we're just going to make history no-op. (apb)
-	return nil
+func writeHistory(opener pkgio.Opener, path string, hist map[string][]*Record) error {
+	b, err := json.Marshal(hist)
+	if err != nil {
+		return fmt.Errorf("failed to marshal history: %v", err)
+	}
+	writer, err := opener.Writer(context.Background(), path)
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(b); err != nil {
+		writer.Close() //nolint:errcheck
+		return err
+	}
+	return writer.Close()
 }
 
 // Record is an entry describing one action that Keeper has taken (e.g. TRIGGER or MERGE).
@@ -62,19 +107,21 @@ type Record struct {
 	Err     string          `json:"err,omitempty"`
 }
 
-// New creates a new History struct with the specificed recordLog size limit.
-func New(maxRecordsPerKey int, path string) (*History, error) {
+// New creates a new History struct with the specificed recordLog size limit. If a path is
+// specified, the history is loaded from it and flushed back to it using the opener.
+func New(maxRecordsPerKey int, opener pkgio.Opener, path string) (*History, error) {
 	hist := &History{
 		logs:         map[string]*recordLog{},
 		logSizeLimit: maxRecordsPerKey,
+		opener:       opener,
 		path:         path,
 	}
 
 	if path != "" {
-		// Load existing history from GCS.
+		// Load existing history from the file or object store.
 		var err error
 		start := time.Now()
-		hist.logs, err = readHistory(maxRecordsPerKey, hist.path)
+		hist.logs, err = readHistory(maxRecordsPerKey, hist.opener, hist.path)
 		if err != nil {
 			return nil, err
 		}
@@ -131,13 +178,13 @@ func (h *History) Flush() {
 	}
 	records := h.AllRecords()
 	start := time.Now()
-	err := writeHistory(h.path, records)
+	err := writeHistory(h.opener, h.path, records)
 	log := logrus.WithFields(logrus.Fields{
 		"duration": time.Since(start).String(),
 		"path":     h.path,
 	})
 	if err != nil {
-		log.WithError(err).Error("Error flushing action history to GCS.")
+		log.WithError(err).Error("Error flushing action history.")
 	} else {
 		log.Debugf("Successfully flushed action history for %d pools.", len(h.logs))
 	}
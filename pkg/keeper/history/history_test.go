@@ -19,11 +19,21 @@ package history
 import (
 	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	pkgio "github.com/jenkins-x/lighthouse/pkg/io"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestHistory(t *testing.T) {
@@ -47,7 +57,7 @@ func TestHistory(t *testing.T) {
 		}
 	}
 
-	hist, err := New(logSizeLimit, "")
+	hist, err := New(logSizeLimit, nil, "")
 	if err != nil {
 		t.Fatalf("Failed to create history client: %v", err)
 	}
@@ -122,3 +132,71 @@ func TestHistory(t *testing.T) {
 		t.Logf("strs equal: %v.", string(es) == string(gs))
 	}
 }
+
+func TestPersistence(t *testing.T) {
+	// a local stand-in for an S3 compatible object store
+	var lock sync.Mutex
+	objects := map[string][]byte{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+		switch r.Method {
+		case http.MethodGet:
+			data, ok := objects[r.URL.Path]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			w.Write(data) //nolint:errcheck
+		case http.MethodPut:
+			data, _ := io.ReadAll(r.Body)
+			objects[r.URL.Path] = data
+		}
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	credentialsFile := filepath.Join(dir, "credentials.json")
+	credentials := fmt.Sprintf(`{"endpoint": %q, "insecure": true, "access_key": "key", "secret_key": "secret"}`, strings.TrimPrefix(server.URL, "http://"))
+	require.NoError(t, os.WriteFile(credentialsFile, []byte(credentials), 0o600))
+	opener, err := pkgio.NewOpener("", credentialsFile, nil)
+	require.NoError(t, err)
+
+	for _, path := range []string{filepath.Join(dir, "history.json"), "s3://bucket/history.json", "s3://other-bucket/history.json"} {
+		t.Run(path, func(t *testing.T) {
+			hist, err := New(3, opener, path)
+			require.NoError(t, err)
+			assert.Empty(t, hist.AllRecords(), "there should be no history before the first flush")
+
+			for i := 1; i <= 3; i++ {
+				hist.Record("pool A", "MERGE", fmt.Sprintf("sha %d", i), "", []v1alpha1.Pull{{Number: i}})
+			}
+			hist.Record("pool B", "TRIGGER", "sha B", "", nil)
+			hist.Flush()
+
+			reloaded, err := New(3, opener, path)
+			require.NoError(t, err)
+			assert.Equal(t, toJSON(t, hist.AllRecords()), toJSON(t, reloaded.AllRecords()))
+
+			// the record cap is still enforced when loading a longer history
+			capped, err := New(2, opener, path)
+			require.NoError(t, err)
+			records := capped.AllRecords()
+			require.Len(t, records["pool A"], 2)
+			assert.Equal(t, "sha 3", records["pool A"][0].BaseSHA)
+			assert.Equal(t, "sha 2", records["pool A"][1].BaseSHA)
+			capped.Record("pool A", "MERGE", "sha 4", "", nil)
+			records = capped.AllRecords()
+			require.Len(t, records["pool A"], 2)
+			assert.Equal(t, "sha 4", records["pool A"][0].BaseSHA)
+		})
+	}
+	assert.Contains(t, objects, "/bucket/history.json")
+	assert.Contains(t, objects, "/other-bucket/history.json")
+}
+
+func toJSON(t *testing.T, records map[string][]*Record) string {
+	data, err := json.Marshal(records)
+	require.NoError(t, err)
+	return string(data)
+}
@@ -42,6 +42,7 @@ import (
 	"github.com/jenkins-x/lighthouse/pkg/errorutil"
 	"github.com/jenkins-x/lighthouse/pkg/filebrowser"
 	"github.com/jenkins-x/lighthouse/pkg/git"
+	pkgio "github.com/jenkins-x/lighthouse/pkg/io"
 	"github.com/jenkins-x/lighthouse/pkg/jobutil"
 	"github.com/jenkins-x/lighthouse/pkg/keeper/blockers"
 	"github.com/jenkins-x/lighthouse/pkg/keeper/history"
@@ -232,11 +233,11 @@ func init() {
 }
 
 // NewController makes a DefaultController out of the given clients.
-func NewController(spcSync, spcStatus *scmprovider.Client, fileBrowsers *filebrowser.FileBrowsers, launcherClient launcher, tektonClient tektonclient.Interface, lighthouseClient clientset.Interface, ns string, cfg config.Getter, gc git.Client, maxRecordsPerPool int, historyURI, statusURI string, logger *logrus.Entry) (*DefaultController, error) {
+func NewController(spcSync, spcStatus *scmprovider.Client, fileBrowsers *filebrowser.FileBrowsers, launcherClient launcher, tektonClient tektonclient.Interface, lighthouseClient clientset.Interface, ns string, cfg config.Getter, gc git.Client, maxRecordsPerPool int, opener pkgio.Opener, historyURI, statusURI string, logger *logrus.Entry) (*DefaultController, error) {
 	if logger == nil {
 		logger = logrus.NewEntry(logrus.StandardLogger())
 	}
-	hist, err := history.New(maxRecordsPerPool, historyURI)
+	hist, err := history.New(maxRecordsPerPool, opener, historyURI)
 	if err != nil {
 		return nil, fmt.Errorf("error initializing history client from %q: %v", historyURI, err)
 	}
@@ -246,6 +247,7 @@ func NewController(spcSync, spcStatus *scmprovider.Client, fileBrowsers *filebro
 		config:         cfg,
 		newPoolPending: make(chan bool, 1),
 		shutDown:       make(chan bool),
+		opener:         opener,
 		path:           statusURI,
 	}
 	go sc.run()
@@ -742,15 +744,16 @@ func pickSmallestPassingNumber(log *logrus.Entry, spc scmProviderClient, prs []P
 	return smallestNumber > -1, smallestPR
 }
 
-// accumulateBatch returns a list of PRs that can be merged after passing batch
-// testing, if any exist. It also returns a list of PRs currently being batch
-// tested.
-func accumulateBatch(presubmits map[int][]job.Presubmit, prs []PullRequest, pjs []v1alpha1.LighthouseJob, log *logrus.Entry) ([]PullRequest, []PullRequest) {
-	log.Debug("accumulating PRs for batch testing")
-	if len(presubmits) == 0 {
-		log.Debug("no presubmits configured, no batch can be triggered")
-		return nil, nil
-	}
+// batchResult is the accumulated state of the batch jobs testing a set of PRs
+type batchResult struct {
+	ref   string
+	prs   []PullRequest
+	state simpleState
+}
+
+// accumulateBatchResults returns the accumulated state of each batch whose PRs still
+// point to the heads of the PRs in the pool, ordered by ref.
+func accumulateBatchResults(presubmits map[int][]job.Presubmit, prs []PullRequest, pjs []v1alpha1.LighthouseJob, log *logrus.Entry) []batchResult {
 	prNums := make(map[int]PullRequest)
 	for _, pr := range prs {
 		prNums[int(pr.Number)] = pr
@@ -803,7 +806,7 @@ func accumulateBatch(presubmits map[int][]job.Presubmit, prs []PullRequest, pjs
 			states[ref].jobStates[context] = jobState
 		}
 	}
-	var pendingBatch, successBatch []PullRequest
+	var results []batchResult
 	for ref, state := range states {
 		if !state.validPulls {
 			continue
@@ -824,18 +827,92 @@ func accumulateBatch(presubmits map[int][]job.Presubmit, prs []PullRequest, pjs
 				overallState = pendingState
 			}
 		}
-		switch overallState {
+		results = append(results, batchResult{ref: ref, prs: state.prs, state: overallState})
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].ref < results[j].ref })
+	return results
+}
+
+// accumulateBatch returns a list of PRs that can be merged after passing batch
+// testing, if any exist. It also returns a list of PRs currently being batch
+// tested.
+func accumulateBatch(presubmits map[int][]job.Presubmit, prs []PullRequest, pjs []v1alpha1.LighthouseJob, log *logrus.Entry) ([]PullRequest, []PullRequest) {
+	log.Debug("accumulating PRs for batch testing")
+	if len(presubmits) == 0 {
+		log.Debug("no presubmits configured, no batch can be triggered")
+		return nil, nil
+	}
+	var pendingBatch, successBatch []PullRequest
+	for _, result := range accumulateBatchResults(presubmits, prs, pjs, log) {
+		switch result.state {
 		// Currently we only consider 1 pending batch and 1 success batch at a time.
 		// If more are somehow present they will be ignored.
 		case pendingState:
-			pendingBatch = state.prs
+			pendingBatch = result.prs
 		case successState:
-			successBatch = state.prs
+			successBatch = result.prs
 		}
 	}
 	return successBatch, pendingBatch
 }
 
+// bisectFailedBatch looks for the PRs breaking the failed batches made of several PRs.
+// Each failed batch is split in two halves: a half made of a single PR is isolated so
+// that it is no longer batched and gets tested serially, the first half which has not
+// been tested yet, smallest failed batches first, is returned as the next batch to test.
+func bisectFailedBatch(presubmits map[int][]job.Presubmit, prs []PullRequest, pjs []v1alpha1.LighthouseJob, log *logrus.Entry) ([]PullRequest, sets.Int) {
+	isolated := sets.NewInt()
+	if len(presubmits) == 0 {
+		return nil, isolated
+	}
+	results := accumulateBatchResults(presubmits, prs, pjs, log)
+	tested := sets.NewString()
+	var failed [][]PullRequest
+	for _, result := range results {
+		tested.Insert(batchKey(result.prs))
+		if result.state == failureState && len(result.prs) >= 2 {
+			failed = append(failed, result.prs)
+		}
+	}
+	sort.Slice(failed, func(i, j int) bool {
+		if len(failed[i]) != len(failed[j]) {
+			return len(failed[i]) < len(failed[j])
+		}
+		return batchKey(failed[i]) < batchKey(failed[j])
+	})
+
+	var batch []PullRequest
+	for _, failedBatch := range failed {
+		n := (len(failedBatch) + 1) / 2
+		for _, half := range [][]PullRequest{failedBatch[:n], failedBatch[n:]} {
+			switch {
+			case len(half) == 1:
+				isolated.Insert(int(half[0].Number))
+			case batch == nil && !tested.Has(batchKey(half)):
+				batch = half
+				log.WithField("failed-batch", prNumbers(failedBatch)).WithField("bisected-batch", prNumbers(half)).Debug("bisecting failed batch")
+			}
+		}
+	}
+	var res []PullRequest
+	for _, pr := range batch {
+		if !isolated.Has(int(pr.Number)) {
+			res = append(res, pr)
+		}
+	}
+	if len(res) < 2 {
+		res = nil
+	}
+	return res, isolated
+}
+
+// batchKey identifies a batch by the sorted numbers of its PRs
+func batchKey(prs []PullRequest) string {
+	numbers := prNumbers(prs)
+	sort.Ints(numbers)
+	return fmt.Sprint(numbers)
+}
+
 // accumulate returns the supplied PRs sorted into three buckets based on their
 // accumulated state across the presubmits.
 func accumulate(presubmits map[int][]job.Presubmit, prs []PullRequest, pjs []v1alpha1.LighthouseJob, log *logrus.Entry) (successes, pendings, missings []PullRequest, missingTests map[int][]job.Presubmit) {
@@ -913,18 +990,31 @@ func (c *DefaultController) pickBatch(sp subpool, cc contextChecker) ([]PullRequ
 	// we must choose the oldest PRs for the batch
 	sort.Slice(sp.prs, func(i, j int) bool { return sp.prs[i].Number < sp.prs[j].Number })
 
+	// if a batch failed, lets test half of it to find out which PRs are breaking it
+	prs, isolated := bisectFailedBatch(sp.presubmits, sp.prs, sp.ljs, sp.log)
+	if prs == nil {
+		for _, pr := range sp.prs {
+			if !isolated.Has(int(pr.Number)) {
+				prs = append(prs, pr)
+			}
+		}
+	}
+	if isolated.Len() > 0 {
+		sp.log.WithField("isolated", isolated.List()).Debug("excluding the PRs isolated from failed batches, they will be tested serially")
+	}
+
 	var candidates []PullRequest
-	for _, pr := range sp.prs {
+	for _, pr := range prs {
 		if isPassingTests(sp.log, c.spc, pr, cc) {
 			candidates = append(candidates, pr)
 		}
 	}
 
 	if len(candidates) == 0 {
-		sp.log.Debugf("of %d possible PRs, none were passing tests, no batch will be created", len(sp.prs))
+		sp.log.Debugf("of %d possible PRs, none were passing tests, no batch will be created", len(prs))
 		return nil, nil
 	}
-	sp.log.Debugf("of %d possible PRs, %d are passing tests", len(sp.prs), len(candidates))
+	sp.log.Debugf("of %d possible PRs, %d are passing tests", len(prs), len(candidates))
 
 	r, err := c.gc.Clone(sp.org + "/" + sp.repo)
 	if err != nil {
@@ -1221,6 +1311,11 @@ func (c *DefaultController) trigger(sp subpool, presubmits map[int][]job.Presubm
 				c.logger.WithField("duration", time.Since(start).String()).Debug("Failed to create pipeline on the cluster.")
 				return fmt.Errorf("failed to create a pipeline for job: %q, PRs: %v: %v", spec.Job, prNumbers(prs), err)
 			}
+			if spec.Type == job.BatchJob {
+				// batch results are not reported on the PRs, only keeper uses them
+				c.logger.WithField("duration", time.Since(start).String()).Debug("Created batch pipeline on the cluster.")
+				continue
+			}
 			sha := refs.BaseSHA
 			if len(refs.Pulls) > 0 {
 				sha = refs.Pulls[0].SHA
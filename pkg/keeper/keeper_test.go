@@ -267,6 +267,97 @@ func TestAccumulateBatch(t *testing.T) {
 	}
 }
 
+func TestBisectFailedBatch(t *testing.T) {
+	presubmits := map[int][]job.Presubmit{}
+	var pulls []PullRequest
+	for i := 1; i <= 5; i++ {
+		presubmits[i] = []job.Presubmit{{Reporter: job.Reporter{Context: "foo"}}}
+		pulls = append(pulls, PullRequest{
+			Number:     githubql.Int(i),
+			HeadRefOID: githubql.String(fmt.Sprintf("sha%d", i)),
+		})
+	}
+	batchJob := func(state v1alpha1.PipelineState, numbers ...int) v1alpha1.LighthouseJob {
+		lj := v1alpha1.LighthouseJob{
+			Spec: v1alpha1.LighthouseJobSpec{
+				Job:     "foo",
+				Context: "foo",
+				Type:    job.BatchJob,
+				Refs:    new(v1alpha1.Refs),
+			},
+			Status: v1alpha1.LighthouseJobStatus{State: state},
+		}
+		for _, n := range numbers {
+			lj.Spec.Refs.Pulls = append(lj.Spec.Refs.Pulls, v1alpha1.Pull{Number: n, SHA: fmt.Sprintf("sha%d", n)})
+		}
+		return lj
+	}
+
+	tests := []struct {
+		name     string
+		ljs      []v1alpha1.LighthouseJob
+		expected []int
+		isolated []int
+	}{
+		{
+			name: "no batch",
+		},
+		{
+			name: "successful and pending batches are not bisected",
+			ljs: []v1alpha1.LighthouseJob{
+				batchJob(v1alpha1.SuccessState, 1, 2),
+				batchJob(v1alpha1.PendingState, 3, 4, 5),
+			},
+		},
+		{
+			name:     "failed batch",
+			ljs:      []v1alpha1.LighthouseJob{batchJob(v1alpha1.FailureState, 1, 2, 3, 4, 5)},
+			expected: []int{1, 2, 3},
+		},
+		{
+			name: "failed half of a failed batch",
+			ljs: []v1alpha1.LighthouseJob{
+				batchJob(v1alpha1.FailureState, 1, 2, 3, 4, 5),
+				batchJob(v1alpha1.FailureState, 1, 2, 3),
+			},
+			expected: []int{1, 2},
+			isolated: []int{3},
+		},
+		{
+			name: "second half of a failed batch",
+			ljs: []v1alpha1.LighthouseJob{
+				batchJob(v1alpha1.FailureState, 1, 2, 3, 4, 5),
+				batchJob(v1alpha1.SuccessState, 1, 2, 3),
+			},
+			expected: []int{4, 5},
+		},
+		{
+			name: "failed batch of two PRs",
+			ljs: []v1alpha1.LighthouseJob{
+				batchJob(v1alpha1.FailureState, 1, 2),
+			},
+			isolated: []int{1, 2},
+		},
+		{
+			name: "failed batch whose PR changed",
+			ljs: []v1alpha1.LighthouseJob{
+				func() v1alpha1.LighthouseJob {
+					lj := batchJob(v1alpha1.FailureState, 1, 2)
+					lj.Spec.Refs.Pulls[1].SHA = "old"
+					return lj
+				}(),
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			batch, isolated := bisectFailedBatch(presubmits, pulls, test.ljs, logrus.NewEntry(logrus.New()))
+			assert.Equal(t, test.expected, prNumbers(batch))
+			assert.ElementsMatch(t, test.isolated, isolated.List())
+		})
+	}
+}
+
 func TestAccumulate(t *testing.T) {
 	jobSet := []job.Presubmit{
 		{
@@ -834,8 +925,6 @@ func TestDividePool(t *testing.T) {
 }
 
 func TestPickBatch(t *testing.T) {
-	// TODO: Remove once #564 is fixed and batch builds can work again. (APB)
-	t.Skip("Skipping TestPickBatch until #564 is fixed and batch builds can work again")
 	lg, gc, err := localgit.New()
 	if err != nil {
 		t.Fatalf("Error making local git: %v", err)
@@ -967,6 +1056,80 @@ func TestPickBatch(t *testing.T) {
 	}
 }
 
+func TestPickBatchAfterFailedBatchOfTwoPRs(t *testing.T) {
+	lg, gc, err := localgit.New()
+	if err != nil {
+		t.Fatalf("Error making local git: %v", err)
+	}
+	defer gc.Clean() //nolint: errcheck
+	defer lg.Clean() //nolint: errcheck
+	if err := lg.MakeFakeRepo("o", "r"); err != nil {
+		t.Fatalf("Error making fake repo: %v", err)
+	}
+	if err := lg.AddCommit("o", "r", map[string][]byte{"foo": []byte("foo")}); err != nil {
+		t.Fatalf("Adding initial commit: %v", err)
+	}
+	sp := subpool{
+		log:        logrus.WithField("component", "keeper"),
+		org:        "o",
+		repo:       "r",
+		branch:     "master",
+		sha:        "master",
+		presubmits: map[int][]job.Presubmit{},
+	}
+	for number := 1; number <= 4; number++ {
+		if err := lg.CheckoutNewBranch("o", "r", fmt.Sprintf("pr-%d", number)); err != nil {
+			t.Fatalf("Error checking out new branch: %v", err)
+		}
+		if err := lg.AddCommit("o", "r", map[string][]byte{fmt.Sprintf("file-%d", number): []byte("ok")}); err != nil {
+			t.Fatalf("Error adding commit: %v", err)
+		}
+		if err := lg.Checkout("o", "r", "master"); err != nil {
+			t.Fatalf("Error checking out master: %v", err)
+		}
+		oid := githubql.String(fmt.Sprintf("origin/pr-%d", number))
+		var pr PullRequest
+		pr.Number = githubql.Int(number)
+		pr.HeadRefOID = oid
+		pr.Commits.Nodes = []struct {
+			Commit Commit
+		}{{Commit: Commit{OID: oid}}}
+		pr.Commits.Nodes[0].Commit.Status.Contexts = append(pr.Commits.Nodes[0].Commit.Status.Contexts, Context{State: githubql.StatusStateSuccess})
+		sp.prs = append(sp.prs, pr)
+		sp.presubmits[number] = []job.Presubmit{{Reporter: job.Reporter{Context: "foo"}}}
+	}
+	sp.ljs = []v1alpha1.LighthouseJob{{
+		Spec: v1alpha1.LighthouseJobSpec{
+			Job:     "foo",
+			Context: "foo",
+			Type:    job.BatchJob,
+			Refs: &v1alpha1.Refs{Pulls: []v1alpha1.Pull{
+				{Number: 1, SHA: "origin/pr-1"},
+				{Number: 2, SHA: "origin/pr-2"},
+			}},
+		},
+		Status: v1alpha1.LighthouseJobStatus{State: v1alpha1.FailureState},
+	}}
+	ca := &config.Agent{}
+	ca.Set(&config.Config{
+		ProwConfig: config.ProwConfig{
+			Keeper: keeper.Config{
+				BatchSizeLimitMap: map[string]int{"*": 5},
+			},
+		},
+	})
+	c := &DefaultController{
+		logger: logrus.WithField("component", "keeper"),
+		gc:     gc,
+		config: ca.Config,
+	}
+	prs, err := c.pickBatch(sp, &keeper.ContextPolicy{})
+	if err != nil {
+		t.Fatalf("Error from pickBatch: %v", err)
+	}
+	assert.Equal(t, []int{3, 4}, prNumbers(prs), "the PRs of the failed batch should be isolated, the other PRs batched")
+}
+
 func TestCheckMergeLabels(t *testing.T) {
 	squashLabel := "keeper/squash"
 	mergeLabel := "keeper/merge"
@@ -1345,12 +1508,14 @@ func TestTakeAction(t *testing.T) {
 
 	for _, tc := range testcases {
 		t.Run(tc.name, func(t *testing.T) {
-			// TODO: Remove once #564 is fixed and batch builds can work again. (APB)
-			if strings.Contains(tc.name, "should trigger batch") {
-				t.Skipf("Skipping TestTakeAction/%s until #564 is fixed and batch builds can work again", tc.name)
-			}
 			ca := &config.Agent{}
-			cfg := &config.Config{}
+			cfg := &config.Config{
+				ProwConfig: config.ProwConfig{
+					Keeper: keeper.Config{
+						BatchSizeLimitMap: map[string]int{"*": 0},
+					},
+				},
+			}
 			if err := cfg.SetPresubmits(
 				map[string][]job.Presubmit{
 					"o/r": {
@@ -1490,13 +1655,18 @@ func TestTakeAction(t *testing.T) {
 			var batchJobs []*v1alpha1.LighthouseJob
 			for _, activity := range fakeLauncher.Pipelines {
 				pjSha := activity.Spec.Refs.Pulls[0].SHA
-				if scm.StatePending.String() != fgc.combinedStatus[pjSha][activity.Spec.Context].status {
-					t.Errorf("Status not set to %s for context %s, is %s instead", scm.StatePending.String(), activity.Spec.Context,
-						fgc.combinedStatus[pjSha][activity.Spec.Context].status)
-				}
 				numCreated++
 				if activity.Spec.Type == job.BatchJob {
+					// batch results are not reported on the PRs
+					if status, ok := fgc.combinedStatus[pjSha][activity.Spec.Context]; ok {
+						t.Errorf("Status set to %s for batch context %s", status.status, activity.Spec.Context)
+					}
 					batchJobs = append(batchJobs, activity)
+					continue
+				}
+				if scm.StatePending.String() != fgc.combinedStatus[pjSha][activity.Spec.Context].status {
+					t.Errorf("Status not set to %s for context %s, is %s instead", scm.StatePending.String(), activity.Spec.Context,
+						fgc.combinedStatus[pjSha][activity.Spec.Context].status)
 				}
 			}
 			if tc.triggered != numCreated {
@@ -1525,7 +1695,7 @@ func TestServeHTTP(t *testing.T) {
 		Context:     githubql.String("coverage/coveralls"),
 		Description: githubql.String("Coverage increased (+0.1%) to 27.599%"),
 	}}
-	hist, err := history.New(100, "")
+	hist, err := history.New(100, nil, "")
 	if err != nil {
 		t.Fatalf("Failed to create history client: %v", err)
 	}
@@ -1758,7 +1928,7 @@ func TestSync(t *testing.T) {
 					},
 				},
 			})
-			hist, err := history.New(100, "")
+			hist, err := history.New(100, nil, "")
 			if err != nil {
 				t.Fatalf("Failed to create history client: %v", err)
 			}
@@ -17,7 +17,10 @@ limitations under the License.
 package keeper
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"net/url"
 	"os"
 	"sort"
@@ -28,6 +31,7 @@ import (
 
 	"github.com/jenkins-x/lighthouse/pkg/config"
 	"github.com/jenkins-x/lighthouse/pkg/config/keeper"
+	pkgio "github.com/jenkins-x/lighthouse/pkg/io"
 	"github.com/jenkins-x/lighthouse/pkg/keeper/blockers"
 	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
 	"github.com/pkg/errors"
@@ -47,6 +51,9 @@ const (
 
 	// StatusContextLabelEnvVar is the environment variable we look to for the overriding status context label.
 	StatusContextLabelEnvVar = "LIGHTHOUSE_KEEPER_STATUS_CONTEXT_LABEL"
+
+	// appliedStatusTTL is how long the status applied to a head commit is remembered after it was last seen
+	appliedStatusTTL = 30 * 24 * time.Hour
 )
 
 // GetStatusContextLabel gets the label used for the keeper status context, defaulting to "keeper" if the env var
@@ -63,6 +70,20 @@ type storedState struct {
 	LatestPR metav1.Time
 	// PreviousQuery is the query most recently used for results
 	PreviousQuery string
+	// Statuses are the statuses last applied to the PRs keyed by PR and head commit, see appliedStatusKey
+	Statuses map[string]appliedStatus
+}
+
+// appliedStatus is the keeper status context last applied to the head commit of a PR
+type appliedStatus struct {
+	State       string
+	Description string
+	// LastSeen is when the PR was last processed with this head commit, used to prune the stored state
+	LastSeen metav1.Time
+}
+
+func appliedStatusKey(pr *PullRequest) string {
+	return fmt.Sprintf("%s@%s", pr.prKey(), string(pr.HeadRefOID))
 }
 
 type statusController struct {
@@ -86,7 +107,10 @@ type statusController struct {
 	blocks  blockers.Blockers
 
 	storedState
-	path string
+	// dirty is true if storedState changed since it was last saved
+	dirty  bool
+	opener pkgio.Opener
+	path   string
 }
 
 func (sc *statusController) shutdown() {
@@ -320,11 +344,6 @@ func (sc *statusController) setStatuses(all []PullRequest, pool map[string]prWit
 	process := func(pr *PullRequest) {
 		processed.Insert(pr.prKey())
 		log := sc.logger.WithFields(pr.logFields())
-		contexts, err := headContexts(log, sc.spc, pr)
-		if err != nil {
-			log.WithError(err).Error("Getting head commit status contexts, skipping...")
-			return
-		}
 		cr, err := sc.config().GetKeeperContextPolicy(
 			string(pr.Repository.Owner.Login),
 			string(pr.Repository.Name),
@@ -335,6 +354,18 @@ func (sc *statusController) setStatuses(all []PullRequest, pool map[string]prWit
 		}
 
 		wantState, wantDesc := expectedStatus(queryMap, pr, pool, cr, blocks, sc.spc.ProviderType(), log)
+		key := appliedStatusKey(pr)
+		if applied, ok := sc.Statuses[key]; ok && applied.State == wantState && applied.Description == wantDesc {
+			// nothing changed since the status was last applied to the head commit
+			applied.LastSeen = metav1.Now()
+			sc.Statuses[key] = applied
+			return
+		}
+		contexts, err := headContexts(log, sc.spc, pr)
+		if err != nil {
+			log.WithError(err).Error("Getting head commit status contexts, skipping...")
+			return
+		}
 		var actualState githubql.StatusState
 		var actualDesc string
 		for _, ctx := range contexts {
@@ -364,8 +395,10 @@ func (sc *statusController) setStatuses(all []PullRequest, pool map[string]prWit
 					strings.ToLower(string(actualState)),
 					wantState,
 				)
+				return
 			}
 		}
+		sc.recordAppliedStatus(key, wantState, wantDesc)
 	}
 
 	for _, pr := range all {
@@ -390,23 +423,88 @@ func (sc *statusController) setStatuses(all []PullRequest, pool map[string]prWit
 	}
 }
 
+func (sc *statusController) recordAppliedStatus(key, state, desc string) {
+	if sc.Statuses == nil {
+		sc.Statuses = map[string]appliedStatus{}
+	}
+	sc.Statuses[key] = appliedStatus{State: state, Description: desc, LastSeen: metav1.Now()}
+	sc.dirty = true
+}
+
+// load restores the state stored at the status path, if any
 func (sc *statusController) load() {
-	// TODO: We need to do a new solution for stored state some day, but for now, no state, so this is a no-op. (apb)
-	return //nolint: gosimple
+	if sc.path == "" || sc.opener == nil {
+		sc.logger.Debug("No stored state configured")
+		return
+	}
+	log := sc.logger.WithField("path", sc.path)
+	reader, err := sc.opener.Reader(context.Background(), sc.path)
+	if err != nil {
+		if pkgio.IsNotExist(err) {
+			log.Info("No stored state found")
+		} else {
+			log.WithError(err).Warn("Cannot open stored state")
+		}
+		return
+	}
+	defer reader.Close()
+
+	buf, err := io.ReadAll(reader)
+	if err != nil {
+		log.WithError(err).Warn("Cannot read stored state")
+		return
+	}
+	var stored storedState
+	if err := json.Unmarshal(buf, &stored); err != nil {
+		log.WithError(err).Warn("Cannot unmarshal stored state")
+		return
+	}
+	sc.storedState = stored
+	log.WithField("statuses", len(stored.Statuses)).Info("Loaded stored state")
 }
 
-func (sc *statusController) save(ticker *time.Ticker) {
-	for range ticker.C {
-		// TODO: We need to do a new solution for stored state some day, but for now, no state, so this is a no-op. (apb)
+// save stores the state at the status path if it changed since it was last saved, pruning the
+// statuses of head commits which were not seen for a while
+func (sc *statusController) save() {
+	if sc.path == "" || sc.opener == nil {
+		return
+	}
+	cutoff := time.Now().Add(-appliedStatusTTL)
+	for key, applied := range sc.Statuses {
+		if applied.LastSeen.Time.Before(cutoff) {
+			delete(sc.Statuses, key)
+			sc.dirty = true
+		}
+	}
+	if !sc.dirty {
+		return
+	}
+	log := sc.logger.WithField("path", sc.path)
+	buf, err := json.Marshal(sc.storedState)
+	if err != nil {
+		log.WithError(err).Warn("Cannot marshal state")
+		return
+	}
+	writer, err := sc.opener.Writer(context.Background(), sc.path)
+	if err != nil {
+		log.WithError(err).Warn("Cannot open state writer")
+		return
+	}
+	if _, err = writer.Write(buf); err != nil {
+		log.WithError(err).Warn("Cannot write state")
+		writer.Close() //nolint:errcheck
 		return
 	}
+	if err := writer.Close(); err != nil {
+		log.WithError(err).Warn("Failed to close written state")
+		return
+	}
+	sc.dirty = false
+	log.Debug("Saved status state")
 }
 
 func (sc *statusController) run() {
 	sc.load()
-	ticks := time.NewTicker(time.Hour)
-	defer ticks.Stop()
-	go sc.save(ticks)
 	for {
 		// wait for a new pool
 		if !<-sc.newPoolPending {
@@ -451,6 +549,7 @@ func (sc *statusController) sync(pool map[string]prWithStatus, blocks blockers.B
 	}()
 
 	sc.setStatuses(sc.search(), pool, blocks)
+	sc.save()
 }
 
 func (sc *statusController) search() []PullRequest {
@@ -470,6 +569,7 @@ func (sc *statusController) search() []PullRequest {
 		log.WithField("previously", sc.PreviousQuery).Info("Query changed, resetting start time to zero")
 		sc.LatestPR = metav1.Time{}
 		sc.PreviousQuery = query
+		sc.dirty = true
 	}
 
 	var prs []PullRequest
@@ -508,8 +608,12 @@ func (sc *statusController) search() []PullRequest {
 		log.WithField("latestPR", sc.LatestPR).Debug("latest PR has zero time")
 		return prs
 	}
-	sc.LatestPR.Time = latest.Add(-30 * time.Second)
-	log.WithField("latestPR", sc.LatestPR).Debug("Advanced start time")
+	// the searches overlap, so the start time only moves when more recently updated PRs are found
+	if start := latest.Add(-30 * time.Second); start.After(sc.LatestPR.Time) {
+		sc.LatestPR.Time = start
+		sc.dirty = true
+		log.WithField("latestPR", sc.LatestPR).Debug("Advanced start time")
+	}
 	return prs
 }
 
@@ -18,17 +18,22 @@ package keeper
 
 import (
 	"fmt"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/jenkins-x/lighthouse/pkg/config/keeper"
+	pkgio "github.com/jenkins-x/lighthouse/pkg/io"
 	"github.com/jenkins-x/lighthouse/pkg/keeper/blockers"
 	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
 	githubql "github.com/shurcooL/githubv4"
 	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/jenkins-x/lighthouse/pkg/config"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
 )
 
@@ -454,6 +459,77 @@ func TestSetStatuses(t *testing.T) {
 	}
 }
 
+func TestSetStatusesStoredState(t *testing.T) {
+	opener, err := pkgio.NewOpener("", "", nil)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "status.json")
+	ca := &config.Agent{}
+	ca.Set(&config.Config{})
+	log := logrus.WithField("component", "keeper")
+
+	pr := PullRequest{Number: 1, HeadRefOID: "sha1"}
+	pr.Repository.NameWithOwner = "org/repo"
+	pr.Commits.Nodes = []struct{ Commit Commit }{{Commit: Commit{OID: "sha1"}}}
+
+	fc := &fgc{}
+	sc := &statusController{spc: fc, config: ca.Config, logger: log, opener: opener, path: path}
+	sc.load()
+	sc.setStatuses([]PullRequest{pr}, map[string]prWithStatus{}, blockers.Blockers{})
+	assert.True(t, fc.setStatus, "the status of a new PR should be set")
+	sc.save()
+
+	// a restarted controller should not set the same status again
+	fc = &fgc{}
+	sc = &statusController{spc: fc, config: ca.Config, logger: log, opener: opener, path: path}
+	sc.load()
+	require.Contains(t, sc.Statuses, "org/repo#1@sha1")
+	assert.Equal(t, scmprovider.StatusPending, sc.Statuses["org/repo#1@sha1"].State)
+	sc.setStatuses([]PullRequest{pr}, map[string]prWithStatus{}, blockers.Blockers{})
+	assert.False(t, fc.setStatus, "the stored status should not be set again")
+
+	// the status of a PR entering the pool changes
+	sc.setStatuses([]PullRequest{pr}, map[string]prWithStatus{pr.prKey(): {pr: pr}}, blockers.Blockers{})
+	assert.True(t, fc.setStatus, "the changed status should be set")
+
+	// a new head commit has no stored status
+	fc.setStatus = false
+	pr.HeadRefOID = "sha2"
+	pr.Commits.Nodes[0].Commit.OID = "sha2"
+	sc.setStatuses([]PullRequest{pr}, map[string]prWithStatus{}, blockers.Blockers{})
+	assert.True(t, fc.setStatus, "the status of a new head commit should be set")
+
+	// old statuses are pruned
+	old := sc.Statuses["org/repo#1@sha1"]
+	old.LastSeen = metav1.NewTime(time.Now().Add(-appliedStatusTTL - time.Hour))
+	sc.Statuses["org/repo#1@sha1"] = old
+	sc.save()
+	sc = &statusController{spc: fc, config: ca.Config, logger: log, opener: opener, path: path}
+	sc.load()
+	assert.NotContains(t, sc.Statuses, "org/repo#1@sha1")
+	assert.Contains(t, sc.Statuses, "org/repo#1@sha2")
+}
+
+func TestSearchOnlyDirtiesStateWhenAdvancing(t *testing.T) {
+	ca := &config.Agent{}
+	ca.Set(&config.Config{ProwConfig: config.ProwConfig{Keeper: keeper.Config{
+		Queries: keeper.Queries{{Repos: []string{"org/repo"}}},
+	}}})
+	pr := PullRequest{Number: 1, UpdatedAt: githubql.DateTime{Time: time.Now().Add(-time.Hour)}}
+	fc := &fgc{prs: []PullRequest{pr}}
+	sc := &statusController{spc: fc, config: ca.Config, logger: logrus.WithField("component", "keeper")}
+
+	require.Len(t, sc.search(), 1)
+	assert.True(t, sc.dirty, "the first search should change the state")
+
+	sc.dirty = false
+	require.Len(t, sc.search(), 1)
+	assert.False(t, sc.dirty, "finding the same PR again should not change the state")
+
+	fc.prs[0].UpdatedAt = githubql.DateTime{Time: time.Now()}
+	require.Len(t, sc.search(), 1)
+	assert.True(t, sc.dirty, "finding a more recently updated PR should advance the start time")
+}
+
 func TestTargetUrl(t *testing.T) {
 	testcases := []struct {
 		name   string
@@ -5,6 +5,7 @@ import (
 
 	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
 	"github.com/jenkins-x/lighthouse/pkg/launcher"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
 )
@@ -30,6 +31,11 @@ func (p *Launcher) Launch(po *v1alpha1.LighthouseJob) (*v1alpha1.LighthouseJob,
 	if p.FailJobs.Has(po.Spec.Job) {
 		return po, errors.New("failed to create job")
 	}
+	for _, existing := range p.Pipelines {
+		if po.Name != "" && existing.Name == po.Name {
+			return nil, apierrors.NewAlreadyExists(v1alpha1.Resource("lighthousejobs"), po.Name)
+		}
+	}
 	p.Pipelines = append(p.Pipelines, po)
 	po.Status.State = v1alpha1.SuccessState
 	return po, nil
@@ -6,6 +6,6 @@ import (
 
 // PipelineLauncher the interface is the service which creates Pipelines
 type PipelineLauncher interface {
-	// Launch creates new pipelines
+	// Launch creates new pipelines, returning an AlreadyExists error if a job of the same name was already launched
 	Launch(*v1alpha1.LighthouseJob) (*v1alpha1.LighthouseJob, error)
 }
@@ -6,6 +6,7 @@ import (
 	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
 	clientset "github.com/jenkins-x/lighthouse/pkg/client/clientset/versioned"
 	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -27,11 +28,22 @@ func NewLauncher(lhClient clientset.Interface, ns string) PipelineLauncher {
 // Launch creates a pipeline
 // TODO: This should be moved somewhere else, probably, and needs some kind of unit testing (apb)
 func (b *launcherImpl) Launch(request *v1alpha1.LighthouseJob) (*v1alpha1.LighthouseJob, error) {
-	appliedJob, err := b.lhClient.LighthouseV1alpha1().LighthouseJobs(b.namespace).Create(context.TODO(), request, metav1.CreateOptions{})
+	jobs := b.lhClient.LighthouseV1alpha1().LighthouseJobs(b.namespace)
+	appliedJob, err := jobs.Create(context.TODO(), request, metav1.CreateOptions{})
+	if apierrors.IsAlreadyExists(err) {
+		// a previous launch of the job may have failed to set its status, in which case it is completed here
+		if triggerErr := b.triggerExisting(request.Name); triggerErr != nil {
+			return nil, triggerErr
+		}
+	}
 	if err != nil {
 		return nil, errors.Wrap(err, "unable to apply LighthouseJob")
 	}
-	// Set status on the job
+	return b.trigger(appliedJob)
+}
+
+// trigger sets the status of the created job
+func (b *launcherImpl) trigger(appliedJob *v1alpha1.LighthouseJob) (*v1alpha1.LighthouseJob, error) {
 	appliedJob.Status = v1alpha1.LighthouseJobStatus{
 		State: v1alpha1.TriggeredState,
 	}
@@ -39,6 +51,18 @@ func (b *launcherImpl) Launch(request *v1alpha1.LighthouseJob) (*v1alpha1.Lighth
 	if err != nil {
 		return nil, errors.Wrapf(err, "unable to set status on LighthouseJob %s", appliedJob.Name)
 	}
-
 	return fullyCreatedJob, nil
 }
+
+// triggerExisting sets the status of the existing job of the given name if it has none
+func (b *launcherImpl) triggerExisting(name string) error {
+	existing, err := b.lhClient.LighthouseV1alpha1().LighthouseJobs(b.namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "unable to get existing LighthouseJob %s", name)
+	}
+	if existing.Status.State != "" {
+		return nil
+	}
+	_, err = b.trigger(existing)
+	return err
+}
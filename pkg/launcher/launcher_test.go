@@ -0,0 +1,44 @@
+package launcher
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/client/clientset/versioned/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func TestLaunchRetryAfterStatusUpdateFailure(t *testing.T) {
+	lhClient := fake.NewSimpleClientset()
+	failStatus := true
+	lhClient.PrependReactor("update", "lighthousejobs", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() == "status" && failStatus {
+			return true, nil, errors.New("status update failed")
+		}
+		return false, nil, nil
+	})
+	l := NewLauncher(lhClient, "jx")
+	newJob := func() *v1alpha1.LighthouseJob {
+		return &v1alpha1.LighthouseJob{
+			ObjectMeta: metav1.ObjectMeta{Name: "periodic-1234", Namespace: "jx"},
+			Spec:       v1alpha1.LighthouseJobSpec{Job: "periodic"},
+		}
+	}
+
+	_, err := l.Launch(newJob())
+	require.Error(t, err)
+
+	failStatus = false
+	_, err = l.Launch(newJob())
+	assert.True(t, apierrors.IsAlreadyExists(err), "expected an AlreadyExists error but got %v", err)
+	lhjob, err := lhClient.LighthouseV1alpha1().LighthouseJobs("jx").Get(context.TODO(), "periodic-1234", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, v1alpha1.TriggeredState, lhjob.Status.State)
+}
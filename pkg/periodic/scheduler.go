@@ -0,0 +1,308 @@
+// Package periodic schedules the periodic jobs, launching a LighthouseJob each time the cron
+// schedule of a periodic is due.
+//
+// Each periodic is described by a ConfigMap containing the template of its LighthouseJob, its cron
+// schedule and the time it was last scheduled, so that the schedules survive restarts and are shared
+// by all the replicas. Only the replica holding the leader election lease launches jobs.
+package periodic
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/jobutil"
+	"github.com/jenkins-x/lighthouse/pkg/launcher"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubeclient "k8s.io/client-go/kubernetes"
+	typedbatchv1 "k8s.io/client-go/kubernetes/typed/batch/v1"
+	typedv1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+)
+
+const (
+	// LabelSelector selects the ConfigMaps of the periodics
+	LabelSelector = "app=lighthouse-webhooks,component=periodic,org,repo,trigger"
+	// JobDataKey is the ConfigMap key of the LighthouseJob template
+	JobDataKey = "lighthousejob.json"
+	// CronDataKey is the ConfigMap key of the cron schedule
+	CronDataKey = "cron"
+	// LastScheduleTimeDataKey is the ConfigMap key of the time the periodic was last scheduled
+	LastScheduleTimeDataKey = "lastScheduleTime"
+
+	// LeaseName is the name of the lease used to elect the replica scheduling the periodics
+	LeaseName = "lighthouse-periodic-scheduler"
+
+	// DefaultInterval is the default interval between the checks of the schedules
+	DefaultInterval = 30 * time.Second
+
+	// missedScheduleGrace is how late a schedule can still be run with the CatchUpNone policy
+	missedScheduleGrace = 5 * time.Minute
+	// maxMissedSchedules bounds the number of missed schedules which are iterated over
+	maxMissedSchedules = 10000
+
+	scheduleLaunched = "launched"
+	scheduleFailed   = "failed"
+	scheduleSkipped  = "skipped"
+)
+
+var periodicSchedules = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "lighthouse_periodic_schedules",
+	Help: "A counter of the schedules of the periodics, by result.",
+}, []string{"result"})
+
+// CatchUpPolicy defines what happens to the schedules missed while no replica was scheduling the periodics
+type CatchUpPolicy string
+
+const (
+	// CatchUpOnce launches a single job for all the schedules missed since the periodic was last scheduled
+	CatchUpOnce CatchUpPolicy = "once"
+	// CatchUpNone skips the schedules missed by more than a few minutes
+	CatchUpNone CatchUpPolicy = "none"
+)
+
+// ParseCatchUpPolicy parses a catch-up policy, defaulting to CatchUpOnce
+func ParseCatchUpPolicy(text string) (CatchUpPolicy, error) {
+	switch CatchUpPolicy(strings.ToLower(text)) {
+	case "", CatchUpOnce:
+		return CatchUpOnce, nil
+	case CatchUpNone:
+		return CatchUpNone, nil
+	}
+	return "", errors.Errorf("invalid catch-up policy %q, it should be %s or %s", text, CatchUpOnce, CatchUpNone)
+}
+
+// Scheduler launches the LighthouseJobs of the periodics when they are due
+type Scheduler struct {
+	ConfigMaps typedv1.ConfigMapInterface
+	// CronJobs are used to migrate the periodics scheduled by the CronJobs of previous versions, if not nil
+	CronJobs typedbatchv1.CronJobInterface
+	Launcher launcher.PipelineLauncher
+	CatchUp  CatchUpPolicy
+	Interval time.Duration
+	Logger   *logrus.Entry
+
+	now func() time.Time
+}
+
+// NewScheduler creates a new scheduler of the periodics described by the ConfigMaps
+func NewScheduler(configMaps typedv1.ConfigMapInterface, cronJobs typedbatchv1.CronJobInterface, launcher launcher.PipelineLauncher, catchUp CatchUpPolicy) *Scheduler {
+	return &Scheduler{
+		ConfigMaps: configMaps,
+		CronJobs:   cronJobs,
+		Launcher:   launcher,
+		CatchUp:    catchUp,
+		Interval:   DefaultInterval,
+		Logger:     logrus.WithField("component", "periodic-scheduler"),
+		now:        time.Now,
+	}
+}
+
+// RunWithLeaderElection schedules the periodics whenever the lease is held, until the context is done
+func (s *Scheduler) RunWithLeaderElection(ctx context.Context, lock resourcelock.Interface) {
+	for ctx.Err() == nil {
+		leaderelection.RunOrDie(ctx, leaderelection.LeaderElectionConfig{
+			Lock:            lock,
+			LeaseDuration:   15 * time.Second,
+			RenewDeadline:   10 * time.Second,
+			RetryPeriod:     2 * time.Second,
+			ReleaseOnCancel: true,
+			Name:            LeaseName,
+			Callbacks: leaderelection.LeaderCallbacks{
+				OnStartedLeading: func(ctx context.Context) {
+					s.Logger.WithField("identity", lock.Identity()).Info("started leading, scheduling the periodics")
+					s.Run(ctx)
+				},
+				OnStoppedLeading: func() {
+					s.Logger.WithField("identity", lock.Identity()).Info("stopped leading")
+				},
+			},
+		})
+	}
+}
+
+// NewLeaseLock creates the lock electing the replica scheduling the periodics in the namespace
+func NewLeaseLock(kubeClient kubeclient.Interface, namespace string) (resourcelock.Interface, error) {
+	identity, err := os.Hostname()
+	if err != nil {
+		return nil, errors.Wrap(err, "getting the hostname")
+	}
+	return &resourcelock.LeaseLock{
+		LeaseMeta: metav1.ObjectMeta{
+			Name:      LeaseName,
+			Namespace: namespace,
+		},
+		Client: kubeClient.CoordinationV1(),
+		LockConfig: resourcelock.ResourceLockConfig{
+			Identity: identity,
+		},
+	}, nil
+}
+
+// Run checks the schedules of the periodics every interval until the context is done
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+	for {
+		if err := s.Sync(ctx); err != nil {
+			s.Logger.WithError(err).Error("failed to schedule the periodics")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sync launches the LighthouseJobs of the periodics which are due
+func (s *Scheduler) Sync(ctx context.Context) error {
+	cms, err := s.ConfigMaps.List(ctx, metav1.ListOptions{LabelSelector: LabelSelector})
+	if err != nil {
+		return errors.Wrap(err, "listing the periodic ConfigMaps")
+	}
+	for i := range cms.Items {
+		cm := &cms.Items[i]
+		l := s.Logger.WithFields(map[string]interface{}{
+			"org":       cm.Labels["org"],
+			"repo":      cm.Labels["repo"],
+			"periodic":  cm.Labels["trigger"],
+			"configMap": cm.Name,
+		})
+		if err := s.schedule(ctx, l, cm); err != nil {
+			l.WithError(err).Error("failed to schedule periodic")
+		}
+	}
+	return nil
+}
+
+func (s *Scheduler) schedule(ctx context.Context, l *logrus.Entry, cm *corev1.ConfigMap) error {
+	now := s.now()
+	if cm.Data[CronDataKey] == "" {
+		return s.migrateCronJob(ctx, l, cm, now)
+	}
+	schedule, err := job.ParseSchedule(cm.Data[CronDataKey])
+	if err != nil {
+		return errors.Wrapf(err, "parsing cron schedule %q", cm.Data[CronDataKey])
+	}
+	last, err := time.Parse(time.RFC3339, cm.Data[LastScheduleTimeDataKey])
+	if err != nil {
+		// the periodic has just been created so lets schedule it from now on
+		_, err = s.updateLastScheduleTime(ctx, cm, now)
+		return err
+	}
+
+	var due time.Time
+	missed := 0
+	for next := schedule.Next(last); !next.IsZero() && !next.After(now) && missed < maxMissedSchedules; next = schedule.Next(next) {
+		due = next
+		missed++
+	}
+	if due.IsZero() {
+		return nil
+	}
+	if missed > 1 {
+		l = l.WithField("missed", missed-1)
+	}
+	if s.CatchUp == CatchUpNone && now.Sub(due) > missedScheduleGrace {
+		updated, err := s.updateLastScheduleTime(ctx, cm, due)
+		if err != nil || !updated {
+			return err
+		}
+		periodicSchedules.WithLabelValues(scheduleSkipped).Inc()
+		l.WithField("schedule", due).Warn("skipping missed schedule")
+		return nil
+	}
+
+	lhjob := &v1alpha1.LighthouseJob{}
+	if err := json.Unmarshal([]byte(cm.Data[JobDataKey]), lhjob); err != nil {
+		periodicSchedules.WithLabelValues(scheduleFailed).Inc()
+		return errors.Wrapf(err, "unmarshalling the LighthouseJob template")
+	}
+	// the name of the job only depends on the periodic and the schedule so that the job is launched at most
+	// once, even if the launch is retried or another replica also thinks it is the leader
+	lhjob.GenerateName = ""
+	lhjob.Name = jobutil.DeterministicName(&lhjob.Spec, cm.Name+"@"+due.UTC().Format(time.RFC3339))
+	l = l.WithField("schedule", due).WithField("lighthouseJob", lhjob.Name)
+	_, err = s.Launcher.Launch(lhjob)
+	switch {
+	case apierrors.IsAlreadyExists(err):
+		l.Info("periodic already launched")
+	case err != nil:
+		// the schedule is only recorded once the job is launched so that the launch is retried at the next sync
+		periodicSchedules.WithLabelValues(scheduleFailed).Inc()
+		return errors.Wrapf(err, "launching the LighthouseJob scheduled at %s", due.Format(time.RFC3339))
+	default:
+		periodicSchedules.WithLabelValues(scheduleLaunched).Inc()
+		l.Info("launched periodic")
+	}
+	_, err = s.updateLastScheduleTime(ctx, cm, due)
+	return err
+}
+
+// updateLastScheduleTime records the last schedule time, returning false if the ConfigMap was modified
+// concurrently, in which case the schedule is checked again at the next sync
+func (s *Scheduler) updateLastScheduleTime(ctx context.Context, cm *corev1.ConfigMap, t time.Time) (bool, error) {
+	cm = cm.DeepCopy()
+	if cm.Data == nil {
+		cm.Data = map[string]string{}
+	}
+	cm.Data[LastScheduleTimeDataKey] = t.UTC().Format(time.RFC3339)
+	_, err := s.ConfigMaps.Update(ctx, cm, metav1.UpdateOptions{})
+	if apierrors.IsConflict(err) || apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrapf(err, "updating ConfigMap %s", cm.Name)
+	}
+	return true, nil
+}
+
+// migrateCronJob moves the schedule of the CronJob created by previous versions for the periodic to its
+// ConfigMap, then deletes the CronJob
+func (s *Scheduler) migrateCronJob(ctx context.Context, l *logrus.Entry, cm *corev1.ConfigMap, now time.Time) error {
+	if s.CronJobs == nil {
+		l.Debug("ignoring periodic without schedule")
+		return nil
+	}
+	cj, err := s.CronJobs.Get(ctx, cm.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		l.Debug("ignoring periodic without schedule")
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "getting CronJob %s", cm.Name)
+	}
+	last := now
+	if cj.Status.LastScheduleTime != nil {
+		last = cj.Status.LastScheduleTime.Time
+	}
+	cm = cm.DeepCopy()
+	if cm.Data == nil {
+		cm.Data = map[string]string{}
+	}
+	cm.Data[CronDataKey] = cj.Spec.Schedule
+	if cj.Spec.TimeZone != nil && *cj.Spec.TimeZone != "" {
+		cm.Data[CronDataKey] = "CRON_TZ=" + *cj.Spec.TimeZone + " " + cj.Spec.Schedule
+	}
+	updated, err := s.updateLastScheduleTime(ctx, cm, last)
+	if err != nil || !updated {
+		return err
+	}
+	if err := s.CronJobs.Delete(ctx, cj.Name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		return errors.Wrapf(err, "deleting CronJob %s", cj.Name)
+	}
+	l.WithField("cronJob", cj.Name).Info("migrated the schedule of the CronJob")
+	return nil
+}
@@ -0,0 +1,231 @@
+package periodic
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const namespace = "jx"
+
+type fakeLauncher struct {
+	launched []*v1alpha1.LighthouseJob
+	created  sets.String
+	fail     bool
+}
+
+func (f *fakeLauncher) Launch(lhjob *v1alpha1.LighthouseJob) (*v1alpha1.LighthouseJob, error) {
+	if f.fail {
+		return nil, errors.New("failed to create job")
+	}
+	if f.created.Has(lhjob.Name) {
+		return nil, apierrors.NewAlreadyExists(v1alpha1.Resource("lighthousejobs"), lhjob.Name)
+	}
+	f.created.Insert(lhjob.Name)
+	f.launched = append(f.launched, lhjob)
+	return lhjob, nil
+}
+
+func periodicConfigMap(name string, data map[string]string) *corev1.ConfigMap {
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels: map[string]string{
+				"app":       "lighthouse-webhooks",
+				"component": "periodic",
+				"org":       "org",
+				"repo":      "repo",
+				"trigger":   name,
+			},
+		},
+		Data: map[string]string{
+			JobDataKey: `{"metadata":{"generateName":"org-repo-"},"spec":{"type":"periodic","job":"` + name + `"}}`,
+		},
+	}
+	for k, v := range data {
+		cm.Data[k] = v
+	}
+	return cm
+}
+
+func newTestScheduler(t *testing.T, now time.Time, catchUp CatchUpPolicy, objects ...*corev1.ConfigMap) (*Scheduler, *fake.Clientset, *fakeLauncher) {
+	kubeClient := fake.NewSimpleClientset()
+	for _, cm := range objects {
+		_, err := kubeClient.CoreV1().ConfigMaps(namespace).Create(context.TODO(), cm, metav1.CreateOptions{})
+		require.NoError(t, err)
+	}
+	launcher := &fakeLauncher{created: sets.NewString()}
+	s := NewScheduler(kubeClient.CoreV1().ConfigMaps(namespace), kubeClient.BatchV1().CronJobs(namespace), launcher, catchUp)
+	s.Logger = logrus.WithField("test", t.Name())
+	s.now = func() time.Time {
+		return now
+	}
+	return s, kubeClient, launcher
+}
+
+func lastScheduleTime(t *testing.T, kubeClient *fake.Clientset, name string) string {
+	cm, err := kubeClient.CoreV1().ConfigMaps(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	require.NoError(t, err)
+	return cm.Data[LastScheduleTimeDataKey]
+}
+
+func launchedJobs(launcher *fakeLauncher) []string {
+	var jobs []string
+	for _, j := range launcher.launched {
+		jobs = append(jobs, j.Spec.Job)
+	}
+	return jobs
+}
+
+func TestSync(t *testing.T) {
+	now := time.Date(2020, 6, 1, 4, 0, 20, 0, time.UTC)
+	s, kubeClient, launcher := newTestScheduler(t, now, CatchUpOnce,
+		periodicConfigMap("due", map[string]string{
+			CronDataKey:             "0 4 * * *",
+			LastScheduleTimeDataKey: "2020-05-31T04:00:00Z",
+		}),
+		periodicConfigMap("not-due", map[string]string{
+			CronDataKey:             "0 5 * * *",
+			LastScheduleTimeDataKey: "2020-05-31T05:00:00Z",
+		}),
+		periodicConfigMap("new", map[string]string{
+			CronDataKey: "* * * * *",
+		}),
+		periodicConfigMap("timezone", map[string]string{
+			CronDataKey:             "CRON_TZ=Europe/Paris 0 6 * * *",
+			LastScheduleTimeDataKey: "2020-05-31T04:00:00Z",
+		}),
+		periodicConfigMap("missed", map[string]string{
+			CronDataKey:             "0 * * * *",
+			LastScheduleTimeDataKey: "2020-05-30T00:00:00Z",
+		}),
+		periodicConfigMap("invalid", map[string]string{
+			CronDataKey:             "not a schedule",
+			LastScheduleTimeDataKey: "2020-05-30T00:00:00Z",
+		}),
+	)
+
+	require.NoError(t, s.Sync(context.TODO()))
+	assert.ElementsMatch(t, []string{"due", "timezone", "missed"}, launchedJobs(launcher))
+	for _, j := range launcher.launched {
+		assert.Empty(t, j.GenerateName)
+		assert.Regexp(t, "^missingref[0-9a-f]{10}$", j.Name)
+	}
+
+	assert.Equal(t, "2020-06-01T04:00:00Z", lastScheduleTime(t, kubeClient, "due"))
+	assert.Equal(t, "2020-05-31T05:00:00Z", lastScheduleTime(t, kubeClient, "not-due"))
+	assert.Equal(t, "2020-06-01T04:00:20Z", lastScheduleTime(t, kubeClient, "new"), "a new periodic should be scheduled from now on")
+	assert.Equal(t, "2020-06-01T04:00:00Z", lastScheduleTime(t, kubeClient, "timezone"))
+	assert.Equal(t, "2020-06-01T04:00:00Z", lastScheduleTime(t, kubeClient, "missed"), "the missed schedules should be launched once")
+
+	// nothing is due anymore
+	launcher.launched = nil
+	require.NoError(t, s.Sync(context.TODO()))
+	assert.Empty(t, launcher.launched)
+}
+
+func TestSyncRetriesFailedLaunch(t *testing.T) {
+	now := time.Date(2020, 6, 1, 4, 0, 20, 0, time.UTC)
+	s, kubeClient, launcher := newTestScheduler(t, now, CatchUpOnce, periodicConfigMap("due", map[string]string{
+		CronDataKey:             "0 4 * * *",
+		LastScheduleTimeDataKey: "2020-05-31T04:00:00Z",
+	}))
+	failed := testutil.ToFloat64(periodicSchedules.WithLabelValues(scheduleFailed))
+
+	launcher.fail = true
+	require.NoError(t, s.Sync(context.TODO()))
+	assert.Empty(t, launcher.launched)
+	assert.Equal(t, "2020-05-31T04:00:00Z", lastScheduleTime(t, kubeClient, "due"), "the schedule should not be recorded when the launch fails")
+	assert.Equal(t, failed+1, testutil.ToFloat64(periodicSchedules.WithLabelValues(scheduleFailed)))
+
+	launcher.fail = false
+	require.NoError(t, s.Sync(context.TODO()))
+	assert.Equal(t, []string{"due"}, launchedJobs(launcher))
+	assert.Equal(t, "2020-06-01T04:00:00Z", lastScheduleTime(t, kubeClient, "due"))
+}
+
+func TestSyncLaunchesScheduleOnce(t *testing.T) {
+	now := time.Date(2020, 6, 1, 4, 0, 20, 0, time.UTC)
+	cm := periodicConfigMap("due", map[string]string{
+		CronDataKey:             "0 4 * * *",
+		LastScheduleTimeDataKey: "2020-05-31T04:00:00Z",
+	})
+	s, kubeClient, launcher := newTestScheduler(t, now, CatchUpOnce, cm)
+
+	// another replica which still thinks it is the leader schedules the periodic from a stale ConfigMap
+	require.NoError(t, s.Sync(context.TODO()))
+	require.NoError(t, s.schedule(context.TODO(), s.Logger, cm))
+	assert.Equal(t, []string{"due"}, launchedJobs(launcher))
+	assert.Equal(t, "2020-06-01T04:00:00Z", lastScheduleTime(t, kubeClient, "due"))
+}
+
+func TestSyncCatchUpNone(t *testing.T) {
+	now := time.Date(2020, 6, 1, 4, 2, 0, 0, time.UTC)
+	skipped := testutil.ToFloat64(periodicSchedules.WithLabelValues(scheduleSkipped))
+	s, kubeClient, launcher := newTestScheduler(t, now, CatchUpNone,
+		periodicConfigMap("recent", map[string]string{
+			CronDataKey:             "0 4 * * *",
+			LastScheduleTimeDataKey: "2020-05-31T04:00:00Z",
+		}),
+		periodicConfigMap("missed", map[string]string{
+			CronDataKey:             "0 3 * * *",
+			LastScheduleTimeDataKey: "2020-05-30T03:00:00Z",
+		}),
+	)
+
+	require.NoError(t, s.Sync(context.TODO()))
+	assert.Equal(t, []string{"recent"}, launchedJobs(launcher))
+	assert.Equal(t, "2020-06-01T03:00:00Z", lastScheduleTime(t, kubeClient, "missed"), "the missed schedules should be skipped")
+	assert.Equal(t, skipped+1, testutil.ToFloat64(periodicSchedules.WithLabelValues(scheduleSkipped)))
+}
+
+func TestSyncMigratesCronJob(t *testing.T) {
+	now := time.Date(2020, 6, 1, 4, 0, 20, 0, time.UTC)
+	s, kubeClient, launcher := newTestScheduler(t, now, CatchUpOnce, periodicConfigMap("legacy", nil), periodicConfigMap("orphan", nil))
+	timeZone := "Europe/Paris"
+	_, err := kubeClient.BatchV1().CronJobs(namespace).Create(context.TODO(), &batchv1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "legacy"},
+		Spec:       batchv1.CronJobSpec{Schedule: "0 6 * * *", TimeZone: &timeZone},
+		Status:     batchv1.CronJobStatus{LastScheduleTime: &metav1.Time{Time: time.Date(2020, 5, 31, 4, 0, 0, 0, time.UTC)}},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	require.NoError(t, s.Sync(context.TODO()))
+	assert.Empty(t, launcher.launched)
+	cm, err := kubeClient.CoreV1().ConfigMaps(namespace).Get(context.TODO(), "legacy", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "CRON_TZ=Europe/Paris 0 6 * * *", cm.Data[CronDataKey])
+	assert.Equal(t, "2020-05-31T04:00:00Z", cm.Data[LastScheduleTimeDataKey])
+	cjs, err := kubeClient.BatchV1().CronJobs(namespace).List(context.TODO(), metav1.ListOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, cjs.Items)
+	assert.Empty(t, lastScheduleTime(t, kubeClient, "orphan"), "a periodic without schedule should be ignored")
+
+	// the migrated periodic is now scheduled
+	require.NoError(t, s.Sync(context.TODO()))
+	assert.Equal(t, []string{"legacy"}, launchedJobs(launcher))
+}
+
+func TestParseCatchUpPolicy(t *testing.T) {
+	for text, expected := range map[string]CatchUpPolicy{"": CatchUpOnce, "once": CatchUpOnce, "None": CatchUpNone} {
+		policy, err := ParseCatchUpPolicy(text)
+		require.NoError(t, err)
+		assert.Equal(t, expected, policy, text)
+	}
+	_, err := ParseCatchUpPolicy("always")
+	assert.Error(t, err)
+}
@@ -0,0 +1,213 @@
+// Package abort implements the `/abort` and `/cancel` commands which allow
+// users to stop the pipelines running for a pull request.
+package abort
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/errorutil"
+	"github.com/jenkins-x/lighthouse/pkg/jobutil"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/jenkins-x/lighthouse/pkg/plugins/trigger"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	"github.com/jenkins-x/lighthouse/pkg/util"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const pluginName = "abort"
+
+type scmProviderClient interface {
+	BotName() (string, error)
+	CreateComment(owner, repo string, number int, pr bool, comment string) error
+	CreateStatus(org, repo, ref string, s *scm.StatusInput) (*scm.Status, error)
+	GetPullRequest(org, repo string, number int) (*scm.PullRequest, error)
+	IsCollaborator(org, repo, user string) (bool, error)
+	IsMember(org, user string) (bool, error)
+	QuoteAuthorForComment(string) string
+}
+
+type lighthouseJobClient interface {
+	List(ctx context.Context, opts metav1.ListOptions) (*v1alpha1.LighthouseJobList, error)
+	UpdateStatus(ctx context.Context, lighthouseJob *v1alpha1.LighthouseJob, opts metav1.UpdateOptions) (*v1alpha1.LighthouseJob, error)
+}
+
+var (
+	plugin = plugins.Plugin{
+		Description: "The abort plugin allows the author of a pull request and trusted users to stop the pipelines running for the pull request.",
+		Commands: []plugins.Command{{
+			Name:        "abort",
+			Description: "Aborts all the pipelines running for the PR.",
+			WhoCanUse:   "The PR author and members of the trusted organization for the repo.",
+			Action: plugins.
+				Invoke(func(_ plugins.CommandMatch, pc plugins.Agent, e scmprovider.GenericCommentEvent) error {
+					return handleGenericComment(nil, pc, e)
+				}).
+				When(plugins.Action(scm.ActionCreate), plugins.IsPR(), plugins.IssueState("open")),
+		}, {
+			Name: "cancel",
+			Arg: &plugins.CommandArg{
+				Pattern: `[-\w]+(?:,[-\w]+)*`,
+			},
+			Description: "Aborts the given pipeline(s) running for the PR.",
+			WhoCanUse:   "The PR author and members of the trusted organization for the repo.",
+			Action: plugins.
+				Invoke(func(match plugins.CommandMatch, pc plugins.Agent, e scmprovider.GenericCommentEvent) error {
+					return handleGenericComment(strings.Split(match.Arg, ","), pc, e)
+				}).
+				When(plugins.Action(scm.ActionCreate), plugins.IsPR(), plugins.IssueState("open")),
+		}},
+	}
+)
+
+func init() {
+	plugins.RegisterPlugin(pluginName, plugin)
+}
+
+func handleGenericComment(names []string, pc plugins.Agent, e scmprovider.GenericCommentEvent) error {
+	if pc.LighthouseClient == nil {
+		return fmt.Errorf("no LighthouseJob client available to abort jobs")
+	}
+	cfg := pc.PluginConfig.TriggerFor(e.Repo.Namespace, e.Repo.Name)
+	return handle(names, pc.SCMProviderClient, pc.LighthouseClient, cfg, pc.Logger, &e)
+}
+
+func handle(names []string, spc scmProviderClient, lhClient lighthouseJobClient, cfg *plugins.Trigger, log *logrus.Entry, e *scmprovider.GenericCommentEvent) error {
+	org := e.Repo.Namespace
+	repo := e.Repo.Name
+	number := e.Number
+	user := e.Author.Login
+
+	respond := func(resp string) error {
+		return spc.CreateComment(org, repo, number, e.IsPR, plugins.FormatResponseRaw(e.Body, e.Link, spc.QuoteAuthorForComment(user), resp))
+	}
+
+	pr, err := spc.GetPullRequest(org, repo, number)
+	if err != nil {
+		resp := fmt.Sprintf("Cannot get PR #%d in %s/%s: %v", number, org, repo, err)
+		log.Warn(resp)
+		return respond(resp)
+	}
+
+	if pr.Author.Login != user {
+		trusted, err := trigger.TrustedUser(spc, cfg, user, org, repo)
+		if err != nil {
+			return fmt.Errorf("could not check membership of %s: %w", user, err)
+		}
+		if !trusted {
+			resp := fmt.Sprintf("%s unauthorized: only the PR author and trusted users can abort pipelines", user)
+			log.Debug(resp)
+			return respond(resp)
+		}
+	}
+
+	// the repository label may be sanitized or dropped so the jobs of the repository are matched on their refs
+	selector := fmt.Sprintf("%s=%s,%s=%d", util.OrgLabel, strings.ToLower(org), util.PullLabel, number)
+	jobs, err := lhClient.List(context.TODO(), metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return fmt.Errorf("failed to list LighthouseJobs for %s/%s#%d: %w", org, repo, number, err)
+	}
+
+	requested := sets.NewString(names...)
+	matched := sets.NewString()
+	aborted := sets.NewString()
+	var errors []error
+	for i := range jobs.Items {
+		lhjob := &jobs.Items[i]
+		if !isActive(lhjob) || lhjob.Spec.Refs == nil || lhjob.Spec.Refs.Repo != repo {
+			continue
+		}
+		if requested.Len() > 0 {
+			name := matchingName(lhjob, requested)
+			if name == "" {
+				continue
+			}
+			matched.Insert(name)
+		}
+		if err := abortJob(spc, lhClient, lhjob, user, log); err != nil {
+			errors = append(errors, err)
+			continue
+		}
+		aborted.Insert(lhjob.Spec.Context)
+	}
+
+	var resp string
+	switch {
+	case requested.Len() > 0 && matched.Len() < requested.Len():
+		resp = fmt.Sprintf("No running pipelines found for: %s", formatList(requested.Difference(matched).List()))
+		if aborted.Len() > 0 {
+			resp = fmt.Sprintf("Aborted: %s\n\n%s", formatList(aborted.List()), resp)
+		}
+	case aborted.Len() > 0:
+		resp = fmt.Sprintf("Aborted: %s", formatList(aborted.List()))
+	case len(errors) == 0:
+		resp = "There are no running pipelines to abort."
+	}
+	if resp != "" {
+		errors = append(errors, respond(resp))
+	}
+	return errorutil.NewAggregate(errors...)
+}
+
+// isActive returns true if the job is a presubmit which has not finished yet
+func isActive(lhjob *v1alpha1.LighthouseJob) bool {
+	if lhjob.Complete() || v1alpha1.IsTerminalPipelineState(lhjob.Status.State) {
+		return false
+	}
+	return lhjob.Spec.Type == job.PresubmitJob
+}
+
+// matchingName returns the requested name matching the job name or context, if any
+func matchingName(lhjob *v1alpha1.LighthouseJob, requested sets.String) string {
+	for _, name := range []string{lhjob.Spec.Job, lhjob.Spec.Context} {
+		if requested.Has(name) {
+			return name
+		}
+	}
+	return ""
+}
+
+// abortJob moves the job to the aborted state, the engines then cancel the underlying pipeline,
+// and reports its commit status as canceled
+func abortJob(spc scmProviderClient, lhClient lighthouseJobClient, lhjob *v1alpha1.LighthouseJob, user string, log *logrus.Entry) error {
+	log.WithFields(jobutil.LighthouseJobFields(lhjob)).
+		WithField("from", lhjob.Status.State).
+		WithField("to", v1alpha1.AbortedState).
+		Infof("Aborting LighthouseJob on behalf of %s.", user)
+	lhjob.Status.State = v1alpha1.AbortedState
+	lhjob.Status.Description = fmt.Sprintf("Aborted by %s.", user)
+	if _, err := lhClient.UpdateStatus(context.TODO(), lhjob, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to abort LighthouseJob %s: %w", lhjob.Name, err)
+	}
+
+	refs := lhjob.Spec.Refs
+	if refs == nil || len(refs.Pulls) == 0 {
+		return nil
+	}
+	status := &scm.StatusInput{
+		State:  scm.StateCanceled,
+		Label:  lhjob.Spec.Context,
+		Desc:   lhjob.Status.Description,
+		Target: lhjob.Status.ReportURL,
+	}
+	if _, err := spc.CreateStatus(refs.Org, refs.Repo, refs.Pulls[0].SHA, status); err != nil {
+		return fmt.Errorf("failed to report canceled status for %s: %w", lhjob.Spec.Context, err)
+	}
+	return nil
+}
+
+func formatList(list []string) string {
+	sort.Strings(list)
+	var items []string
+	for _, item := range list {
+		items = append(items, fmt.Sprintf("`%s`", item))
+	}
+	return strings.Join(items, ", ")
+}
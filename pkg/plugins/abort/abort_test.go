@@ -0,0 +1,176 @@
+package abort
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	lhfake "github.com/jenkins-x/lighthouse/pkg/client/clientset/versioned/fake"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/jobutil"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider/fake"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestHandle(t *testing.T) {
+	newJob := func(name, context string, state v1alpha1.PipelineState) *v1alpha1.LighthouseJob {
+		spec := v1alpha1.LighthouseJobSpec{
+			Type:    job.PresubmitJob,
+			Job:     context,
+			Context: context,
+			Refs: &v1alpha1.Refs{
+				Org:     "org",
+				Repo:    "repo",
+				BaseRef: "master",
+				Pulls: []v1alpha1.Pull{{
+					Number: 5,
+					SHA:    "head",
+				}},
+			},
+		}
+		lhjob := jobutil.NewLighthouseJob(spec, nil, nil)
+		lhjob.Name = name
+		lhjob.Namespace = "jx"
+		lhjob.Status.State = state
+		return &lhjob
+	}
+
+	testCases := []struct {
+		name             string
+		user             string
+		names            []string
+		expectedAborted  []string
+		expectedStatuses []string
+		expectedComment  string
+	}{
+		{
+			name:             "author aborts all running pipelines",
+			user:             "author",
+			expectedAborted:  []string{"build", "lint"},
+			expectedStatuses: []string{"build", "lint"},
+			expectedComment:  "Aborted: `build`, `lint`",
+		},
+		{
+			name:             "trusted user cancels a single pipeline",
+			user:             "member",
+			names:            []string{"lint"},
+			expectedAborted:  []string{"lint"},
+			expectedStatuses: []string{"lint"},
+			expectedComment:  "Aborted: `lint`",
+		},
+		{
+			name:            "unknown pipeline",
+			user:            "author",
+			names:           []string{"e2e"},
+			expectedComment: "No running pipelines found for: `e2e`",
+		},
+		{
+			name:            "untrusted user",
+			user:            "random",
+			expectedComment: "random unauthorized: only the PR author and trusted users can abort pipelines",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			lhClient := lhfake.NewSimpleClientset(
+				newJob("build", "build", v1alpha1.RunningState),
+				newJob("lint", "lint", v1alpha1.TriggeredState),
+				newJob("done", "unit", v1alpha1.SuccessState),
+			)
+			jobClient := lhClient.LighthouseV1alpha1().LighthouseJobs("jx")
+			spc := &fake.SCMClient{
+				OrgMembers:          map[string][]string{"org": {"member"}},
+				PullRequestComments: map[int][]*scm.Comment{},
+				PullRequests: map[int]*scm.PullRequest{
+					5: {
+						Number: 5,
+						Author: scm.User{Login: "author"},
+						Head:   scm.PullRequestBranch{Sha: "head"},
+					},
+				},
+			}
+			e := &scmprovider.GenericCommentEvent{
+				Repo:   scm.Repository{Namespace: "org", Name: "repo"},
+				Number: 5,
+				IsPR:   true,
+				Author: scm.User{Login: tc.user},
+				Body:   "/abort",
+			}
+
+			err := handle(tc.names, spc, jobClient, &plugins.Trigger{}, logrus.WithField("plugin", pluginName), e)
+			require.NoError(t, err)
+
+			jobs, err := jobClient.List(context.TODO(), metav1.ListOptions{})
+			require.NoError(t, err)
+			var aborted []string
+			for _, j := range jobs.Items {
+				if j.Status.State == v1alpha1.AbortedState {
+					aborted = append(aborted, j.Name)
+				}
+			}
+			assert.ElementsMatch(t, tc.expectedAborted, aborted)
+
+			var statuses []string
+			for _, s := range spc.CreatedStatuses["head"] {
+				assert.Equal(t, scm.StateCanceled, s.State)
+				statuses = append(statuses, s.Label)
+			}
+			assert.ElementsMatch(t, tc.expectedStatuses, statuses)
+
+			require.Len(t, spc.PullRequestCommentsAdded, 1)
+			assert.Contains(t, spc.PullRequestCommentsAdded[0], tc.expectedComment)
+		})
+	}
+}
+
+func TestHandleNestedRepository(t *testing.T) {
+	// the repository label of the GitLab nested repositories is sanitized
+	t.Setenv("GIT_KIND", "gitlab")
+	newJob := func(name, repo string) *v1alpha1.LighthouseJob {
+		spec := v1alpha1.LighthouseJobSpec{
+			Type:    job.PresubmitJob,
+			Job:     "build",
+			Context: "build",
+			Refs: &v1alpha1.Refs{
+				Org:   "org",
+				Repo:  repo,
+				Pulls: []v1alpha1.Pull{{Number: 5, SHA: "head"}},
+			},
+		}
+		lhjob := jobutil.NewLighthouseJob(spec, nil, nil)
+		lhjob.Name = name
+		lhjob.Namespace = "jx"
+		lhjob.Status.State = v1alpha1.RunningState
+		return &lhjob
+	}
+	lhClient := lhfake.NewSimpleClientset(newJob("nested", "group/repo"), newJob("other-repo", "repo"))
+	jobClient := lhClient.LighthouseV1alpha1().LighthouseJobs("jx")
+	spc := &fake.SCMClient{
+		PullRequestComments: map[int][]*scm.Comment{},
+		PullRequests: map[int]*scm.PullRequest{
+			5: {Number: 5, Author: scm.User{Login: "author"}, Head: scm.PullRequestBranch{Sha: "head"}},
+		},
+	}
+	e := &scmprovider.GenericCommentEvent{
+		Repo:   scm.Repository{Namespace: "org", Name: "group/repo"},
+		Number: 5,
+		IsPR:   true,
+		Author: scm.User{Login: "author"},
+		Body:   "/abort",
+	}
+	require.NoError(t, handle(nil, spc, jobClient, &plugins.Trigger{}, logrus.WithField("plugin", pluginName), e))
+
+	jobs, err := jobClient.List(context.TODO(), metav1.ListOptions{})
+	require.NoError(t, err)
+	states := map[string]v1alpha1.PipelineState{}
+	for _, j := range jobs.Items {
+		states[j.Name] = j.Status.State
+	}
+	assert.Equal(t, map[string]v1alpha1.PipelineState{"nested": v1alpha1.AbortedState, "other-repo": v1alpha1.RunningState}, states)
+}
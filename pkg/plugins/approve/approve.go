@@ -145,6 +145,9 @@ func configHelp(config *plugins.Configuration, enabledRepos []string) (map[strin
 			return nil, fmt.Errorf("invalid repo in enabledRepos: %q", repo)
 		}
 		approveConfig[repo] = fmt.Sprintf("Pull requests %s require an associated issue.<br>Pull request authors %s implicitly approve their own PRs.<br>The /lgtm [cancel] command(s) %s act as approval.<br>A GitHub approved or changes requested review %s act as approval or cancel respectively.", doNot(opts.IssueRequired), doNot(opts.HasSelfApproval()), willNot(opts.LgtmActsAsApprove), willNot(opts.ConsiderReviewState()))
+		if opts.RequiredDistinctApprovers > 0 {
+			approveConfig[repo] += fmt.Sprintf("<br>Pull requests require %d distinct approvers from different OWNERS files.", opts.RequiredDistinctApprovers)
+		}
 	}
 	return approveConfig, nil
 }
@@ -427,6 +430,7 @@ func handle(log *logrus.Entry, spc scmProviderClient, repo approvers.Repo, baseU
 		log.WithError(err).Errorf("Failed to find associated issue from PR body: %v", err)
 	}
 	approversHandler.RequireIssue = opts.IssueRequired
+	approversHandler.RequiredDistinctApprovers = opts.RequiredDistinctApprovers
 	approversHandler.ManuallyApproved = humanAddedApproved(spc, log, pr.org, pr.repo, pr.number, botName, hasApprovedLabel)
 
 	// Author implicitly approves their own PR if config allows it
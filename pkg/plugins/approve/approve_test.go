@@ -1150,7 +1150,7 @@ The full list of commands accepted by this bot can be found [here](https://jayex
 <details open>
 Needs approval from an approver in each of these files:
 
-- **[d/OWNERS](https://github.com/org/repo/blob/master/d/OWNERS)**
+- **[d/OWNERS](https://github.com/org/repo/blob/master/d/OWNERS)** (2 more approval(s) needed)
 
 Approvers can indicate their approval by writing ` + "`/approve`" + ` in a comment
 Approvers can cancel approval by writing ` + "`/approve cancel`" + ` in a comment
@@ -1186,7 +1186,7 @@ The full list of commands accepted by this bot can be found [here](https://jayex
 <details open>
 Needs approval from an approver in each of these files:
 
-- ~~[d/OWNERS](https://github.com/org/repo/blob/master/d/OWNERS)~~ [derek]
+- **[d/OWNERS](https://github.com/org/repo/blob/master/d/OWNERS)** [derek] (1 more approval(s) needed)
 
 Approvers can indicate their approval by writing ` + "`/approve`" + ` in a comment
 Approvers can cancel approval by writing ` + "`/approve cancel`" + ` in a comment
@@ -1259,7 +1259,7 @@ The full list of commands accepted by this bot can be found [here](https://jayex
 <details open>
 Needs approval from an approver in each of these files:
 
-- ~~[d/OWNERS](https://github.com/org/repo/blob/master/d/OWNERS)~~ [derek]
+- **[d/OWNERS](https://github.com/org/repo/blob/master/d/OWNERS)** [derek] (1 more approval(s) needed)
 
 Approvers can indicate their approval by writing ` + "`/approve`" + ` in a comment
 Approvers can cancel approval by writing ` + "`/approve cancel`" + ` in a comment
@@ -794,6 +794,118 @@ func TestIsApprovedWithMinReviewers(t *testing.T) {
 	}
 }
 
+func TestIsApprovedWithDistinctApprovers(t *testing.T) {
+	rootApprovers := sets.NewString("Alice", "Bob")
+	aApprovers := sets.NewString("Alice", "Anne")
+	bApprovers := sets.NewString("Bill")
+	FakeRepoMap := map[string]sets.String{
+		"":    rootApprovers,
+		"a":   aApprovers,
+		"a/b": bApprovers,
+		"c":   sets.NewString("Carol"),
+	}
+	tests := []struct {
+		testName          string
+		filenames         []string
+		noParentOwnersMap map[string]bool
+		required          int
+		currentlyApproved sets.String
+		distinct          int
+		remaining         int
+		isApproved        bool
+	}{
+		{
+			testName:          "disabled",
+			filenames:         []string{"a/b/test.go"},
+			currentlyApproved: sets.NewString("Bill"),
+			distinct:          1,
+			isApproved:        true,
+		},
+		{
+			testName:          "single approver can only count once",
+			filenames:         []string{"a/b/test.go"},
+			required:          2,
+			currentlyApproved: sets.NewString("Alice"),
+			distinct:          1,
+			remaining:         1,
+			isApproved:        false,
+		},
+		{
+			testName:          "approvers from the leaf and a parent OWNERS file",
+			filenames:         []string{"a/b/test.go"},
+			required:          2,
+			currentlyApproved: sets.NewString("Bill", "Alice"),
+			distinct:          2,
+			isApproved:        true,
+		},
+		{
+			testName:          "approvers matched to different OWNERS files",
+			filenames:         []string{"a/b/test.go"},
+			required:          3,
+			currentlyApproved: sets.NewString("Alice", "Anne", "Bill"),
+			distinct:          3,
+			isApproved:        true,
+		},
+		{
+			testName:          "two approvers listed in the same OWNERS files only",
+			filenames:         []string{"a/test.go"},
+			required:          2,
+			currentlyApproved: sets.NewString("Anne", "Bill"),
+			distinct:          1,
+			remaining:         1,
+			isApproved:        false,
+		},
+		{
+			testName:          "required approvers capped to the number of OWNERS files",
+			filenames:         []string{"c/test.go"},
+			noParentOwnersMap: map[string]bool{"c": true},
+			required:          3,
+			currentlyApproved: sets.NewString("Carol"),
+			distinct:          1,
+			isApproved:        true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			fakeRepo := createFakeRepo(FakeRepoMap)
+			setNoParent(fakeRepo, test.noParentOwnersMap)
+			testApprovers := NewApprovers(Owners{filenames: test.filenames, repo: fakeRepo, seed: 0, log: logrus.WithField("plugin", "some_plugin")})
+			testApprovers.RequiredDistinctApprovers = test.required
+			for approver := range test.currentlyApproved {
+				testApprovers.AddApprover(approver, "REFERENCE", false)
+			}
+			assert.Equal(t, test.distinct, testApprovers.GetDistinctApprovers())
+			assert.Equal(t, test.remaining, testApprovers.GetRemainingDistinctApprovers())
+			assert.Equal(t, test.isApproved, testApprovers.IsApproved())
+		})
+	}
+}
+
+func TestGetFilesWithMinReviewers(t *testing.T) {
+	fakeRepo := createFakeRepo(map[string]sets.String{
+		"a": sets.NewString("Alice", "Anne", "Art"),
+		"b": sets.NewString("Bill", "Ben"),
+		"c": sets.NewString("Carol"),
+	})
+	setMinReviewers(fakeRepo, map[string]int{"a": 3, "b": 2})
+	testApprovers := NewApprovers(Owners{filenames: []string{"a/test.go", "b/test.go", "c/test.go"}, repo: fakeRepo, seed: 0, log: logrus.WithField("plugin", "some_plugin")})
+	testApprovers.AddApprover("Alice", "REFERENCE", false)
+	testApprovers.AddApprover("Bill", "REFERENCE", false)
+	testApprovers.AddApprover("Ben", "REFERENCE", false)
+
+	var files []string
+	for _, f := range testApprovers.GetFiles(&url.URL{Scheme: "https", Host: "github.com"}, "org", "repo", "master", "github") {
+		files = append(files, f.String())
+	}
+	assert.Equal(t, []string{
+		"- **[a/OWNERS](https://github.com/org/repo/blob/master/a/OWNERS)** [Alice] (2 more approval(s) needed)\n",
+		"- ~~[b/OWNERS](https://github.com/org/repo/blob/master/b/OWNERS)~~ [Ben,Bill]\n",
+		"- **[c/OWNERS](https://github.com/org/repo/blob/master/c/OWNERS)**\n",
+	}, files)
+	assert.Equal(t, 3, testApprovers.GetRemainingRequiredApprovers())
+}
+
 func TestIsApprovedWithIssue(t *testing.T) {
 	aApprovers := sets.NewString("Author", "Anne", "Carl")
 	bApprovers := sets.NewString("Bill", "Carl")
@@ -202,6 +202,42 @@ func (o Owners) GetOwnersSet() sets.String {
 	return owners
 }
 
+// GetApproverOwnersChain returns the OWNERS files with approvers for the changed files
+// together with their parent OWNERS files, up to the root or an OWNERS file with no parent owners.
+func (o Owners) GetApproverOwnersChain() sets.String {
+	chain := sets.NewString()
+	for _, fn := range o.filenames {
+		dir := o.repo.FindApproverOwnersForFile(fn)
+		for !chain.Has(dir) {
+			chain.Insert(dir)
+			if dir == "" || o.repo.IsNoParentOwners(dir) {
+				break
+			}
+			dir = o.repo.FindApproverOwnersForFile(filepath.Join(filepath.Dir(dir), ownersFileName))
+		}
+	}
+	return chain
+}
+
+// GetRequiredApprovals returns a map from ownersFiles -> the number of approvals required,
+// which is the highest minimum_reviewers of the changed files they own (1 by default).
+func (o Owners) GetRequiredApprovals() map[string]int {
+	required := map[string]int{}
+	for fn := range o.GetOwnersSet() {
+		required[fn] = 1
+	}
+	for _, fn := range o.filenames {
+		dir := o.repo.FindApproverOwnersForFile(fn)
+		if _, ok := required[dir]; !ok {
+			continue
+		}
+		if minReviewers := o.repo.MinimumReviewersForFile(fn); minReviewers > required[dir] {
+			required[dir] = minReviewers
+		}
+	}
+	return required
+}
+
 // GetShuffledApprovers shuffles the potential approvers so that we don't
 // always suggest the same people.
 func (o Owners) GetShuffledApprovers() []string {
@@ -271,6 +307,9 @@ type Approvers struct {
 	assignees       sets.String
 	AssociatedIssue int
 	RequireIssue    bool
+	// RequiredDistinctApprovers is the number of distinct approvers, each listed in a different
+	// OWNERS file of the changes or their parents, required to approve the PR. 0 disables it.
+	RequiredDistinctApprovers int
 
 	ManuallyApproved func() bool
 }
@@ -448,23 +487,9 @@ func (ap Approvers) NoIssueApprovers() map[string]Approval {
 // UnapprovedFiles returns owners files that still need approval
 func (ap Approvers) UnapprovedFiles() sets.String {
 	unapproved := sets.NewString()
-
-	// Build a map of OWNERS directory -> max minimum_reviewers for files in that directory
-	dirMinReviewers := map[string]int{}
-	for _, fn := range ap.owners.filenames {
-		dir := ap.owners.repo.FindApproverOwnersForFile(fn)
-		minReviewers := ap.owners.repo.MinimumReviewersForFile(fn)
-		if minReviewers > dirMinReviewers[dir] {
-			dirMinReviewers[dir] = minReviewers
-		}
-	}
-
+	required := ap.owners.GetRequiredApprovals()
 	for fn, approvers := range ap.GetFilesApprovers() {
-		minRequired := dirMinReviewers[fn]
-		if minRequired == 0 {
-			minRequired = 1 // default
-		}
-		if len(approvers) < minRequired {
+		if len(approvers) < required[fn] {
 			unapproved.Insert(fn)
 		}
 	}
@@ -475,38 +500,95 @@ func (ap Approvers) UnapprovedFiles() sets.String {
 // It computes the deficit for each OWNERS directory and returns the sum of all deficits.
 // This ensures that when multiple directories each require multiple approvals, the total
 // remaining count reflects all outstanding approval requirements.
+// When distinct approvers are required and more of them are missing, that number is returned instead.
 func (ap Approvers) GetRemainingRequiredApprovers() int {
-	// Build a map of OWNERS directory -> max minimum_reviewers for files in that directory
-	dirMinReviewers := map[string]int{}
-	for _, fn := range ap.owners.filenames {
-		dir := ap.owners.repo.FindApproverOwnersForFile(fn)
-		minReviewers := ap.owners.repo.MinimumReviewersForFile(fn)
-		if minReviewers > dirMinReviewers[dir] {
-			dirMinReviewers[dir] = minReviewers
-		}
-	}
+	required := ap.owners.GetRequiredApprovals()
 
 	// Sum the deficits across all directories
 	totalRemaining := 0
 	for dir, approvers := range ap.GetFilesApprovers() {
-		minRequired := dirMinReviewers[dir]
-		if minRequired == 0 {
-			minRequired = 1 // default
-		}
-		deficit := minRequired - len(approvers)
+		deficit := required[dir] - len(approvers)
 		if deficit > 0 {
 			totalRemaining += deficit
 		}
 	}
+	if distinctRemaining := ap.GetRemainingDistinctApprovers(); distinctRemaining > totalRemaining {
+		return distinctRemaining
+	}
 	return totalRemaining
 }
 
+// GetRequiredDistinctApprovers returns the number of distinct approvers required to approve the PR.
+// It is capped to the number of OWNERS files with approvers for the changes and their parents so
+// that a PR can always be approved.
+func (ap Approvers) GetRequiredDistinctApprovers() int {
+	if ap.RequiredDistinctApprovers <= 0 {
+		return 0
+	}
+	ownersFiles := 0
+	for fn := range ap.owners.GetApproverOwnersChain() {
+		if ap.owners.repo.LeafApprovers(fn).Len() > 0 {
+			ownersFiles++
+		}
+	}
+	if ownersFiles < ap.RequiredDistinctApprovers {
+		return ownersFiles
+	}
+	return ap.RequiredDistinctApprovers
+}
+
+// GetDistinctApprovers returns the number of current approvers which can each be matched
+// with a different OWNERS file of the changes or their parents.
+func (ap Approvers) GetDistinctApprovers() int {
+	currentApprovers := ap.GetCurrentApproversSet()
+	ownersFiles := ap.owners.GetApproverOwnersChain().List()
+	candidates := map[string][]string{}
+	for _, fn := range ownersFiles {
+		candidates[fn] = IntersectSetsCase(currentApprovers, ap.owners.repo.LeafApprovers(fn)).List()
+	}
+
+	// maximum bipartite matching between approvers and OWNERS files using augmenting paths
+	matches := map[string]string{}
+	var match func(fn string, visited sets.String) bool
+	match = func(fn string, visited sets.String) bool {
+		for _, approver := range candidates[fn] {
+			if visited.Has(approver) {
+				continue
+			}
+			visited.Insert(approver)
+			if other, ok := matches[approver]; !ok || match(other, visited) {
+				matches[approver] = fn
+				return true
+			}
+		}
+		return false
+	}
+	for _, fn := range ownersFiles {
+		match(fn, sets.NewString())
+	}
+	return len(matches)
+}
+
+// GetRemainingDistinctApprovers returns the number of additional distinct approvers needed.
+func (ap Approvers) GetRemainingDistinctApprovers() int {
+	required := ap.GetRequiredDistinctApprovers()
+	if required == 0 {
+		return 0
+	}
+	if remaining := required - ap.GetDistinctApprovers(); remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
 // GetFiles returns owners files that still need approval.
 func (ap Approvers) GetFiles(baseURL *url.URL, owner, repo, branch, providerType string) []File {
 	allOwnersFiles := []File{}
 	filesApprovers := ap.GetFilesApprovers()
+	required := ap.owners.GetRequiredApprovals()
 	for _, file := range ap.owners.GetOwnersSet().List() {
-		if len(filesApprovers[file]) == 0 {
+		switch {
+		case len(filesApprovers[file]) == 0 && required[file] <= 1:
 			allOwnersFiles = append(allOwnersFiles, UnapprovedFile{
 				baseURL:      baseURL,
 				owner:        owner,
@@ -515,7 +597,18 @@ func (ap Approvers) GetFiles(baseURL *url.URL, owner, repo, branch, providerType
 				branch:       branch,
 				providerType: providerType,
 			})
-		} else {
+		case len(filesApprovers[file]) < required[file]:
+			allOwnersFiles = append(allOwnersFiles, PartiallyApprovedFile{
+				baseURL:      baseURL,
+				owner:        owner,
+				repo:         repo,
+				filepath:     file,
+				approvers:    filesApprovers[file],
+				remaining:    required[file] - len(filesApprovers[file]),
+				branch:       branch,
+				providerType: providerType,
+			})
+		default:
 			allOwnersFiles = append(allOwnersFiles, ApprovedFile{
 				baseURL:      baseURL,
 				owner:        owner,
@@ -571,12 +664,13 @@ func (ap Approvers) AreFilesApproved() bool {
 
 // RequirementsMet returns a bool indicating whether the PR has met all approval requirements:
 // - all OWNERS files associated with the PR have been approved AND
+// - enough distinct approvers from different OWNERS files approved the PR, when required AND
 // EITHER
 //   - the munger config is such that an issue is not required to be associated with the PR
 //   - that there is an associated issue with the PR
 //   - an OWNER has indicated that the PR is trivial enough that an issue need not be associated with the PR
 func (ap Approvers) RequirementsMet() bool {
-	return ap.AreFilesApproved() && (!ap.RequireIssue || ap.AssociatedIssue != 0 || len(ap.NoIssueApprovers()) != 0)
+	return ap.AreFilesApproved() && ap.GetRemainingDistinctApprovers() == 0 && (!ap.RequireIssue || ap.AssociatedIssue != 0 || len(ap.NoIssueApprovers()) != 0)
 }
 
 // IsApproved returns a bool indicating whether the PR is fully approved.
@@ -638,6 +732,20 @@ type UnapprovedFile struct {
 	providerType string
 }
 
+// PartiallyApprovedFile contains the information of a file which requires more approvals.
+type PartiallyApprovedFile struct {
+	baseURL  *url.URL
+	owner    string
+	repo     string
+	filepath string
+	// approvers is the set of users that approved this file change so far.
+	approvers sets.String
+	// remaining is the number of approvals still required for this file change.
+	remaining    int
+	branch       string
+	providerType string
+}
+
 func (a ApprovedFile) String() string {
 	fullOwnersPath := filepath.Join(a.filepath, ownersFileName)
 	if strings.HasSuffix(a.filepath, ".md") {
@@ -657,6 +765,18 @@ func (ua UnapprovedFile) String() string {
 	return fmt.Sprintf("- **[%s](%s)**\n", fullOwnersPath, link)
 }
 
+func (pa PartiallyApprovedFile) String() string {
+	fullOwnersPath := filepath.Join(pa.filepath, ownersFileName)
+	if strings.HasSuffix(pa.filepath, ".md") {
+		fullOwnersPath = pa.filepath
+	}
+	link := util.BlobURLForProvider(pa.providerType, pa.baseURL, pa.owner, pa.repo, pa.branch, fullOwnersPath)
+	if len(pa.approvers) == 0 {
+		return fmt.Sprintf("- **[%s](%s)** (%d more approval(s) needed)\n", fullOwnersPath, link, pa.remaining)
+	}
+	return fmt.Sprintf("- **[%s](%s)** [%v] (%d more approval(s) needed)\n", fullOwnersPath, link, strings.Join(pa.approvers.List(), ","), pa.remaining)
+}
+
 // GenerateTemplate takes a template, name and data, and generates
 // the corresponding string.
 func GenerateTemplate(templ, name string, data interface{}) (string, error) {
@@ -705,6 +825,9 @@ This pull-request has been approved by:{{range $index, $approval := .ap.ListAppr
 {{- if (and (gt .ap.GetRemainingRequiredApprovers 0) (not (call .ap.ManuallyApproved))) }}
 The changes made require {{ .ap.GetRemainingRequiredApprovers }} more approval(s).
 {{- end }}
+{{- if (and (gt .ap.GetRemainingDistinctApprovers 0) (not (call .ap.ManuallyApproved))) }}
+Approval requires {{ .ap.GetRequiredDistinctApprovers }} distinct approvers from different OWNERS files, {{ .ap.GetRemainingDistinctApprovers }} more needed.
+{{- end }}
 
 {{- if (and (not .ap.AreFilesApproved) (not (call .ap.ManuallyApproved))) }}  
 To complete the [pull request process](https://git.k8s.io/community/contributors/guide/owners.md#the-code-review-process), please assign {{range $index, $cc := .ap.GetCCs}}{{if $index}}, {{end}}**{{$cc}}**{{end}}  
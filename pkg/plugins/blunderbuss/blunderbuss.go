@@ -0,0 +1,312 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package blunderbuss contains a plugin which requests reviews of new pull
+// requests from reviewers listed in the OWNERS files of the changed files.
+package blunderbuss
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/jenkins-x/lighthouse/pkg/repoowners"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	githubql "github.com/shurcooL/githubv4"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const (
+	pluginName = "blunderbuss"
+
+	defaultReviewerCount = 2
+)
+
+var (
+	plugin = plugins.Plugin{
+		Description:        "The blunderbuss plugin automatically requests reviews from reviewers when a new PR is opened or marked ready for review. The reviewers are selected from the OWNERS files of the changed files, weighted by the number of changed lines.",
+		ConfigHelpProvider: configHelp,
+		PullRequestHandler: handlePullRequest,
+	}
+)
+
+func init() {
+	plugins.RegisterPlugin(pluginName, plugin)
+}
+
+func configHelp(config *plugins.Configuration, enabledRepos []string) (map[string]string, error) {
+	configInfo := map[string]string{}
+	for _, orgRepo := range enabledRepos {
+		parts := strings.Split(orgRepo, "/")
+		var opts *plugins.Blunderbuss
+		switch len(parts) {
+		case 1:
+			opts = optionsForRepo(config, orgRepo, "")
+		case 2:
+			opts = optionsForRepo(config, parts[0], parts[1])
+		default:
+			return nil, fmt.Errorf("invalid repo in enabledRepos: %q", orgRepo)
+		}
+		var pieces []string
+		pieces = append(pieces, fmt.Sprintf("Blunderbuss is currently configured to request reviews from %d reviewers.", reviewerCount(opts)))
+		if opts.MaxReviewerCount > 0 {
+			pieces = append(pieces, fmt.Sprintf("At most %d reviewers are requested.", opts.MaxReviewerCount))
+		}
+		if opts.ExcludeApprovers {
+			pieces = append(pieces, "Approvers are never requested as reviewers.")
+		}
+		if opts.UseStatusAvailability {
+			pieces = append(pieces, "Reviewers whose status is busy are skipped.")
+		}
+		configInfo[orgRepo] = strings.Join(pieces, " ")
+	}
+	return configInfo, nil
+}
+
+// optionsForRepo gets the plugins.Blunderbuss struct that is applicable to the indicated repo.
+func optionsForRepo(config *plugins.Configuration, org, repo string) *plugins.Blunderbuss {
+	fullName := fmt.Sprintf("%s/%s", org, repo)
+	for _, c := range config.Blunderbuss {
+		if !sets.NewString(c.Repos...).Has(fullName) {
+			continue
+		}
+		return &c
+	}
+	// If you don't find anything, loop again looking for an org config
+	for _, c := range config.Blunderbuss {
+		if !sets.NewString(c.Repos...).Has(org) {
+			continue
+		}
+		return &c
+	}
+	return &plugins.Blunderbuss{}
+}
+
+func reviewerCount(opts *plugins.Blunderbuss) int {
+	if opts.ReviewerCount == nil {
+		return defaultReviewerCount
+	}
+	return *opts.ReviewerCount
+}
+
+type ownersClient interface {
+	LoadRepoOwners(org, repo, base string) (repoowners.RepoOwner, error)
+}
+
+type scmProviderClient interface {
+	RequestReview(org, repo string, number int, logins []string) error
+	GetPullRequestChanges(org, repo string, number int) ([]*scm.Change, error)
+	SupportsGraphQL() bool
+	Query(ctx context.Context, q interface{}, vars map[string]interface{}) error
+}
+
+// reviewersClient is the subset of repoowners.RepoOwner used to select reviewers
+type reviewersClient interface {
+	LeafReviewers(path string) sets.String
+	Reviewers(path string) sets.String
+	RequiredReviewers(path string) sets.String
+}
+
+// fallbackReviewersClient selects the approvers as reviewers
+type fallbackReviewersClient struct {
+	ownersClient repoowners.RepoOwner
+}
+
+func (foc fallbackReviewersClient) LeafReviewers(path string) sets.String {
+	return foc.ownersClient.LeafApprovers(path)
+}
+
+func (foc fallbackReviewersClient) Reviewers(path string) sets.String {
+	return foc.ownersClient.Approvers(path)
+}
+
+func (foc fallbackReviewersClient) RequiredReviewers(path string) sets.String {
+	return sets.NewString()
+}
+
+func handlePullRequest(pc plugins.Agent, pre scm.PullRequestHook) error {
+	if pre.Action != scm.ActionOpen && pre.Action != scm.ActionReadyForReview {
+		return nil
+	}
+	if pre.PullRequest.Draft {
+		return nil
+	}
+	opts := optionsForRepo(pc.PluginConfig, pre.Repo.Namespace, pre.Repo.Name)
+	return handle(pc.SCMProviderClient, pc.OwnersClient, pc.Logger, opts, pre.Repo, &pre.PullRequest)
+}
+
+func handle(spc scmProviderClient, oc ownersClient, log *logrus.Entry, opts *plugins.Blunderbuss, repo scm.Repository, pr *scm.PullRequest) error {
+	org := repo.Namespace
+	repoName := repo.Name
+	owners, err := oc.LoadRepoOwners(org, repoName, pr.Base.Ref)
+	if err != nil {
+		return fmt.Errorf("error loading RepoOwners: %v", err)
+	}
+	changes, err := spc.GetPullRequestChanges(org, repoName, pr.Number)
+	if err != nil {
+		return fmt.Errorf("error getting PR changes: %v", err)
+	}
+	useStatusAvailability := opts.UseStatusAvailability && spc.SupportsGraphQL()
+	count := reviewerCount(opts)
+	busy := sets.NewString()
+
+	reviewers, requiredReviewers := getReviewers(owners, spc, log, pr.Author.Login, changes, count, useStatusAvailability, busy)
+	if missing := count - len(reviewers); missing > 0 && !opts.ExcludeApprovers {
+		// Attempt to use approvers as additional reviewers. This must use
+		// count instead of missing because owners can be both reviewers
+		// and approvers and the search might stop too early if it finds
+		// duplicates.
+		approvers, _ := getReviewers(fallbackReviewersClient{ownersClient: owners}, spc, log, pr.Author.Login, changes, count, useStatusAvailability, busy)
+		combinedReviewers := sets.NewString(reviewers...)
+		for _, approver := range approvers {
+			if combinedReviewers.Len() >= count {
+				break
+			}
+			combinedReviewers.Insert(approver)
+		}
+		log.Infof("Added %d approvers as reviewers. %d/%d reviewers found.", combinedReviewers.Len()-len(reviewers), combinedReviewers.Len(), count)
+		reviewers = combinedReviewers.List()
+	}
+	if missing := count - len(reviewers); missing > 0 {
+		log.Warnf("Not enough reviewers found in OWNERS files for files touched by this PR. %d/%d reviewers found.", len(reviewers), count)
+	}
+	if opts.MaxReviewerCount > 0 && len(reviewers) > opts.MaxReviewerCount {
+		log.Infof("Limiting request of %d reviewers to %d maxReviewers.", len(reviewers), opts.MaxReviewerCount)
+		reviewers = reviewers[:opts.MaxReviewerCount]
+	}
+
+	// add required reviewers if any
+	all := sets.NewString(reviewers...).Insert(requiredReviewers...).Delete(scmprovider.NormLogin(pr.Author.Login))
+	if all.Len() == 0 {
+		return nil
+	}
+	log.Infof("Requesting reviews from users %s.", all.List())
+	return spc.RequestReview(org, repoName, pr.Number, all.List())
+}
+
+// getReviewers selects count reviewers for the changes, favouring the leaf reviewers and then the reviewers
+// owning the files with the most changed lines, together with the required reviewers of the changed files
+func getReviewers(rc reviewersClient, spc scmProviderClient, log *logrus.Entry, author string, changes []*scm.Change, count int, useStatusAvailability bool, busy sets.String) ([]string, []string) {
+	authorSet := sets.NewString(scmprovider.NormLogin(author))
+	reviewers := sets.NewString()
+	requiredReviewers := sets.NewString()
+	for _, change := range changes {
+		requiredReviewers.Insert(rc.RequiredReviewers(change.Path).Difference(authorSet).UnsortedList()...)
+	}
+	for _, leafOnly := range []bool{true, false} {
+		potentialReviewers := getPotentialReviewers(rc, authorSet, changes, leafOnly)
+		for reviewers.Len() < count && len(potentialReviewers) > 0 {
+			candidate := popRandomWeighted(potentialReviewers)
+			if reviewers.Has(candidate) || busy.Has(candidate) {
+				continue
+			}
+			if useStatusAvailability {
+				isBusy, err := isUserBusy(spc, candidate)
+				if err != nil {
+					log.WithError(err).Errorf("Error checking the availability of %s.", candidate)
+				}
+				if isBusy {
+					busy.Insert(candidate)
+					continue
+				}
+			}
+			reviewers.Insert(candidate)
+		}
+	}
+	return reviewers.List(), requiredReviewers.List()
+}
+
+// weightMap maps the potential reviewers to their weight
+type weightMap map[string]int64
+
+// getPotentialReviewers gets the reviewers from the OWNERS files of the changed files, weighted by
+// the number of lines changed
+func getPotentialReviewers(rc reviewersClient, authorSet sets.String, changes []*scm.Change, leafOnly bool) weightMap {
+	potentialReviewers := weightMap{}
+	for _, change := range changes {
+		fileWeight := int64(change.Changes)
+		if fileWeight == 0 {
+			fileWeight = int64(change.Additions + change.Deletions)
+		}
+		if fileWeight < 1 {
+			fileWeight = 1
+		}
+		// Judge file size on a log scale-- effectively this
+		// makes three buckets, we shouldn't have many 10k+
+		// line changes.
+		fileWeight = int64(math.Log10(float64(fileWeight))) + 1
+
+		var fileOwners sets.String
+		if leafOnly {
+			fileOwners = rc.LeafReviewers(change.Path)
+		} else {
+			fileOwners = rc.Reviewers(change.Path)
+		}
+		for _, owner := range fileOwners.Difference(authorSet).List() {
+			potentialReviewers[owner] += fileWeight
+		}
+	}
+	return potentialReviewers
+}
+
+// popRandomWeighted selects a reviewer with a probability proportional to its weight and removes it from the map
+func popRandomWeighted(potentialReviewers weightMap) string {
+	var names []string
+	var weightSum int64
+	for name, weight := range potentialReviewers {
+		names = append(names, name)
+		weightSum += weight
+	}
+	// sort the names so that the selection only depends on the random number
+	sort.Strings(names)
+	selection := rand.Int63n(weightSum) // #nosec
+	var selected string
+	for _, name := range names {
+		selected = name
+		selection -= potentialReviewers[name]
+		if selection < 0 {
+			break
+		}
+	}
+	delete(potentialReviewers, selected)
+	return selected
+}
+
+type userStatusQuery struct {
+	User struct {
+		Status struct {
+			IndicatesLimitedAvailability githubql.Boolean
+		}
+	} `graphql:"user(login: $login)"`
+}
+
+// isUserBusy returns true if the user has set their GitHub status as busy
+func isUserBusy(spc scmProviderClient, login string) (bool, error) {
+	var query userStatusQuery
+	vars := map[string]interface{}{
+		"login": githubql.String(login),
+	}
+	if err := spc.Query(context.Background(), &query, vars); err != nil {
+		return false, err
+	}
+	return bool(query.User.Status.IndicatesLimitedAvailability), nil
+}
@@ -0,0 +1,198 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package blunderbuss
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/jenkins-x/lighthouse/pkg/repoowners/fake"
+	githubql "github.com/shurcooL/githubv4"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+type fakeSCMClient struct {
+	changes   []*scm.Change
+	busy      sets.String
+	graphQL   bool
+	requested []string
+}
+
+func (f *fakeSCMClient) RequestReview(org, repo string, number int, logins []string) error {
+	f.requested = append(f.requested, logins...)
+	return nil
+}
+
+func (f *fakeSCMClient) GetPullRequestChanges(org, repo string, number int) ([]*scm.Change, error) {
+	return f.changes, nil
+}
+
+func (f *fakeSCMClient) SupportsGraphQL() bool {
+	return f.graphQL
+}
+
+func (f *fakeSCMClient) Query(ctx context.Context, q interface{}, vars map[string]interface{}) error {
+	query := q.(*userStatusQuery)
+	login := string(vars["login"].(githubql.String))
+	query.User.Status.IndicatesLimitedAvailability = githubql.Boolean(f.busy.Has(login))
+	return nil
+}
+
+func newOwnersClient() *fake.Client {
+	return &fake.Client{
+		Owners: &fake.FakeRepoOwners{
+			Dirs: map[string]fake.DirOwners{
+				"": {
+					Approvers: sets.NewString("root-approver"),
+					Reviewers: sets.NewString("root-reviewer"),
+				},
+				"docs": {
+					Approvers: sets.NewString("docs-approver"),
+					Reviewers: sets.NewString("docs-reviewer", "author"),
+				},
+				"pkg": {
+					Approvers:         sets.NewString("pkg-approver"),
+					Reviewers:         sets.NewString("pkg-reviewer-1", "pkg-reviewer-2"),
+					RequiredReviewers: sets.NewString("security"),
+				},
+			},
+		},
+	}
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func TestHandle(t *testing.T) {
+	docsChange := &scm.Change{Path: "docs/README.md", Additions: 10}
+	pkgChange := &scm.Change{Path: "pkg/foo.go", Changes: 1000}
+	testCases := []struct {
+		name             string
+		changes          []*scm.Change
+		opts             plugins.Blunderbuss
+		busy             []string
+		expectedCount    int
+		expectedIncluded []string
+		possible         []string
+	}{
+		{
+			name:             "leaf reviewers are favoured and the author is excluded",
+			changes:          []*scm.Change{docsChange},
+			opts:             plugins.Blunderbuss{ReviewerCount: intPtr(1)},
+			expectedCount:    1,
+			expectedIncluded: []string{"docs-reviewer"},
+		},
+		{
+			name:          "default reviewer count",
+			changes:       []*scm.Change{docsChange, pkgChange},
+			expectedCount: 3,
+			// the required reviewers are always added
+			expectedIncluded: []string{"security"},
+			possible:         []string{"docs-reviewer", "pkg-reviewer-1", "pkg-reviewer-2"},
+		},
+		{
+			name:             "parent reviewers are used when there are not enough leaf reviewers",
+			changes:          []*scm.Change{docsChange},
+			opts:             plugins.Blunderbuss{ReviewerCount: intPtr(2)},
+			expectedCount:    2,
+			expectedIncluded: []string{"docs-reviewer", "root-reviewer"},
+		},
+		{
+			name:             "approvers are used when there are not enough reviewers",
+			changes:          []*scm.Change{docsChange},
+			opts:             plugins.Blunderbuss{ReviewerCount: intPtr(3)},
+			expectedCount:    3,
+			expectedIncluded: []string{"docs-reviewer", "root-reviewer"},
+			possible:         []string{"docs-approver", "root-approver"},
+		},
+		{
+			name:             "approvers are excluded",
+			changes:          []*scm.Change{docsChange},
+			opts:             plugins.Blunderbuss{ReviewerCount: intPtr(3), ExcludeApprovers: true},
+			expectedCount:    2,
+			expectedIncluded: []string{"docs-reviewer", "root-reviewer"},
+		},
+		{
+			name:             "max reviewer count",
+			changes:          []*scm.Change{pkgChange},
+			opts:             plugins.Blunderbuss{ReviewerCount: intPtr(2), MaxReviewerCount: 1},
+			expectedCount:    2,
+			expectedIncluded: []string{"security"},
+			possible:         []string{"pkg-reviewer-1", "pkg-reviewer-2"},
+		},
+		{
+			name:             "busy reviewers are skipped",
+			changes:          []*scm.Change{pkgChange},
+			opts:             plugins.Blunderbuss{ReviewerCount: intPtr(1), UseStatusAvailability: true},
+			busy:             []string{"pkg-reviewer-1"},
+			expectedCount:    2,
+			expectedIncluded: []string{"pkg-reviewer-2", "security"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// run several times as the reviewers are selected randomly
+			for i := 0; i < 10; i++ {
+				spc := &fakeSCMClient{changes: tc.changes, busy: sets.NewString(tc.busy...), graphQL: true}
+				pr := &scm.PullRequest{Number: 1, Author: scm.User{Login: "author"}, Base: scm.PullRequestBranch{Ref: "master"}}
+				repo := scm.Repository{Namespace: "org", Name: "repo"}
+				opts := tc.opts
+				require.NoError(t, handle(spc, newOwnersClient(), logrus.WithField("plugin", pluginName), &opts, repo, pr))
+
+				assert.Len(t, spc.requested, tc.expectedCount)
+				requested := sets.NewString(spc.requested...)
+				assert.True(t, requested.HasAll(tc.expectedIncluded...), "expected %v to be requested, got %v", tc.expectedIncluded, spc.requested)
+				allowed := sets.NewString(tc.expectedIncluded...).Insert(tc.possible...)
+				assert.True(t, allowed.IsSuperset(requested), "unexpected reviewers requested: %v", requested.Difference(allowed).List())
+			}
+		})
+	}
+}
+
+func TestPopRandomWeighted(t *testing.T) {
+	weights := weightMap{"a": 1, "b": 100}
+	counts := map[string]int{}
+	for i := 0; i < 1000; i++ {
+		w := weightMap{}
+		for k, v := range weights {
+			w[k] = v
+		}
+		selected := popRandomWeighted(w)
+		assert.NotContains(t, w, selected)
+		counts[selected]++
+	}
+	assert.Greater(t, counts["b"], counts["a"])
+}
+
+func TestOptionsForRepo(t *testing.T) {
+	config := &plugins.Configuration{
+		Blunderbuss: []plugins.Blunderbuss{
+			{Repos: []string{"org"}, ReviewerCount: intPtr(3)},
+			{Repos: []string{"org/repo"}, ReviewerCount: intPtr(1), ExcludeApprovers: true},
+		},
+	}
+	assert.Equal(t, 1, reviewerCount(optionsForRepo(config, "org", "repo")))
+	assert.True(t, optionsForRepo(config, "org", "repo").ExcludeApprovers)
+	assert.Equal(t, 3, reviewerCount(optionsForRepo(config, "org", "other")))
+	assert.Equal(t, defaultReviewerCount, reviewerCount(optionsForRepo(config, "other", "repo")))
+}
@@ -0,0 +1,296 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package cherrypicker contains a plugin which cherry-picks merged pull requests
+// onto other branches when requested with the /cherrypick command.
+package cherrypicker
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jenkins-x/go-scm/scm"
+	gitv2 "github.com/jenkins-x/lighthouse/pkg/git/v2"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const (
+	pluginName = "cherrypicker"
+)
+
+var (
+	cherryPickCommand = plugins.Command{
+		Name: "cherrypick",
+		Arg: &plugins.CommandArg{
+			Usage:   "branch",
+			Pattern: `[^\s]+`,
+		},
+		Description: "Cherry-picks the pull request onto the given branch once it has been merged.",
+		Featured:    true,
+		WhoCanUse:   "Members of the organization the repository belongs to.",
+		Action: plugins.
+			Invoke(func(match plugins.CommandMatch, pc plugins.Agent, e scmprovider.GenericCommentEvent) error {
+				return handleCommand(newCherryPicker(pc), e, match.Arg)
+			}).
+			When(plugins.Action(scm.ActionCreate), plugins.IsPR()),
+	}
+	plugin = plugins.Plugin{
+		Description:        "The cherrypicker plugin cherry-picks merged pull requests onto other branches. The pull request commits are applied on top of the requested branch in a fork owned by the bot, and a new pull request is opened and assigned to the requestor.",
+		PullRequestHandler: handlePullRequest,
+		Commands:           []plugins.Command{cherryPickCommand},
+	}
+)
+
+func init() {
+	plugins.RegisterPlugin(pluginName, plugin)
+}
+
+type scmProviderClient interface {
+	BotName() (string, error)
+	CreateComment(owner, repo string, number int, pr bool, comment string) error
+	CreatePullRequest(owner, repo string, input *scm.PullRequestInput) (*scm.PullRequest, error)
+	EnsureFork(owner, repo string) (string, error)
+	FindPullRequestsByAuthor(owner, repo, author string) ([]*scm.PullRequest, error)
+	GetPullRequest(owner, repo string, number int) (*scm.PullRequest, error)
+	GetPullRequestPatch(owner, repo string, number int) ([]byte, error)
+	IsMember(org, user string) (bool, error)
+	ListPullRequestComments(owner, repo string, number int) ([]*scm.Comment, error)
+	QuoteAuthorForComment(string) string
+}
+
+type cherryPicker struct {
+	spc        scmProviderClient
+	gitFactory gitv2.ClientFactory
+	log        *logrus.Entry
+}
+
+func newCherryPicker(pc plugins.Agent) *cherryPicker {
+	return &cherryPicker{
+		spc:        pc.SCMProviderClient,
+		gitFactory: pc.GitClientFactory,
+		log:        pc.Logger,
+	}
+}
+
+func handleCommand(c *cherryPicker, e scmprovider.GenericCommentEvent, targetBranch string) error {
+	org := e.Repo.Namespace
+	repo := e.Repo.Name
+	requestor := e.Author.Login
+
+	member, err := c.spc.IsMember(org, requestor)
+	if err != nil {
+		return errors.Wrapf(err, "checking if %s is a member of %s", requestor, org)
+	}
+	if !member {
+		msg := fmt.Sprintf("only members of the %s organization may request cherry-picks.", org)
+		return c.spc.CreateComment(org, repo, e.Number, true, plugins.FormatResponseRaw(e.Body, e.Link, c.spc.QuoteAuthorForComment(requestor), msg))
+	}
+
+	pr, err := c.spc.GetPullRequest(org, repo, e.Number)
+	if err != nil {
+		return errors.Wrapf(err, "getting pull request %s/%s#%d", org, repo, e.Number)
+	}
+	if pr.Base.Ref == targetBranch {
+		msg := fmt.Sprintf("base branch (%s) needs to differ from target branch (%s).", pr.Base.Ref, targetBranch)
+		return c.spc.CreateComment(org, repo, e.Number, true, plugins.FormatResponseRaw(e.Body, e.Link, c.spc.QuoteAuthorForComment(requestor), msg))
+	}
+	if !pr.Merged {
+		if pr.Closed {
+			msg := "cannot cherry-pick a pull request which has been closed without being merged."
+			return c.spc.CreateComment(org, repo, e.Number, true, plugins.FormatResponseRaw(e.Body, e.Link, c.spc.QuoteAuthorForComment(requestor), msg))
+		}
+		msg := fmt.Sprintf("once the present PR merges, I will cherry-pick it on top of %s in a new PR and assign it to you.", targetBranch)
+		return c.spc.CreateComment(org, repo, e.Number, true, plugins.FormatResponseRaw(e.Body, e.Link, c.spc.QuoteAuthorForComment(requestor), msg))
+	}
+	return c.cherryPick(pr, e.Repo, targetBranch, requestor)
+}
+
+func handlePullRequest(pc plugins.Agent, pre scm.PullRequestHook) error {
+	return handlePR(newCherryPicker(pc), pre)
+}
+
+// handlePR cherry-picks a merged pull request onto all the branches requested in its comments
+func handlePR(c *cherryPicker, pre scm.PullRequestHook) error {
+	if (pre.Action != scm.ActionClose && pre.Action != scm.ActionMerge) || !pre.PullRequest.Merged {
+		return nil
+	}
+	org := pre.Repo.Namespace
+	repo := pre.Repo.Name
+	num := pre.PullRequest.Number
+
+	comments, err := c.spc.ListPullRequestComments(org, repo, num)
+	if err != nil {
+		return errors.Wrapf(err, "listing comments of %s/%s#%d", org, repo, num)
+	}
+
+	requests := map[string]string{}
+	var targetBranches []string
+	for _, comment := range comments {
+		matches, err := cherryPickCommand.GetMatches(comment.Body)
+		if err != nil {
+			return err
+		}
+		if len(matches) == 0 {
+			continue
+		}
+		member, err := c.spc.IsMember(org, comment.Author.Login)
+		if err != nil {
+			return errors.Wrapf(err, "checking if %s is a member of %s", comment.Author.Login, org)
+		}
+		if !member {
+			continue
+		}
+		for _, match := range matches {
+			if _, requested := requests[match.Arg]; requested || match.Arg == pre.PullRequest.Base.Ref {
+				continue
+			}
+			requests[match.Arg] = comment.Author.Login
+			targetBranches = append(targetBranches, match.Arg)
+		}
+	}
+
+	var errs []string
+	for _, targetBranch := range targetBranches {
+		if err := c.cherryPick(&pre.PullRequest, pre.Repo, targetBranch, requests[targetBranch]); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Errorf("failed to cherry-pick %s/%s#%d: %s", org, repo, num, strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// cherryPick applies the commits of the pull request onto the target branch in the bot fork,
+// then opens a new pull request and reports the outcome on the original one
+func (c *cherryPicker) cherryPick(pr *scm.PullRequest, repository scm.Repository, targetBranch, requestor string) error {
+	org := repository.Namespace
+	repo := repository.Name
+	num := pr.Number
+	log := c.log.WithFields(logrus.Fields{"org": org, "repo": repo, "number": num, "targetBranch": targetBranch})
+
+	comment := func(msg string) error {
+		return c.spc.CreateComment(org, repo, num, true, plugins.FormatSimpleResponse(c.spc.QuoteAuthorForComment(requestor), msg))
+	}
+
+	if c.gitFactory == nil {
+		return errors.New("no git client factory available to cherry-pick pull requests")
+	}
+	botName, err := c.spc.BotName()
+	if err != nil {
+		return err
+	}
+	newBranch := fmt.Sprintf("cherry-pick-%d-to-%s", num, targetBranch)
+	title := fmt.Sprintf("[%s] %s", targetBranch, pr.Title)
+
+	existing, err := c.existingPullRequest(org, repo, botName, newBranch, targetBranch)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return comment(fmt.Sprintf("looks like #%d has already been cherry-picked in #%d", num, existing.Number))
+	}
+
+	fork, err := c.spc.EnsureFork(org, repo)
+	if err != nil {
+		return errors.Wrapf(err, "ensuring a fork of %s/%s exists", org, repo)
+	}
+	patch, err := c.spc.GetPullRequestPatch(org, repo, num)
+	if err != nil {
+		return errors.Wrapf(err, "getting the patch of %s/%s#%d", org, repo, num)
+	}
+
+	r, err := c.gitFactory.ClientFor(org, repo, nil)
+	if err != nil {
+		return errors.Wrapf(err, "cloning %s/%s", org, repo)
+	}
+	defer func() {
+		if err := r.Clean(); err != nil {
+			log.WithError(err).Error("Error cleaning up repo.")
+		}
+	}()
+	if err := r.Config("user.name", botName); err != nil {
+		return err
+	}
+	if err := r.Config("user.email", botName+"@users.noreply.github.com"); err != nil {
+		return err
+	}
+
+	if err := r.Checkout(targetBranch); err != nil {
+		log.WithError(err).Info("Failed to checkout the target branch.")
+		return comment(fmt.Sprintf("cannot checkout `%s`: %v", targetBranch, err))
+	}
+	if err := r.CheckoutNewBranch(newBranch); err != nil {
+		return errors.Wrapf(err, "creating branch %s", newBranch)
+	}
+
+	patchDir, err := os.MkdirTemp("", "cherrypick")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(patchDir) // nolint: errcheck
+	patchPath := filepath.Join(patchDir, fmt.Sprintf("%s-%s-%d.patch", org, repo, num))
+	if err := os.WriteFile(patchPath, patch, 0600); err != nil {
+		return errors.Wrapf(err, "writing patch to %s", patchPath)
+	}
+	if err := r.Am(patchPath); err != nil {
+		log.WithError(err).Info("Failed to apply the patch.")
+		return comment(fmt.Sprintf("#%d failed to apply on top of branch %q:\n```\n%v\n```", num, targetBranch, err))
+	}
+
+	// the fork may be owned by an organization or named differently than the repository
+	forkOwner, forkRepo := scm.Split(fork)
+	if err := r.PushToNamedFork(forkRepo, newBranch, true); err != nil {
+		log.WithError(err).Info("Failed to push the cherry-pick.")
+		return comment(fmt.Sprintf("failed to push cherry-picked changes to the %s fork: %v", fork, err))
+	}
+
+	head := fmt.Sprintf("%s:%s", forkOwner, newBranch)
+	body := fmt.Sprintf("This is an automated cherry-pick of #%d\n\n/assign %s", num, requestor)
+	created, err := c.spc.CreatePullRequest(org, repo, &scm.PullRequestInput{
+		Title: title,
+		Head:  head,
+		Base:  targetBranch,
+		Body:  body,
+	})
+	if err != nil {
+		log.WithError(err).Info("Failed to create the pull request.")
+		return comment(fmt.Sprintf("new pull request could not be created: %v", err))
+	}
+	log.WithField("pullRequest", created.Number).Info("Created the cherry-pick pull request.")
+	return comment(fmt.Sprintf("new pull request created: #%d", created.Number))
+}
+
+// existingPullRequest returns the open pull request previously created by the bot for the same cherry-pick, if any
+func (c *cherryPicker) existingPullRequest(org, repo, botName, newBranch, targetBranch string) (*scm.PullRequest, error) {
+	prs, err := c.spc.FindPullRequestsByAuthor(org, repo, botName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "listing pull requests of %s in %s/%s", botName, org, repo)
+	}
+	branches := sets.NewString(newBranch, botName+":"+newBranch)
+	for _, pr := range prs {
+		if !pr.Closed && branches.Has(pr.Head.Ref) && pr.Base.Ref == targetBranch {
+			return pr, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,326 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cherrypicker
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm"
+	gitv2 "github.com/jenkins-x/lighthouse/pkg/git/v2"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSCMClient struct {
+	members     []string
+	pr          *scm.PullRequest
+	botPRs      []*scm.PullRequest
+	comments    []*scm.Comment
+	patch       string
+	fork        string
+	createdPRs  []*scm.PullRequestInput
+	newComments []string
+}
+
+func (f *fakeSCMClient) BotName() (string, error) {
+	return "bot", nil
+}
+
+func (f *fakeSCMClient) CreateComment(owner, repo string, number int, pr bool, comment string) error {
+	f.newComments = append(f.newComments, comment)
+	return nil
+}
+
+func (f *fakeSCMClient) CreatePullRequest(owner, repo string, input *scm.PullRequestInput) (*scm.PullRequest, error) {
+	f.createdPRs = append(f.createdPRs, input)
+	return &scm.PullRequest{Number: 100 + len(f.createdPRs), Title: input.Title}, nil
+}
+
+func (f *fakeSCMClient) EnsureFork(owner, repo string) (string, error) {
+	if f.fork != "" {
+		return f.fork, nil
+	}
+	return "bot/" + repo, nil
+}
+
+func (f *fakeSCMClient) FindPullRequestsByAuthor(owner, repo, author string) ([]*scm.PullRequest, error) {
+	return f.botPRs, nil
+}
+
+func (f *fakeSCMClient) GetPullRequest(owner, repo string, number int) (*scm.PullRequest, error) {
+	return f.pr, nil
+}
+
+func (f *fakeSCMClient) GetPullRequestPatch(owner, repo string, number int) ([]byte, error) {
+	return []byte(f.patch), nil
+}
+
+func (f *fakeSCMClient) IsMember(org, user string) (bool, error) {
+	for _, m := range f.members {
+		if m == user {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (f *fakeSCMClient) ListPullRequestComments(owner, repo string, number int) ([]*scm.Comment, error) {
+	return f.comments, nil
+}
+
+func (f *fakeSCMClient) QuoteAuthorForComment(author string) string {
+	return author
+}
+
+type fakeGitClientFactory struct {
+	gitv2.ClientFactory
+	repo *fakeRepoClient
+}
+
+func (f *fakeGitClientFactory) ClientFor(org, repo string, sparseCheckoutPatterns []string) (gitv2.RepoClient, error) {
+	return f.repo, nil
+}
+
+type fakeRepoClient struct {
+	gitv2.RepoClient
+	branches []string
+	amErr    error
+	patches  []string
+	commands []string
+	cleaned  bool
+}
+
+func (f *fakeRepoClient) Clean() error {
+	f.cleaned = true
+	return nil
+}
+
+func (f *fakeRepoClient) Config(key, value string) error {
+	return nil
+}
+
+func (f *fakeRepoClient) Checkout(commitlike string) error {
+	for _, b := range f.branches {
+		if b == commitlike {
+			f.commands = append(f.commands, "checkout "+commitlike)
+			return nil
+		}
+	}
+	return fmt.Errorf("pathspec '%s' did not match any file(s) known to git", commitlike)
+}
+
+func (f *fakeRepoClient) CheckoutNewBranch(branch string) error {
+	f.commands = append(f.commands, "checkout -b "+branch)
+	return nil
+}
+
+func (f *fakeRepoClient) Am(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	f.patches = append(f.patches, string(data))
+	f.commands = append(f.commands, "am")
+	return f.amErr
+}
+
+func (f *fakeRepoClient) PushToNamedFork(forkName, branch string, force bool) error {
+	f.commands = append(f.commands, "push "+forkName+" "+branch)
+	return nil
+}
+
+func newTestCherryPicker(spc *fakeSCMClient, repo *fakeRepoClient) *cherryPicker {
+	return &cherryPicker{
+		spc:        spc,
+		gitFactory: &fakeGitClientFactory{repo: repo},
+		log:        logrus.WithField("plugin", pluginName),
+	}
+}
+
+func TestHandleCommand(t *testing.T) {
+	repo := scm.Repository{Namespace: "org", Name: "repo"}
+	testCases := []struct {
+		name             string
+		requestor        string
+		pr               *scm.PullRequest
+		amErr            error
+		targetBranch     string
+		botPRs           []*scm.PullRequest
+		fork             string
+		expectedPR       *scm.PullRequestInput
+		expectedCommands []string
+		expectedComment  string
+	}{
+		{
+			name:            "not a member",
+			requestor:       "stranger",
+			pr:              &scm.PullRequest{Number: 2, Merged: true, Base: scm.PullRequestBranch{Ref: "master"}},
+			targetBranch:    "release-1.0",
+			expectedComment: "only members of the org organization may request cherry-picks.",
+		},
+		{
+			name:            "not merged yet",
+			requestor:       "alice",
+			pr:              &scm.PullRequest{Number: 2, Base: scm.PullRequestBranch{Ref: "master"}},
+			targetBranch:    "release-1.0",
+			expectedComment: "once the present PR merges, I will cherry-pick it on top of release-1.0 in a new PR and assign it to you.",
+		},
+		{
+			name:            "same branch",
+			requestor:       "alice",
+			pr:              &scm.PullRequest{Number: 2, Merged: true, Base: scm.PullRequestBranch{Ref: "master"}},
+			targetBranch:    "master",
+			expectedComment: "base branch (master) needs to differ from target branch (master).",
+		},
+		{
+			name:         "merged",
+			requestor:    "alice",
+			pr:           &scm.PullRequest{Number: 2, Title: "Fix the bug", Merged: true, Base: scm.PullRequestBranch{Ref: "master"}},
+			targetBranch: "release-1.0",
+			expectedPR: &scm.PullRequestInput{
+				Title: "[release-1.0] Fix the bug",
+				Head:  "bot:cherry-pick-2-to-release-1.0",
+				Base:  "release-1.0",
+				Body:  "This is an automated cherry-pick of #2\n\n/assign alice",
+			},
+			expectedCommands: []string{"checkout release-1.0", "checkout -b cherry-pick-2-to-release-1.0", "am", "push repo cherry-pick-2-to-release-1.0"},
+			expectedComment:  "new pull request created: #101",
+		},
+		{
+			name:         "renamed fork",
+			requestor:    "alice",
+			pr:           &scm.PullRequest{Number: 2, Title: "Fix the bug", Merged: true, Base: scm.PullRequestBranch{Ref: "master"}},
+			targetBranch: "release-1.0",
+			fork:         "bot-org/repo-1",
+			expectedPR: &scm.PullRequestInput{
+				Title: "[release-1.0] Fix the bug",
+				Head:  "bot-org:cherry-pick-2-to-release-1.0",
+				Base:  "release-1.0",
+				Body:  "This is an automated cherry-pick of #2\n\n/assign alice",
+			},
+			expectedCommands: []string{"checkout release-1.0", "checkout -b cherry-pick-2-to-release-1.0", "am", "push repo-1 cherry-pick-2-to-release-1.0"},
+			expectedComment:  "new pull request created: #101",
+		},
+		{
+			name:             "conflict",
+			requestor:        "alice",
+			pr:               &scm.PullRequest{Number: 2, Title: "Fix the bug", Merged: true, Base: scm.PullRequestBranch{Ref: "master"}},
+			targetBranch:     "release-1.0",
+			amErr:            errors.New("Patch failed at 0001 Fix the bug"),
+			expectedCommands: []string{"checkout release-1.0", "checkout -b cherry-pick-2-to-release-1.0", "am"},
+			expectedComment:  "#2 failed to apply on top of branch \"release-1.0\":\n```\nPatch failed at 0001 Fix the bug\n```",
+		},
+		{
+			name:            "missing branch",
+			requestor:       "alice",
+			pr:              &scm.PullRequest{Number: 2, Merged: true, Base: scm.PullRequestBranch{Ref: "master"}},
+			targetBranch:    "release-2.0",
+			expectedComment: "cannot checkout `release-2.0`: pathspec 'release-2.0' did not match any file(s) known to git",
+		},
+		{
+			name:         "already cherry-picked",
+			requestor:    "alice",
+			pr:           &scm.PullRequest{Number: 2, Merged: true, Base: scm.PullRequestBranch{Ref: "master"}},
+			targetBranch: "release-1.0",
+			botPRs: []*scm.PullRequest{{
+				Number: 42,
+				Head:   scm.PullRequestBranch{Ref: "cherry-pick-2-to-release-1.0"},
+				Base:   scm.PullRequestBranch{Ref: "release-1.0"},
+			}},
+			expectedComment: "looks like #2 has already been cherry-picked in #42",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			spc := &fakeSCMClient{
+				members: []string{"alice"},
+				pr:      tc.pr,
+				botPRs:  tc.botPRs,
+				fork:    tc.fork,
+				patch:   "From 1234 Mon Sep 17 00:00:00 2001\nSubject: [PATCH] Fix the bug\n",
+			}
+			repoClient := &fakeRepoClient{branches: []string{"master", "release-1.0"}, amErr: tc.amErr}
+			e := scmprovider.GenericCommentEvent{
+				IsPR:   true,
+				Action: scm.ActionCreate,
+				Body:   "/cherrypick " + tc.targetBranch,
+				Number: 2,
+				Repo:   repo,
+				Author: scm.User{Login: tc.requestor},
+			}
+			require.NoError(t, handleCommand(newTestCherryPicker(spc, repoClient), e, tc.targetBranch))
+
+			require.Len(t, spc.newComments, 1)
+			assert.Contains(t, spc.newComments[0], tc.expectedComment)
+			assert.Equal(t, tc.expectedCommands, repoClient.commands)
+			if tc.expectedPR == nil {
+				assert.Empty(t, spc.createdPRs)
+				return
+			}
+			require.Len(t, spc.createdPRs, 1)
+			assert.Equal(t, tc.expectedPR, spc.createdPRs[0])
+			assert.Equal(t, []string{spc.patch}, repoClient.patches)
+			assert.True(t, repoClient.cleaned)
+		})
+	}
+}
+
+func TestHandlePR(t *testing.T) {
+	spc := &fakeSCMClient{
+		members: []string{"alice", "bob"},
+		comments: []*scm.Comment{
+			{Body: "/cherrypick release-1.0", Author: scm.User{Login: "alice"}},
+			{Body: "/cherrypick release-1.1\n/cherrypick release-1.0", Author: scm.User{Login: "bob"}},
+			{Body: "/cherrypick release-1.2", Author: scm.User{Login: "stranger"}},
+			{Body: "/cherrypick master", Author: scm.User{Login: "alice"}},
+		},
+	}
+	repoClient := &fakeRepoClient{branches: []string{"master", "release-1.0", "release-1.1", "release-1.2"}}
+	pre := scm.PullRequestHook{
+		Action: scm.ActionClose,
+		Repo:   scm.Repository{Namespace: "org", Name: "repo"},
+		PullRequest: scm.PullRequest{
+			Number: 2,
+			Title:  "Fix the bug",
+			Merged: true,
+			Base:   scm.PullRequestBranch{Ref: "master"},
+		},
+	}
+
+	unmerged := pre
+	unmerged.PullRequest.Merged = false
+	require.NoError(t, handlePR(newTestCherryPicker(spc, repoClient), unmerged))
+	assert.Empty(t, spc.createdPRs)
+
+	require.NoError(t, handlePR(newTestCherryPicker(spc, repoClient), pre))
+	var bases, bodies []string
+	for _, pr := range spc.createdPRs {
+		bases = append(bases, pr.Base)
+		bodies = append(bodies, pr.Body)
+	}
+	assert.Equal(t, []string{"release-1.0", "release-1.1"}, bases)
+	assert.Equal(t, []string{
+		"This is an automated cherry-pick of #2\n\n/assign alice",
+		"This is an automated cherry-pick of #2\n\n/assign bob",
+	}, bodies)
+	assert.Len(t, spc.newComments, 2)
+}
@@ -52,6 +52,7 @@ type Configuration struct {
 	// Built-in plugins specific configuration.
 	Approve              []Approve              `json:"approve,omitempty"`
 	Blockades            []Blockade             `json:"blockades,omitempty"`
+	Blunderbuss          []Blunderbuss          `json:"blunderbuss,omitempty"`
 	Cat                  Cat                    `json:"cat,omitempty"`
 	CherryPickUnapproved CherryPickUnapproved   `json:"cherry_pick_unapproved,omitempty"`
 	ConfigUpdater        ConfigUpdater          `json:"config_updater,omitempty"`
@@ -78,6 +79,13 @@ type ExternalPlugin struct {
 	// server to the external plugin. If no events are specified,
 	// everything is sent.
 	Events []string `json:"events,omitempty"`
+	// Timeout is the maximum duration of a single delivery attempt to the plugin.
+	// Defaults to '30s'.
+	Timeout         string        `json:"timeout,omitempty"`
+	TimeoutDuration time.Duration `json:"-"`
+	// MaxConcurrency is the maximum number of events delivered at the same time to
+	// the endpoint of the plugin. Defaults to 10.
+	MaxConcurrency int `json:"max_concurrency,omitempty"`
 }
 
 // Owners contains configuration related to handling OWNERS files.
@@ -103,6 +111,12 @@ type Owners struct {
 	// OWNERS file, preventing their automatic addition by the owners-label plugin.
 	// This check is performed by the verify-owners plugin.
 	LabelsExcludeList []string `json:"labels_excludes,omitempty"`
+	// CodeOwnersRepos is a list of org and org/repo strings specifying the repos whose
+	// CODEOWNERS file (in .github/, the root or docs/) is used as an OWNERS source.
+	// CODEOWNERS owners are both approvers and reviewers, @org/team owners are expanded
+	// into the members of the team. As on GitHub, a path is owned by the last rule matching it
+	// only, its owners are added to the ones of the OWNERS files of the directories containing it.
+	CodeOwnersRepos []string `json:"codeowners_repos,omitempty"`
 }
 
 // MDYAMLEnabled returns a boolean denoting if the passed repo supports YAML OWNERS config headers
@@ -118,6 +132,18 @@ func (c *Configuration) MDYAMLEnabled(org, repo string) bool {
 	return false
 }
 
+// CodeOwnersEnabled returns a boolean denoting if the CODEOWNERS file of the passed repo
+// should be used as an OWNERS source.
+func (c *Configuration) CodeOwnersEnabled(org, repo string) bool {
+	full := fmt.Sprintf("%s/%s", org, repo)
+	for _, elem := range c.Owners.CodeOwnersRepos {
+		if elem == org || elem == full {
+			return true
+		}
+	}
+	return false
+}
+
 // SkipCollaborators returns a boolean denoting if collaborator cross-checks are enabled for
 // the passed repo. If it's true, approve and lgtm plugins rely solely on OWNERS files.
 func (c *Configuration) SkipCollaborators(org, repo string) bool {
@@ -203,6 +229,10 @@ type Approve struct {
 	IgnoreReviewState *bool `json:"ignore_review_state,omitempty"`
 	// IgnoreUpdateBot makes the approve plugin ignore PRs with the label updatebot
 	IgnoreUpdateBot *bool `json:"ignore_updatebot,omitempty"`
+	// RequiredDistinctApprovers is the number of distinct approvers required to approve a PR, each of them
+	// being an approver in a different OWNERS file of the changed files or their parents.
+	// It is capped to the number of such OWNERS files. Defaults to 0 which disables the check.
+	RequiredDistinctApprovers int `json:"required_distinct_approvers,omitempty"`
 }
 
 // HasSelfApproval checks if it has self-approval
@@ -248,6 +278,27 @@ type Lgtm struct {
 	StickyLgtmTeam string `json:"trusted_team_for_sticky_lgtm,omitempty"`
 }
 
+// Blunderbuss defines configuration for the blunderbuss plugin.
+type Blunderbuss struct {
+	// Repos is either of the form org/repos or just org.
+	Repos []string `json:"repos,omitempty"`
+	// ReviewerCount is the minimum number of reviewers to request
+	// reviews from. Defaults to requesting reviews from 2 reviewers.
+	ReviewerCount *int `json:"request_count,omitempty"`
+	// MaxReviewerCount is the maximum number of reviewers to request
+	// reviews from. Defaults to 0 meaning no limit.
+	MaxReviewerCount int `json:"max_request_count,omitempty"`
+	// ExcludeApprovers controls whether approvers are considered to be
+	// reviewers. By default, approvers are considered as reviewers if
+	// insufficient reviewers are available. If ExcludeApprovers is true,
+	// approvers will never be considered as reviewers.
+	ExcludeApprovers bool `json:"exclude_approvers,omitempty"`
+	// UseStatusAvailability controls whether blunderbuss will skip the reviewers
+	// who have set their GitHub status as busy. This uses one additional GraphQL
+	// query per considered reviewer and is ignored for other providers.
+	UseStatusAvailability bool `json:"use_status_availability,omitempty"`
+}
+
 // Cat contains the configuration for the cat plugin.
 type Cat struct {
 	// Path to file containing an api key for thecatapi.com
@@ -298,6 +349,9 @@ type Trigger struct {
 	// ShowReportCompletionDuration when enabled, show completion duration in report status in the SCM provider
 	// based on StartTime and CompletionTime of the PipelineActivity.
 	ShowReportCompletionDuration bool `json:"show_report_completion_duration,omitempty"`
+	// SkipAbortSupersededJobs when enabled, keeps running the presubmits started for previous commits
+	// of a PR when new commits are pushed instead of aborting them.
+	SkipAbortSupersededJobs bool `json:"skip_abort_superseded_jobs,omitempty"`
 }
 
 // Milestone contains the configuration options for the milestone and
@@ -662,6 +716,11 @@ func validateExternalPlugins(pluginMap map[string][]ExternalPlugin) error {
 	var errors []string
 
 	for repo, plugins := range pluginMap {
+		for _, p := range plugins {
+			if p.MaxConcurrency < 0 {
+				errors = append(errors, fmt.Sprintf("external plugin %s for %s has a negative max_concurrency", p.Name, repo))
+			}
+		}
 		if !strings.Contains(repo, "/") {
 			continue
 		}
@@ -723,6 +782,21 @@ func validateRequireMatchingLabel(rs []RequireMatchingLabel) error {
 	return nil
 }
 
+func validateBlunderbuss(bs []Blunderbuss) error {
+	for i, b := range bs {
+		if b.ReviewerCount != nil && *b.ReviewerCount < 1 {
+			return fmt.Errorf("error validating blunderbuss config #%d: invalid request_count: %v (needs to be positive)", i, *b.ReviewerCount)
+		}
+		if b.MaxReviewerCount < 0 {
+			return fmt.Errorf("error validating blunderbuss config #%d: invalid max_request_count: %v (needs to be non-negative)", i, b.MaxReviewerCount)
+		}
+		if b.ReviewerCount != nil && b.MaxReviewerCount > 0 && b.MaxReviewerCount < *b.ReviewerCount {
+			return fmt.Errorf("error validating blunderbuss config #%d: max_request_count (%d) cannot be lower than request_count (%d)", i, b.MaxReviewerCount, *b.ReviewerCount)
+		}
+	}
+	return nil
+}
+
 func compileRegexpsAndDurations(pc *Configuration) error {
 	cRe, err := regexp.Compile(pc.SigMention.Regexp)
 	if err != nil {
@@ -751,6 +825,19 @@ func compileRegexpsAndDurations(pc *Configuration) error {
 		}
 		rs[i].GracePeriodDuration = dur
 	}
+
+	for repo, plugins := range pc.ExternalPlugins {
+		for i, p := range plugins {
+			if p.Timeout == "" {
+				continue
+			}
+			dur, err := time.ParseDuration(p.Timeout)
+			if err != nil {
+				return fmt.Errorf("failed to compile timeout of external plugin %s for %s: %q, error: %v", p.Name, repo, p.Timeout, err)
+			}
+			pc.ExternalPlugins[repo][i].TimeoutDuration = dur
+		}
+	}
 	return nil
 }
 
@@ -782,6 +869,9 @@ func (c *Configuration) Validate() error {
 	if err := validateRequireMatchingLabel(c.RequireMatchingLabel); err != nil {
 		return err
 	}
+	if err := validateBlunderbuss(c.Blunderbuss); err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -80,6 +80,18 @@ func TestValidateExternalPlugins(t *testing.T) {
 			},
 			expectedErr: errors.New("invalid plugin configuration:\n\texternal plugins [tetris] are duplicated for kubernetes/test-infra and kubernetes"),
 		},
+		{
+			name: "negative max concurrency",
+			plugins: map[string][]ExternalPlugin{
+				"kubernetes/test-infra": {
+					{
+						Name:           "cherrypick",
+						MaxConcurrency: -1,
+					},
+				},
+			},
+			expectedErr: errors.New("invalid plugin configuration:\n\texternal plugin cherrypick for kubernetes/test-infra has a negative max_concurrency"),
+		},
 	}
 
 	for _, test := range tests {
@@ -0,0 +1,270 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package needsrebase contains a plugin which labels pull requests which
+// cannot be merged into their base branch any more.
+package needsrebase
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/commentpruner"
+	gitv2 "github.com/jenkins-x/lighthouse/pkg/git/v2"
+	"github.com/jenkins-x/lighthouse/pkg/labels"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	pluginName = "needs-rebase"
+
+	needsRebaseMessage = "PR needs rebase."
+)
+
+var (
+	plugin = plugins.Plugin{
+		Description:        "The needs-rebase plugin adds the '" + labels.NeedsRebase + "' label to pull requests which cannot be merged into their base branch, and removes it once they have been rebased. Pull requests are checked when they are updated and whenever their base branch changes.",
+		PullRequestHandler: handlePullRequest,
+		PushEventHandler:   handlePush,
+	}
+)
+
+func init() {
+	plugins.RegisterPlugin(pluginName, plugin)
+}
+
+type scmProviderClient interface {
+	AddLabel(owner, repo string, number int, label string, pr bool) error
+	RemoveLabel(owner, repo string, number int, label string, pr bool) error
+	CreateComment(owner, repo string, number int, pr bool, comment string) error
+	GetIssueLabels(org, repo string, number int, pr bool) ([]*scm.Label, error)
+	GetPullRequest(owner, repo string, number int) (*scm.PullRequest, error)
+	ListAllPullRequestsForFullNameRepo(fullName string, opts scm.PullRequestListOptions) ([]*scm.PullRequest, error)
+	PRRefFmt() string
+	QuoteAuthorForComment(string) string
+	BotName() (string, error)
+}
+
+type commentPruner interface {
+	PruneComments(pr bool, shouldPrune func(*scm.Comment) bool)
+}
+
+func handlePullRequest(pc plugins.Agent, pre scm.PullRequestHook) error {
+	cp, err := pc.CommentPruner()
+	if err != nil {
+		return err
+	}
+	return handlePR(pc.SCMProviderClient, pc.GitClientFactory, cp, pc.Logger, pre)
+}
+
+// handlePR checks the mergeability of a pull request which has been opened or updated
+func handlePR(spc scmProviderClient, gitFactory gitv2.ClientFactory, cp commentPruner, log *logrus.Entry, pre scm.PullRequestHook) error {
+	switch pre.Action {
+	case scm.ActionOpen, scm.ActionReopen, scm.ActionSync:
+	default:
+		return nil
+	}
+	org := pre.Repo.Namespace
+	repo := pre.Repo.Name
+	number := pre.PullRequest.Number
+
+	// the mergeable state is only computed when a single pull request is requested
+	pr, err := spc.GetPullRequest(org, repo, number)
+	if err != nil {
+		return errors.Wrapf(err, "getting pull request %s/%s#%d", org, repo, number)
+	}
+	checker := newMergeChecker(spc, gitFactory, org, repo, log)
+	defer checker.clean()
+
+	mergeable, known, err := checker.isMergeable(pr)
+	if err != nil {
+		return err
+	}
+	if !known {
+		log.Infof("Mergeability of %s/%s#%d is unknown, skipping.", org, repo, number)
+		return nil
+	}
+	return takeAction(spc, cp, log, org, repo, pr, mergeable)
+}
+
+func handlePush(pc plugins.Agent, pe scm.PushHook) error {
+	newPruner := func(org, repo string, number int) commentPruner {
+		return commentpruner.NewEventClient(pc.SCMProviderClient, pc.Logger.WithField("client", "commentpruner"), org, repo, number)
+	}
+	return handlePushEvent(pc.SCMProviderClient, pc.GitClientFactory, newPruner, pc.Logger, pe)
+}
+
+// handlePushEvent checks the mergeability of all the open pull requests against the branch which has been pushed to
+func handlePushEvent(spc scmProviderClient, gitFactory gitv2.ClientFactory, newPruner func(org, repo string, number int) commentPruner, log *logrus.Entry, pe scm.PushHook) error {
+	if pe.Deleted || !strings.HasPrefix(pe.Ref, "refs/heads/") {
+		return nil
+	}
+	branch := strings.TrimPrefix(pe.Ref, "refs/heads/")
+	org := pe.Repo.Namespace
+	repo := pe.Repo.Name
+
+	prs, err := spc.ListAllPullRequestsForFullNameRepo(pe.Repo.FullName, scm.PullRequestListOptions{Open: true, Page: 1})
+	if err != nil {
+		return errors.Wrapf(err, "listing open pull requests of %s", pe.Repo.FullName)
+	}
+
+	checker := newMergeChecker(spc, gitFactory, org, repo, log)
+	defer checker.clean()
+
+	var errs []string
+	for _, listed := range prs {
+		if listed.Closed || listed.Base.Ref != branch {
+			continue
+		}
+		pr, err := spc.GetPullRequest(org, repo, listed.Number)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("getting pull request #%d: %v", listed.Number, err))
+			continue
+		}
+		mergeable, known, err := checker.isMergeable(pr)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		if !known {
+			log.Infof("Mergeability of %s/%s#%d is unknown, skipping.", org, repo, pr.Number)
+			continue
+		}
+		if err := takeAction(spc, newPruner(org, repo, pr.Number), log, org, repo, pr, mergeable); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Errorf("failed to check the open pull requests against %s in %s: %s", branch, pe.Repo.FullName, strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// takeAction adds or removes the needs-rebase label and comment to match the mergeability of the pull request
+func takeAction(spc scmProviderClient, cp commentPruner, log *logrus.Entry, org, repo string, pr *scm.PullRequest, mergeable bool) error {
+	issueLabels, err := spc.GetIssueLabels(org, repo, pr.Number, true)
+	if err != nil {
+		return errors.Wrapf(err, "getting the labels of %s/%s#%d", org, repo, pr.Number)
+	}
+	hasLabel := scmprovider.HasLabel(labels.NeedsRebase, issueLabels)
+	botName, err := spc.BotName()
+	if err != nil {
+		return err
+	}
+	isStale := func(c *scm.Comment) bool {
+		return c.Author.Login == botName && strings.Contains(c.Body, needsRebaseMessage)
+	}
+
+	switch {
+	case hasLabel && mergeable:
+		log.Infof("Removing %q Label for %s/%s#%d", labels.NeedsRebase, org, repo, pr.Number)
+		if err := spc.RemoveLabel(org, repo, pr.Number, labels.NeedsRebase, true); err != nil {
+			return err
+		}
+		cp.PruneComments(true, isStale)
+	case !hasLabel && !mergeable:
+		log.Infof("Adding %q Label for %s/%s#%d", labels.NeedsRebase, org, repo, pr.Number)
+		if err := spc.AddLabel(org, repo, pr.Number, labels.NeedsRebase, true); err != nil {
+			return err
+		}
+		cp.PruneComments(true, isStale)
+		msg := plugins.FormatSimpleResponse(spc.QuoteAuthorForComment(pr.Author.Login), needsRebaseMessage)
+		return spc.CreateComment(org, repo, pr.Number, true, msg)
+	}
+	return nil
+}
+
+// mergeChecker determines whether pull requests can be merged into their base branch, using the mergeable state
+// reported by the provider when it is known and falling back to a trial merge in a local clone otherwise
+type mergeChecker struct {
+	spc        scmProviderClient
+	gitFactory gitv2.ClientFactory
+	org        string
+	repo       string
+	log        *logrus.Entry
+
+	// r is cloned lazily the first time a trial merge is needed
+	r gitv2.RepoClient
+}
+
+func newMergeChecker(spc scmProviderClient, gitFactory gitv2.ClientFactory, org, repo string, log *logrus.Entry) *mergeChecker {
+	return &mergeChecker{
+		spc:        spc,
+		gitFactory: gitFactory,
+		org:        org,
+		repo:       repo,
+		log:        log,
+	}
+}
+
+// isMergeable returns whether the pull request can be merged and whether its mergeability could be determined
+func (m *mergeChecker) isMergeable(pr *scm.PullRequest) (bool, bool, error) {
+	switch pr.MergeableState {
+	case scm.MergeableStateMergeable:
+		return true, true, nil
+	case scm.MergeableStateConflicting:
+		return false, true, nil
+	}
+	if m.gitFactory == nil {
+		return false, false, nil
+	}
+	if m.r == nil {
+		r, err := m.gitFactory.ClientFor(m.org, m.repo, nil)
+		if err != nil {
+			return false, false, errors.Wrapf(err, "cloning %s/%s", m.org, m.repo)
+		}
+		m.r = r
+		// the trial merge creates a commit which requires an identity
+		botName, err := m.spc.BotName()
+		if err != nil {
+			return false, false, err
+		}
+		if err := r.Config("user.name", botName); err != nil {
+			return false, false, err
+		}
+		if err := r.Config("user.email", botName+"@users.noreply.github.com"); err != nil {
+			return false, false, err
+		}
+	}
+	// the clone is shallow so the complete history is fetched for the merge base to be found
+	if err := m.r.FetchUnshallow(pr.Base.Ref); err != nil {
+		return false, false, errors.Wrapf(err, "fetching base branch of #%d", pr.Number)
+	}
+	if err := m.r.Checkout("FETCH_HEAD"); err != nil {
+		return false, false, errors.Wrapf(err, "checking out base branch of #%d", pr.Number)
+	}
+	if err := m.r.FetchUnshallow(fmt.Sprintf(m.spc.PRRefFmt(), pr.Number)); err != nil {
+		return false, false, errors.Wrapf(err, "fetching head of #%d", pr.Number)
+	}
+	merged, err := m.r.Merge("FETCH_HEAD")
+	if err != nil {
+		return false, false, errors.Wrapf(err, "trying to merge #%d", pr.Number)
+	}
+	return merged, true, nil
+}
+
+func (m *mergeChecker) clean() {
+	if m.r == nil {
+		return
+	}
+	if err := m.r.Clean(); err != nil {
+		m.log.WithError(err).Error("Error cleaning up repo.")
+	}
+}
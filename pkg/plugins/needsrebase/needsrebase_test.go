@@ -0,0 +1,293 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package needsrebase
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/git/localgit"
+	gitv2 "github.com/jenkins-x/lighthouse/pkg/git/v2"
+	"github.com/jenkins-x/lighthouse/pkg/labels"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSCMClient struct {
+	prs      map[int]*scm.PullRequest
+	labels   map[int][]string
+	added    []string
+	removed  []string
+	comments map[int][]string
+}
+
+func (f *fakeSCMClient) AddLabel(owner, repo string, number int, label string, pr bool) error {
+	f.added = append(f.added, fmt.Sprintf("#%d:%s", number, label))
+	return nil
+}
+
+func (f *fakeSCMClient) RemoveLabel(owner, repo string, number int, label string, pr bool) error {
+	f.removed = append(f.removed, fmt.Sprintf("#%d:%s", number, label))
+	return nil
+}
+
+func (f *fakeSCMClient) CreateComment(owner, repo string, number int, pr bool, comment string) error {
+	f.comments[number] = append(f.comments[number], comment)
+	return nil
+}
+
+func (f *fakeSCMClient) GetIssueLabels(org, repo string, number int, pr bool) ([]*scm.Label, error) {
+	var answer []*scm.Label
+	for _, l := range f.labels[number] {
+		answer = append(answer, &scm.Label{Name: l})
+	}
+	return answer, nil
+}
+
+func (f *fakeSCMClient) GetPullRequest(owner, repo string, number int) (*scm.PullRequest, error) {
+	return f.prs[number], nil
+}
+
+func (f *fakeSCMClient) ListAllPullRequestsForFullNameRepo(fullName string, opts scm.PullRequestListOptions) ([]*scm.PullRequest, error) {
+	var answer []*scm.PullRequest
+	for i := 1; i <= len(f.prs); i++ {
+		pr := *f.prs[i]
+		// the mergeable state is not included when listing pull requests
+		pr.MergeableState = scm.MergeableStateUnknown
+		answer = append(answer, &pr)
+	}
+	return answer, nil
+}
+
+func (f *fakeSCMClient) PRRefFmt() string {
+	return "refs/pull/%d/head"
+}
+
+func (f *fakeSCMClient) QuoteAuthorForComment(author string) string {
+	return author
+}
+
+func (f *fakeSCMClient) BotName() (string, error) {
+	return "bot", nil
+}
+
+type fakePruner struct {
+	pruned bool
+}
+
+func (f *fakePruner) PruneComments(pr bool, shouldPrune func(*scm.Comment) bool) {
+	f.pruned = true
+}
+
+type fakeGitClientFactory struct {
+	gitv2.ClientFactory
+	repo *fakeRepoClient
+}
+
+func (f *fakeGitClientFactory) ClientFor(org, repo string, sparseCheckoutPatterns []string) (gitv2.RepoClient, error) {
+	f.repo.clones++
+	return f.repo, nil
+}
+
+type fakeRepoClient struct {
+	gitv2.RepoClient
+	conflicting map[string]bool
+	fetched     string
+	clones      int
+	cleaned     bool
+}
+
+func (f *fakeRepoClient) Config(key, value string) error {
+	return nil
+}
+
+func (f *fakeRepoClient) FetchUnshallow(refspec string) error {
+	f.fetched = refspec
+	return nil
+}
+
+func (f *fakeRepoClient) Checkout(commitlike string) error {
+	return nil
+}
+
+func (f *fakeRepoClient) Merge(commitlike string) (bool, error) {
+	return !f.conflicting[f.fetched], nil
+}
+
+func (f *fakeRepoClient) Clean() error {
+	f.cleaned = true
+	return nil
+}
+
+func TestHandlePR(t *testing.T) {
+	testCases := []struct {
+		name            string
+		action          scm.Action
+		state           scm.MergeableState
+		labels          []string
+		expectedAdded   []string
+		expectedRemoved []string
+		expectComment   bool
+		expectPrune     bool
+	}{
+		{
+			name:   "mergeable",
+			action: scm.ActionOpen,
+			state:  scm.MergeableStateMergeable,
+		},
+		{
+			name:          "conflicting",
+			action:        scm.ActionSync,
+			state:         scm.MergeableStateConflicting,
+			expectedAdded: []string{"#1:" + labels.NeedsRebase},
+			expectComment: true,
+			expectPrune:   true,
+		},
+		{
+			name:   "conflicting and already labelled",
+			action: scm.ActionSync,
+			state:  scm.MergeableStateConflicting,
+			labels: []string{labels.NeedsRebase},
+		},
+		{
+			name:            "rebased",
+			action:          scm.ActionSync,
+			state:           scm.MergeableStateMergeable,
+			labels:          []string{labels.NeedsRebase},
+			expectedRemoved: []string{"#1:" + labels.NeedsRebase},
+			expectPrune:     true,
+		},
+		{
+			name:   "unknown without git client",
+			action: scm.ActionSync,
+			state:  scm.MergeableStateUnknown,
+		},
+		{
+			name:   "ignored action",
+			action: scm.ActionLabel,
+			state:  scm.MergeableStateConflicting,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			pr := &scm.PullRequest{Number: 1, Author: scm.User{Login: "author"}, MergeableState: tc.state}
+			spc := &fakeSCMClient{
+				prs:      map[int]*scm.PullRequest{1: pr},
+				labels:   map[int][]string{1: tc.labels},
+				comments: map[int][]string{},
+			}
+			cp := &fakePruner{}
+			pre := scm.PullRequestHook{
+				Action:      tc.action,
+				Repo:        scm.Repository{Namespace: "org", Name: "repo"},
+				PullRequest: *pr,
+			}
+			require.NoError(t, handlePR(spc, nil, cp, logrus.WithField("plugin", pluginName), pre))
+
+			assert.Equal(t, tc.expectedAdded, spc.added)
+			assert.Equal(t, tc.expectedRemoved, spc.removed)
+			assert.Equal(t, tc.expectPrune, cp.pruned)
+			if tc.expectComment {
+				require.Len(t, spc.comments[1], 1)
+				assert.Contains(t, spc.comments[1][0], "@author: "+needsRebaseMessage)
+			} else {
+				assert.Empty(t, spc.comments[1])
+			}
+		})
+	}
+}
+
+func TestHandlePushEvent(t *testing.T) {
+	spc := &fakeSCMClient{
+		prs: map[int]*scm.PullRequest{
+			1: {Number: 1, Base: scm.PullRequestBranch{Ref: "master"}, MergeableState: scm.MergeableStateConflicting},
+			2: {Number: 2, Base: scm.PullRequestBranch{Ref: "master"}},
+			3: {Number: 3, Base: scm.PullRequestBranch{Ref: "master"}},
+			4: {Number: 4, Base: scm.PullRequestBranch{Ref: "release-1.0"}, MergeableState: scm.MergeableStateConflicting},
+		},
+		labels:   map[int][]string{3: {labels.NeedsRebase}},
+		comments: map[int][]string{},
+	}
+	repoClient := &fakeRepoClient{conflicting: map[string]bool{"refs/pull/2/head": true}}
+	pruners := map[int]*fakePruner{}
+	newPruner := func(org, repo string, number int) commentPruner {
+		pruners[number] = &fakePruner{}
+		return pruners[number]
+	}
+	pe := scm.PushHook{
+		Ref:  "refs/heads/master",
+		Repo: scm.Repository{Namespace: "org", Name: "repo", FullName: "org/repo"},
+	}
+	require.NoError(t, handlePushEvent(spc, &fakeGitClientFactory{repo: repoClient}, newPruner, logrus.WithField("plugin", pluginName), pe))
+
+	assert.Equal(t, []string{"#1:" + labels.NeedsRebase, "#2:" + labels.NeedsRebase}, spc.added)
+	assert.Equal(t, []string{"#3:" + labels.NeedsRebase}, spc.removed)
+	assert.Len(t, spc.comments[1], 1)
+	assert.Len(t, spc.comments[2], 1)
+	assert.Empty(t, spc.comments[4])
+	assert.True(t, pruners[3].pruned)
+	assert.Equal(t, 1, repoClient.clones, "the repository should be cloned once for all the trial merges")
+	assert.True(t, repoClient.cleaned)
+
+	// pushing tags does not trigger any check
+	spc.added = nil
+	pe.Ref = "refs/tags/v1.0.0"
+	require.NoError(t, handlePushEvent(spc, &fakeGitClientFactory{repo: repoClient}, newPruner, logrus.WithField("plugin", pluginName), pe))
+	assert.Empty(t, spc.added)
+}
+
+func TestMergeCheckerMergeBaseBeyondCloneDepth(t *testing.T) {
+	lg, _, err := localgit.New()
+	require.NoError(t, err)
+	defer lg.Clean() //nolint:errcheck
+	require.NoError(t, lg.MakeFakeRepo("org", "repo"))
+	require.NoError(t, lg.CheckoutNewBranch("org", "repo", "base"))
+	require.NoError(t, lg.CheckoutNewBranch("org", "repo", "pr"))
+	require.NoError(t, lg.AddCommit("org", "repo", map[string][]byte{"pr": []byte("pr")}))
+	require.NoError(t, lg.Checkout("org", "repo", "base"))
+	// the base branch moves further than the depth of the clone
+	for i := 0; i < 20; i++ {
+		require.NoError(t, lg.AddCommit("org", "repo", map[string][]byte{"base": []byte(fmt.Sprint(i))}))
+	}
+	updateRef := exec.Command(lg.Git, "update-ref", "refs/pull/1/head", "pr") // #nosec
+	updateRef.Dir = filepath.Join(lg.Dir, "org", "repo")
+	out, err := updateRef.CombinedOutput()
+	require.NoError(t, err, string(out))
+
+	// the depth of the clone is ignored for local paths but not for file:// URLs
+	t.Setenv("GIT_CLONE_PATH_PREFIX", lg.Dir)
+	cacheDir := t.TempDir()
+	gitFactory, err := gitv2.NewNoMirrorClientFactory(func(o *gitv2.ClientFactoryOpts) {
+		o.Scheme = "file"
+		o.Host = "localhost"
+		o.CacheDirBase = &cacheDir
+	})
+	require.NoError(t, err)
+
+	spc := &fakeSCMClient{}
+	m := newMergeChecker(spc, gitFactory, "org", "repo", logrus.WithField("plugin", pluginName))
+	defer m.clean()
+	pr := &scm.PullRequest{Number: 1, Base: scm.PullRequestBranch{Ref: "base"}}
+	mergeable, known, err := m.isMergeable(pr)
+	require.NoError(t, err)
+	assert.True(t, known)
+	assert.True(t, mergeable, "the pull request should be merged with the complete history of the base branch")
+}
@@ -17,6 +17,8 @@ type Plugin struct {
 	ReviewEventHandler      ReviewEventHandler
 	StatusEventHandler      StatusEventHandler
 	DeploymentStatusHandler DeploymentStatusHandler
+	TagEventHandler         TagEventHandler
+	ReleaseEventHandler     ReleaseEventHandler
 	GenericCommentHandler   GenericCommentHandler
 	Commands                []Command
 }
@@ -70,6 +72,12 @@ func (plugin Plugin) getEvents() []string {
 	if plugin.StatusEventHandler != nil {
 		events = append(events, "status")
 	}
+	if plugin.TagEventHandler != nil {
+		events = append(events, "tag")
+	}
+	if plugin.ReleaseEventHandler != nil {
+		events = append(events, "release")
+	}
 	if plugin.GenericCommentHandler != nil {
 		events = append(events, "GenericCommentEvent (any event for user text)")
 	}
@@ -29,6 +29,7 @@ import (
 	"github.com/jenkins-x/lighthouse/pkg/commentpruner"
 	"github.com/jenkins-x/lighthouse/pkg/config"
 	"github.com/jenkins-x/lighthouse/pkg/git"
+	gitv2 "github.com/jenkins-x/lighthouse/pkg/git/v2"
 	"github.com/jenkins-x/lighthouse/pkg/launcher"
 	"github.com/jenkins-x/lighthouse/pkg/pluginhelp"
 	"github.com/jenkins-x/lighthouse/pkg/repoowners"
@@ -54,17 +55,23 @@ type HelpProvider func(config *Configuration, enabledRepos []string) (*pluginhel
 // ConfigHelpProvider defines the function type that constructs help about a plugin configuration.
 type ConfigHelpProvider func(config *Configuration, enabledRepos []string) (map[string]string, error)
 
-// IssueHandler defines the function contract for a scm.Issue handler.
-type IssueHandler func(Agent, scm.Issue) error
+// IssueHandler defines the function contract for a scm.IssueHook handler.
+type IssueHandler func(Agent, scm.IssueHook) error
 
 // PullRequestHandler defines the function contract for a scm.PullRequest handler.
 type PullRequestHandler func(Agent, scm.PullRequestHook) error
 
-// StatusEventHandler defines the function contract for a scm.Status handler.
-type StatusEventHandler func(Agent, scm.Status) error
+// StatusEventHandler defines the function contract for a scm.StatusHook handler.
+type StatusEventHandler func(Agent, scm.StatusHook) error
 
 type DeploymentStatusHandler func(Agent, scm.DeploymentStatusHook) error
 
+// TagEventHandler defines the function contract for a scm.TagHook handler.
+type TagEventHandler func(Agent, scm.TagHook) error
+
+// ReleaseEventHandler defines the function contract for a scmprovider.ReleaseEvent handler.
+type ReleaseEventHandler func(Agent, scmprovider.ReleaseEvent) error
+
 // PushEventHandler defines the function contract for a scm.PushHook handler.
 type PushEventHandler func(Agent, scm.PushHook) error
 
@@ -96,6 +103,9 @@ type Agent struct {
 	KubernetesClient  kubernetes.Interface
 	LighthouseClient  lighthouseclient.LighthouseJobInterface
 	ServerURL         *url.URL
+
+	// GitClientFactory creates clones of repositories which can be modified and pushed, may be nil
+	GitClientFactory gitv2.ClientFactory
 	/*
 		SlackClient      *slack.Client
 	*/
@@ -126,6 +136,7 @@ func NewAgent(configAgent *config.Agent, pluginConfigAgent *ConfigAgent, clientA
 		LauncherClient:    clientAgent.LauncherClient,
 		LighthouseClient:  clientAgent.LighthouseClient,
 		ServerURL:         serverURL,
+		GitClientFactory:  clientAgent.GitClientFactory,
 
 		/*
 			SlackClient:   clientAgent.SlackClient,
@@ -134,6 +145,7 @@ func NewAgent(configAgent *config.Agent, pluginConfigAgent *ConfigAgent, clientA
 			clientAgent.GitClient, scmClient,
 			prowConfig, pluginConfig.MDYAMLEnabled,
 			pluginConfig.SkipCollaborators,
+			pluginConfig.CodeOwnersEnabled,
 		),
 		Config:       prowConfig,
 		PluginConfig: pluginConfig,
@@ -168,6 +180,7 @@ type ClientAgent struct {
 	GitClient        git.Client
 	LauncherClient   launcher.PipelineLauncher
 	LighthouseClient lighthouseclient.LighthouseJobInterface
+	GitClientFactory gitv2.ClientFactory
 
 	/*	SlackClient      *slack.Client
 	 */
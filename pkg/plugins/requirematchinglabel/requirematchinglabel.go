@@ -0,0 +1,220 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package requirematchinglabel adds a label to issues and PRs which do not
+// have any label matching a regular expression, and removes it again once a
+// matching label has been applied.
+package requirematchinglabel
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/sirupsen/logrus"
+)
+
+const pluginName = "require-matching-label"
+
+var (
+	// sleep is overridden in the tests to skip the grace period
+	sleep = time.Sleep
+)
+
+type scmProviderClient interface {
+	AddLabel(owner, repo string, number int, label string, pr bool) error
+	RemoveLabel(owner, repo string, number int, label string, pr bool) error
+	CreateComment(owner, repo string, number int, pr bool, comment string) error
+	GetIssueLabels(org, repo string, number int, pr bool) ([]*scm.Label, error)
+	QuoteAuthorForComment(string) string
+}
+
+type commentPruner interface {
+	PruneComments(pr bool, shouldPrune func(*scm.Comment) bool)
+}
+
+func init() {
+	plugins.RegisterPlugin(
+		pluginName,
+		plugins.Plugin{
+			Description:        "The require-matching-label plugin is a configurable plugin that applies a label to issues and/or PRs that do not have any labels matching a regular expression. An example of this is applying a 'needs-sig' label to all issues that do not have a 'sig/*' label. This plugin can have multiple configurations to provide this kind of behavior for multiple different label sets.",
+			ConfigHelpProvider: configHelp,
+			IssueHandler:       handleIssue,
+			PullRequestHandler: handlePullRequest,
+		},
+	)
+}
+
+func configHelp(config *plugins.Configuration, enabledRepos []string) (map[string]string, error) {
+	descs := make(map[string]string)
+	for _, repo := range enabledRepos {
+		parts := strings.Split(repo, "/")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid repo in enabledRepos: %q", repo)
+		}
+		var descriptions []string
+		for _, cfg := range config.RequireMatchingLabel {
+			if cfg.Org == parts[0] && (cfg.Repo == "" || cfg.Repo == parts[1]) {
+				descriptions = append(descriptions, cfg.Describe())
+			}
+		}
+		descs[repo] = "The plugin has the following configurations:\n<ul><li>" + strings.Join(descriptions, "</li><li>") + "</li></ul>"
+	}
+	return descs, nil
+}
+
+type event struct {
+	org    string
+	repo   string
+	number int
+	author string
+	// branch is empty for issues and the base branch for PRs
+	branch string
+	// label is the label that was added or removed, empty for other events
+	label string
+	// opened is true if the issue or PR has just been opened or reopened
+	opened bool
+}
+
+func (e *event) isPR() bool {
+	return e.branch != ""
+}
+
+func handleIssue(pc plugins.Agent, ie scm.IssueHook) error {
+	if ie.Issue.PullRequest != nil {
+		// PRs are handled by handlePullRequest
+		return nil
+	}
+	if ie.Action != scm.ActionOpen && ie.Action != scm.ActionReopen && ie.Action != scm.ActionLabel && ie.Action != scm.ActionUnlabel {
+		return nil
+	}
+	e := &event{
+		org:    ie.Repo.Namespace,
+		repo:   ie.Repo.Name,
+		number: ie.Issue.Number,
+		author: ie.Issue.Author.Login,
+		opened: ie.Action == scm.ActionOpen || ie.Action == scm.ActionReopen,
+	}
+	cp, err := pc.CommentPruner()
+	if err != nil {
+		return err
+	}
+	return handle(pc.Logger, pc.SCMProviderClient, cp, pc.PluginConfig.RequireMatchingLabel, e)
+}
+
+func handlePullRequest(pc plugins.Agent, pre scm.PullRequestHook) error {
+	if pre.Action != scm.ActionOpen && pre.Action != scm.ActionReopen && pre.Action != scm.ActionLabel && pre.Action != scm.ActionUnlabel {
+		return nil
+	}
+	e := &event{
+		org:    pre.Repo.Namespace,
+		repo:   pre.Repo.Name,
+		number: pre.PullRequest.Number,
+		author: pre.PullRequest.Author.Login,
+		branch: pre.PullRequest.Base.Ref,
+		label:  pre.Label.Name,
+		opened: pre.Action == scm.ActionOpen || pre.Action == scm.ActionReopen,
+	}
+	cp, err := pc.CommentPruner()
+	if err != nil {
+		return err
+	}
+	return handle(pc.Logger, pc.SCMProviderClient, cp, pc.PluginConfig.RequireMatchingLabel, e)
+}
+
+// matchingConfigs filters irrelevant RequireMatchingLabel configs from
+// the list of all configs.
+// `branch` should be empty for Issues and non-empty for PRs.
+// `label` should be omitted in the case of 'open' events.
+func matchingConfigs(org, repo, branch, label string, allConfigs []plugins.RequireMatchingLabel) []plugins.RequireMatchingLabel {
+	var filtered []plugins.RequireMatchingLabel
+	for _, cfg := range allConfigs {
+		// Check if the config applies to this issue type.
+		if (branch == "" && !cfg.Issues) || (branch != "" && !cfg.PRs) {
+			continue
+		}
+		// Check if the config applies to this 'org[/repo][/branch]'.
+		if org != cfg.Org ||
+			(cfg.Repo != "" && cfg.Repo != repo) ||
+			(cfg.Branch != "" && branch != "" && cfg.Branch != branch) {
+			continue
+		}
+		// If we are reacting to a label event, see if it is relevant.
+		if label != "" && !cfg.Re.MatchString(label) {
+			continue
+		}
+		filtered = append(filtered, cfg)
+	}
+	return filtered
+}
+
+func handle(log *logrus.Entry, spc scmProviderClient, cp commentPruner, configs []plugins.RequireMatchingLabel, e *event) error {
+	// Find any configs that may be relevant to this event.
+	matchConfigs := matchingConfigs(e.org, e.repo, e.branch, e.label, configs)
+	if len(matchConfigs) == 0 {
+		return nil
+	}
+
+	if e.opened {
+		// If we are reacting to a newly opened or reopened item, sleep a bit before
+		// fetching labels in order to avoid races with other automation applying labels.
+		var gracePeriod time.Duration
+		for _, cfg := range matchConfigs {
+			if cfg.GracePeriodDuration > gracePeriod {
+				gracePeriod = cfg.GracePeriodDuration
+			}
+		}
+		sleep(gracePeriod)
+	}
+
+	labels, err := spc.GetIssueLabels(e.org, e.repo, e.number, e.isPR())
+	if err != nil {
+		return fmt.Errorf("error getting the issue or pr's labels: %v", err)
+	}
+	for _, cfg := range matchConfigs {
+		hasMissingLabel := false
+		hasMatchingLabel := false
+		for _, label := range labels {
+			hasMissingLabel = hasMissingLabel || label.Name == cfg.MissingLabel
+			hasMatchingLabel = hasMatchingLabel || cfg.Re.MatchString(label.Name)
+		}
+
+		if hasMatchingLabel && hasMissingLabel {
+			if err := spc.RemoveLabel(e.org, e.repo, e.number, cfg.MissingLabel, e.isPR()); err != nil {
+				log.WithError(err).Errorf("Failed to remove %q label.", cfg.MissingLabel)
+			}
+			if cfg.MissingComment != "" {
+				missingComment := cfg.MissingComment
+				cp.PruneComments(e.isPR(), func(comment *scm.Comment) bool {
+					return strings.Contains(comment.Body, missingComment)
+				})
+			}
+		} else if !hasMatchingLabel && !hasMissingLabel {
+			if err := spc.AddLabel(e.org, e.repo, e.number, cfg.MissingLabel, e.isPR()); err != nil {
+				log.WithError(err).Errorf("Failed to add %q label.", cfg.MissingLabel)
+			}
+			if cfg.MissingComment != "" {
+				msg := plugins.FormatSimpleResponse(spc.QuoteAuthorForComment(e.author), cfg.MissingComment)
+				if err := spc.CreateComment(e.org, e.repo, e.number, e.isPR(), msg); err != nil {
+					log.WithError(err).Error("Failed to create comment.")
+				}
+			}
+		}
+	}
+	return nil
+}
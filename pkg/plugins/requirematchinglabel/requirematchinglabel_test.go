@@ -0,0 +1,184 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package requirematchinglabel
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClient struct {
+	labels   []string
+	added    []string
+	removed  []string
+	comments []string
+}
+
+func (fc *fakeClient) AddLabel(owner, repo string, number int, label string, pr bool) error {
+	fc.added = append(fc.added, label)
+	fc.labels = append(fc.labels, label)
+	return nil
+}
+
+func (fc *fakeClient) RemoveLabel(owner, repo string, number int, label string, pr bool) error {
+	fc.removed = append(fc.removed, label)
+	for i, l := range fc.labels {
+		if l == label {
+			fc.labels = append(fc.labels[:i], fc.labels[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (fc *fakeClient) CreateComment(owner, repo string, number int, pr bool, comment string) error {
+	fc.comments = append(fc.comments, comment)
+	return nil
+}
+
+func (fc *fakeClient) GetIssueLabels(org, repo string, number int, pr bool) ([]*scm.Label, error) {
+	var labels []*scm.Label
+	for _, l := range fc.labels {
+		labels = append(labels, &scm.Label{Name: l})
+	}
+	return labels, nil
+}
+
+func (fc *fakeClient) QuoteAuthorForComment(author string) string {
+	return author
+}
+
+type fakePruner struct {
+	pruned bool
+}
+
+func (fp *fakePruner) PruneComments(pr bool, shouldPrune func(*scm.Comment) bool) {
+	fp.pruned = true
+}
+
+func TestHandle(t *testing.T) {
+	var slept time.Duration
+	sleep = func(d time.Duration) { slept += d }
+	defer func() { sleep = time.Sleep }()
+
+	configs := []plugins.RequireMatchingLabel{
+		{
+			Org:                 "org",
+			Repo:                "repo",
+			PRs:                 true,
+			Regexp:              "^kind/",
+			Re:                  regexp.MustCompile("^kind/"),
+			MissingLabel:        "needs-kind",
+			MissingComment:      "Please add a kind label.",
+			GracePeriodDuration: 5 * time.Second,
+		},
+		{
+			Org:                 "org",
+			Issues:              true,
+			Regexp:              "^sig/",
+			Re:                  regexp.MustCompile("^sig/"),
+			MissingLabel:        "needs-sig",
+			GracePeriodDuration: time.Second,
+		},
+	}
+
+	testCases := []struct {
+		name            string
+		event           event
+		labels          []string
+		expectedAdded   []string
+		expectedRemoved []string
+		expectComment   bool
+		expectPrune     bool
+		expectedSleep   time.Duration
+	}{
+		{
+			name:          "opened PR without matching label",
+			event:         event{org: "org", repo: "repo", number: 1, author: "user", branch: "master", opened: true},
+			expectedAdded: []string{"needs-kind"},
+			expectComment: true,
+			expectedSleep: 5 * time.Second,
+		},
+		{
+			name:          "opened PR with matching label",
+			event:         event{org: "org", repo: "repo", number: 1, author: "user", branch: "master", opened: true},
+			labels:        []string{"kind/bug"},
+			expectedSleep: 5 * time.Second,
+		},
+		{
+			name:            "matching label added to PR",
+			event:           event{org: "org", repo: "repo", number: 1, author: "user", branch: "master", label: "kind/bug"},
+			labels:          []string{"kind/bug", "needs-kind"},
+			expectedRemoved: []string{"needs-kind"},
+			expectPrune:     true,
+		},
+		{
+			name:          "matching label removed from PR",
+			event:         event{org: "org", repo: "repo", number: 1, author: "user", branch: "master", label: "kind/bug"},
+			expectedAdded: []string{"needs-kind"},
+			expectComment: true,
+		},
+		{
+			name:   "unrelated label added to PR",
+			event:  event{org: "org", repo: "repo", number: 1, author: "user", branch: "master", label: "lgtm"},
+			labels: []string{"lgtm"},
+		},
+		{
+			name:  "PR in another repo",
+			event: event{org: "org", repo: "other", number: 1, author: "user", branch: "master", opened: true},
+		},
+		{
+			name:          "opened issue without matching label",
+			event:         event{org: "org", repo: "other", number: 2, author: "user", opened: true},
+			expectedAdded: []string{"needs-sig"},
+			expectedSleep: time.Second,
+		},
+		{
+			name:            "issue labelled",
+			event:           event{org: "org", repo: "other", number: 2, author: "user"},
+			labels:          []string{"sig/testing", "needs-sig"},
+			expectedRemoved: []string{"needs-sig"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			slept = 0
+			fc := &fakeClient{labels: tc.labels}
+			fp := &fakePruner{}
+			e := tc.event
+			require.NoError(t, handle(logrus.WithField("plugin", pluginName), fc, fp, configs, &e))
+
+			assert.Equal(t, tc.expectedAdded, fc.added)
+			assert.Equal(t, tc.expectedRemoved, fc.removed)
+			if tc.expectComment {
+				require.Len(t, fc.comments, 1)
+				assert.Contains(t, fc.comments[0], "Please add a kind label.")
+			} else {
+				assert.Empty(t, fc.comments)
+			}
+			assert.Equal(t, tc.expectPrune, fp.pruned)
+			assert.Equal(t, tc.expectedSleep, slept)
+		})
+	}
+}
@@ -0,0 +1,181 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package requiresig adds the `needs-sig` label to issues which do not have
+// any `sig/*` or `committee/*` label, and removes it once one is applied.
+package requiresig
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/labels"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	pluginName = "require-sig"
+
+	needsSIGMessage = "There are no sig labels on this issue. Please add a sig label."
+	needsSIGDetails = `A sig label can be added by either:
+
+1. mentioning a sig: ` + "`@<org>/sig-<group-name>-<group-suffix>`" + `
+    e.g., ` + "`@<org>/sig-contributor-experience-<group-suffix>`" + ` to notify the contributor experience sig, OR
+
+2. specifying the label manually: ` + "`/label sig/<group-name>`" + `
+    e.g., ` + "`/label sig/scalability`" + ` to apply the ` + "`sig/scalability`" + ` label
+
+Note: Method 1 will trigger an email to the group. See the [group list](%s).
+The ` + "`<group-suffix>`" + ` in method 1 has to be replaced with one of these: _**bugs, feature-requests, pr-reviews, test-failures, proposals**_.`
+)
+
+var (
+	labelPrefixes = []string{"sig/", "committee/"}
+
+	sigCommandRe = regexp.MustCompile(`(?m)^/(sig|label sig/)\s*(.*)$`)
+)
+
+type scmProviderClient interface {
+	BotName() (string, error)
+	AddLabel(owner, repo string, number int, label string, pr bool) error
+	RemoveLabel(owner, repo string, number int, label string, pr bool) error
+	CreateComment(owner, repo string, number int, pr bool, comment string) error
+	GetIssueLabels(org, repo string, number int, pr bool) ([]*scm.Label, error)
+	QuoteAuthorForComment(string) string
+}
+
+type commentPruner interface {
+	PruneComments(pr bool, shouldPrune func(*scm.Comment) bool)
+}
+
+func init() {
+	plugins.RegisterPlugin(
+		pluginName,
+		plugins.Plugin{
+			Description:        fmt.Sprintf("When a new issue is opened the require-sig plugin adds the %q label and leaves a comment requesting that a SIG (Special Interest Group) label be added to the issue. SIG labels are labels that have one of the following prefixes: %q.\n<br>Once a SIG label has been added to an issue, this plugin removes the %q label and deletes the comment it made previously.", labels.NeedsSig, labelPrefixes, labels.NeedsSig),
+			ConfigHelpProvider: configHelp,
+			IssueHandler:       handleIssue,
+		},
+	)
+}
+
+func configHelp(config *plugins.Configuration, enabledRepos []string) (map[string]string, error) {
+	url := config.RequireSIG.GroupListURL
+	if url == "" {
+		url = "<no url provided>"
+	}
+	return map[string]string{
+			"": fmt.Sprintf("The comment the plugin creates includes this link to a list of the existing groups: %s", url),
+		},
+		nil
+}
+
+func handleIssue(pc plugins.Agent, ie scm.IssueHook) error {
+	cp, err := pc.CommentPruner()
+	if err != nil {
+		return err
+	}
+	return handle(pc.Logger, pc.SCMProviderClient, cp, &ie, pc.PluginConfig.SigMention.Re, pc.PluginConfig.RequireSIG.GroupListURL)
+}
+
+func isSigLabel(label string) bool {
+	for i := range labelPrefixes {
+		if strings.HasPrefix(label, labelPrefixes[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasSigLabel(issueLabels []*scm.Label) bool {
+	for _, label := range issueLabels {
+		if isSigLabel(label.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+func shouldReact(mentionRe *regexp.Regexp, ie *scm.IssueHook) bool {
+	// Ignore PRs and closed issues.
+	if ie.Issue.PullRequest != nil || ie.Issue.Closed {
+		return false
+	}
+
+	switch ie.Action {
+	case scm.ActionOpen:
+		// Don't react if the new issue has a sig command or sig team mention.
+		return (mentionRe == nil || !mentionRe.MatchString(ie.Issue.Body)) && !sigCommandRe.MatchString(ie.Issue.Body)
+	case scm.ActionLabel, scm.ActionUnlabel:
+		// The hook does not tell which label changed so the labels are fetched again.
+		return true
+	default:
+		return false
+	}
+}
+
+func handle(log *logrus.Entry, spc scmProviderClient, cp commentPruner, ie *scm.IssueHook, mentionRe *regexp.Regexp, groupListURL string) error {
+	// Ignore PRs, closed issues, and events that aren't new issues or label
+	// changes.
+	if !shouldReact(mentionRe, ie) {
+		return nil
+	}
+
+	org := ie.Repo.Namespace
+	repo := ie.Repo.Name
+	number := ie.Issue.Number
+
+	issueLabels, err := spc.GetIssueLabels(org, repo, number, false)
+	if err != nil {
+		return fmt.Errorf("error getting the labels of issue %d: %v", number, err)
+	}
+	hasSigLabel := hasSigLabel(issueLabels)
+	hasNeedsSigLabel := scmprovider.HasLabel(labels.NeedsSig, issueLabels)
+
+	if hasSigLabel && hasNeedsSigLabel {
+		if err := spc.RemoveLabel(org, repo, number, labels.NeedsSig, false); err != nil {
+			log.WithError(err).Errorf("Failed to remove %s label.", labels.NeedsSig)
+		}
+		botName, err := spc.BotName()
+		if err != nil {
+			return fmt.Errorf("error getting bot name: %v", err)
+		}
+		cp.PruneComments(false, shouldPrune(botName))
+	} else if !hasSigLabel && !hasNeedsSigLabel {
+		if err := spc.AddLabel(org, repo, number, labels.NeedsSig, false); err != nil {
+			log.WithError(err).Errorf("Failed to add %s label.", labels.NeedsSig)
+		}
+		msg := plugins.FormatResponse(spc.QuoteAuthorForComment(ie.Issue.Author.Login), needsSIGMessage, fmt.Sprintf(needsSIGDetails, groupListURL))
+		if err := spc.CreateComment(org, repo, number, false, msg); err != nil {
+			log.WithError(err).Error("Failed to create comment.")
+		}
+	}
+	return nil
+}
+
+// shouldPrune finds comments left by this plugin.
+func shouldPrune(botName string) func(*scm.Comment) bool {
+	return func(comment *scm.Comment) bool {
+		if comment.Author.Login != botName {
+			return false
+		}
+		return strings.Contains(comment.Body, needsSIGMessage)
+	}
+}
@@ -0,0 +1,196 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package requiresig
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/labels"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	helpWanted        = "help-wanted"
+	sigApps           = "sig/apps"
+	committeeSteering = "committee/steering"
+)
+
+type fakeClient struct {
+	labels   []string
+	added    []string
+	removed  []string
+	comments []string
+}
+
+func (fc *fakeClient) BotName() (string, error) {
+	return "bot", nil
+}
+
+func (fc *fakeClient) AddLabel(owner, repo string, number int, label string, pr bool) error {
+	fc.added = append(fc.added, label)
+	return nil
+}
+
+func (fc *fakeClient) RemoveLabel(owner, repo string, number int, label string, pr bool) error {
+	fc.removed = append(fc.removed, label)
+	return nil
+}
+
+func (fc *fakeClient) CreateComment(owner, repo string, number int, pr bool, comment string) error {
+	fc.comments = append(fc.comments, comment)
+	return nil
+}
+
+func (fc *fakeClient) GetIssueLabels(org, repo string, number int, pr bool) ([]*scm.Label, error) {
+	var issueLabels []*scm.Label
+	for _, l := range fc.labels {
+		issueLabels = append(issueLabels, &scm.Label{Name: l})
+	}
+	return issueLabels, nil
+}
+
+func (fc *fakeClient) QuoteAuthorForComment(author string) string {
+	return author
+}
+
+type fakePruner struct {
+	pruned []*scm.Comment
+}
+
+func (fp *fakePruner) PruneComments(pr bool, shouldPrune func(*scm.Comment) bool) {
+	comments := []*scm.Comment{
+		{Author: scm.User{Login: "bot"}, Body: "@user: " + needsSIGMessage},
+		{Author: scm.User{Login: "bot"}, Body: "something else"},
+		{Author: scm.User{Login: "user"}, Body: needsSIGMessage},
+	}
+	for _, c := range comments {
+		if shouldPrune(c) {
+			fp.pruned = append(fp.pruned, c)
+		}
+	}
+}
+
+func TestHandle(t *testing.T) {
+	mentionRe := regexp.MustCompile(`(?m)@kubernetes/sig-([\w-]*)-(misc|test-failures|bugs|feature-requests|proposals|pr-reviews|api-reviews)`)
+
+	testCases := []struct {
+		name            string
+		action          scm.Action
+		isPR            bool
+		closed          bool
+		body            string
+		labels          []string
+		expectedAdded   []string
+		expectedRemoved []string
+		expectComment   bool
+		expectPrune     bool
+	}{
+		{
+			name:          "new issue without sig label",
+			action:        scm.ActionOpen,
+			body:          "I found a bug.",
+			expectedAdded: []string{labels.NeedsSig},
+			expectComment: true,
+		},
+		{
+			name:   "new issue with sig mention",
+			action: scm.ActionOpen,
+			body:   "@kubernetes/sig-apps-bugs this is broken",
+		},
+		{
+			name:   "new issue with sig command",
+			action: scm.ActionOpen,
+			body:   "this is broken\n/sig apps",
+		},
+		{
+			name:   "new issue already labelled",
+			action: scm.ActionOpen,
+			labels: []string{committeeSteering},
+		},
+		{
+			name:   "new pull request",
+			action: scm.ActionOpen,
+			isPR:   true,
+		},
+		{
+			name:   "closed issue",
+			action: scm.ActionLabel,
+			closed: true,
+			labels: []string{helpWanted},
+		},
+		{
+			name:            "sig label added",
+			action:          scm.ActionLabel,
+			labels:          []string{sigApps, labels.NeedsSig},
+			expectedRemoved: []string{labels.NeedsSig},
+			expectPrune:     true,
+		},
+		{
+			name:          "sig label removed",
+			action:        scm.ActionUnlabel,
+			labels:        []string{helpWanted},
+			expectedAdded: []string{labels.NeedsSig},
+			expectComment: true,
+		},
+		{
+			name:   "unrelated label added",
+			action: scm.ActionLabel,
+			labels: []string{helpWanted, labels.NeedsSig},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fc := &fakeClient{labels: tc.labels}
+			fp := &fakePruner{}
+			ie := &scm.IssueHook{
+				Action: tc.action,
+				Repo:   scm.Repository{Namespace: "org", Name: "repo"},
+				Issue: scm.Issue{
+					Number: 5,
+					Body:   tc.body,
+					Closed: tc.closed,
+					Author: scm.User{Login: "user"},
+				},
+			}
+			if tc.isPR {
+				ie.Issue.PullRequest = &scm.PullRequest{}
+			}
+			require.NoError(t, handle(logrus.WithField("plugin", pluginName), fc, fp, ie, mentionRe, "https://example.com/sigs"))
+
+			assert.Equal(t, tc.expectedAdded, fc.added)
+			assert.Equal(t, tc.expectedRemoved, fc.removed)
+			if tc.expectComment {
+				require.Len(t, fc.comments, 1)
+				assert.True(t, strings.HasPrefix(fc.comments[0], "@user: "+needsSIGMessage))
+				assert.Contains(t, fc.comments[0], "https://example.com/sigs")
+			} else {
+				assert.Empty(t, fc.comments)
+			}
+			if tc.expectPrune {
+				require.Len(t, fp.pruned, 1)
+				assert.Equal(t, "bot", fp.pruned[0].Author.Login)
+			} else {
+				assert.Empty(t, fp.pruned)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package trigger
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/errorutil"
+	"github.com/jenkins-x/lighthouse/pkg/jobutil"
+	"github.com/jenkins-x/lighthouse/pkg/util"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type lighthouseJobClient interface {
+	List(ctx context.Context, opts metav1.ListOptions) (*v1alpha1.LighthouseJobList, error)
+	UpdateStatus(ctx context.Context, lighthouseJob *v1alpha1.LighthouseJob, opts metav1.UpdateOptions) (*v1alpha1.LighthouseJob, error)
+}
+
+// abortSupersededJobs moves the presubmits of the pull request that are still running against
+// a previous head commit to the aborted state, the engines then cancel the underlying pipelines
+func abortSupersededJobs(c Client, pr *scm.PullRequest) error {
+	if c.LighthouseClient == nil {
+		return nil
+	}
+	org, repo, _ := orgRepoAuthor(*pr)
+	// the repository label may be sanitized or dropped so the jobs of the repository are matched on their refs
+	selector := fmt.Sprintf("%s=%s,%s=%d,%s=%s", util.OrgLabel, strings.ToLower(org), util.PullLabel, pr.Number, job.LighthouseJobTypeLabel, job.PresubmitJob)
+	jobs, err := c.LighthouseClient.List(context.TODO(), metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return fmt.Errorf("failed to list LighthouseJobs for %s/%s#%d: %w", org, repo, pr.Number, err)
+	}
+
+	var errors []error
+	for i := range jobs.Items {
+		lhjob := &jobs.Items[i]
+		if !isSuperseded(lhjob, pr) {
+			continue
+		}
+		c.Logger.WithFields(jobutil.LighthouseJobFields(lhjob)).
+			WithField("from", lhjob.Status.State).
+			WithField("to", v1alpha1.AbortedState).Info("Aborting superseded LighthouseJob.")
+		lhjob.Status.State = v1alpha1.AbortedState
+		lhjob.Status.Description = fmt.Sprintf("Aborted as superseded by %s.", pr.Head.Sha)
+		if _, err := c.LighthouseClient.UpdateStatus(context.TODO(), lhjob, metav1.UpdateOptions{}); err != nil {
+			errors = append(errors, fmt.Errorf("failed to abort LighthouseJob %s: %w", lhjob.Name, err))
+		}
+	}
+	return errorutil.NewAggregate(errors...)
+}
+
+// isSuperseded returns true if the job is an unfinished presubmit for the pull request built from a different head commit
+func isSuperseded(lhjob *v1alpha1.LighthouseJob, pr *scm.PullRequest) bool {
+	if lhjob.Spec.Type != job.PresubmitJob || lhjob.Complete() || v1alpha1.IsTerminalPipelineState(lhjob.Status.State) {
+		return false
+	}
+	refs := lhjob.Spec.Refs
+	if refs == nil || len(refs.Pulls) == 0 {
+		return false
+	}
+	if !strings.EqualFold(refs.Org, pr.Base.Repo.Namespace) || refs.Repo != pr.Base.Repo.Name || refs.Pulls[0].Number != pr.Number {
+		return false
+	}
+	return refs.Pulls[0].SHA != pr.Head.Sha
+}
@@ -0,0 +1,174 @@
+package trigger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/client/clientset/versioned/fake"
+	"github.com/jenkins-x/lighthouse/pkg/config"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/jobutil"
+	fakelauncher "github.com/jenkins-x/lighthouse/pkg/launcher/fake"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	fake2 "github.com/jenkins-x/lighthouse/pkg/scmprovider/fake"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestAbortSupersededJobs(t *testing.T) {
+	newJob := func(name string, number int, sha string, state v1alpha1.PipelineState) *v1alpha1.LighthouseJob {
+		spec := v1alpha1.LighthouseJobSpec{
+			Type:    job.PresubmitJob,
+			Job:     "pr-build",
+			Context: "pr-build",
+			Refs: &v1alpha1.Refs{
+				Org:     "org",
+				Repo:    "repo",
+				BaseRef: "master",
+				BaseSHA: "base",
+				Pulls: []v1alpha1.Pull{{
+					Number: number,
+					SHA:    sha,
+				}},
+			},
+		}
+		lhjob := jobutil.NewLighthouseJob(spec, nil, nil)
+		lhjob.Name = name
+		lhjob.Namespace = "jx"
+		lhjob.Status.State = state
+		return &lhjob
+	}
+
+	testCases := []struct {
+		name            string
+		skipAbort       bool
+		expectedAborted []string
+	}{
+		{
+			name:            "running jobs for previous commits are aborted",
+			expectedAborted: []string{"old-pending", "old-triggered"},
+		},
+		{
+			name:      "opted out",
+			skipAbort: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			lhClient := fake.NewSimpleClientset(
+				newJob("old-pending", 1, "old", v1alpha1.PendingState),
+				newJob("old-triggered", 1, "old", v1alpha1.TriggeredState),
+				newJob("old-failed", 1, "old", v1alpha1.FailureState),
+				newJob("current", 1, "new", v1alpha1.PendingState),
+				newJob("other-pr", 2, "old", v1alpha1.PendingState),
+			)
+			jobClient := lhClient.LighthouseV1alpha1().LighthouseJobs("jx")
+
+			g := &fake2.SCMClient{
+				OrgMembers:          map[string][]string{"org": {"t"}},
+				PullRequestComments: map[int][]*scm.Comment{},
+			}
+			c := Client{
+				SCMProviderClient: g,
+				LauncherClient:    fakelauncher.NewLauncher(),
+				LighthouseClient:  jobClient,
+				Config:            &config.Config{},
+				Logger:            logrus.WithField("plugin", pluginName),
+			}
+			presubmits := map[string][]job.Presubmit{
+				"org/repo": {
+					{
+						Base: job.Base{
+							Name: "pr-build",
+						},
+						AlwaysRun: true,
+					},
+				},
+			}
+			require.NoError(t, c.Config.SetPresubmits(presubmits))
+
+			pr := scm.PullRequestHook{
+				Action: scm.ActionSync,
+				PullRequest: scm.PullRequest{
+					Number: 1,
+					Author: scm.User{Login: "t"},
+					Base: scm.PullRequestBranch{
+						Ref: "master",
+						Repo: scm.Repository{
+							Namespace: "org",
+							Name:      "repo",
+							FullName:  "org/repo",
+						},
+					},
+					Head: scm.PullRequestBranch{
+						Sha: "new",
+					},
+				},
+			}
+			trigger := &plugins.Trigger{
+				TrustedOrg:              "org",
+				OnlyOrgMembers:          true,
+				SkipAbortSupersededJobs: tc.skipAbort,
+			}
+			require.NoError(t, handlePR(c, trigger, pr))
+
+			jobs, err := jobClient.List(context.TODO(), metav1.ListOptions{})
+			require.NoError(t, err)
+			var aborted []string
+			for _, j := range jobs.Items {
+				if j.Status.State == v1alpha1.AbortedState {
+					aborted = append(aborted, j.Name)
+					assert.Equal(t, "Aborted as superseded by new.", j.Status.Description)
+				}
+			}
+			assert.ElementsMatch(t, tc.expectedAborted, aborted)
+		})
+	}
+}
+
+func TestAbortSupersededJobsOfNestedRepository(t *testing.T) {
+	// the repository label of the GitLab nested repositories is sanitized
+	t.Setenv("GIT_KIND", "gitlab")
+	newJob := func(name, repo string) *v1alpha1.LighthouseJob {
+		spec := v1alpha1.LighthouseJobSpec{
+			Type: job.PresubmitJob,
+			Job:  "pr-build",
+			Refs: &v1alpha1.Refs{
+				Org:   "org",
+				Repo:  repo,
+				Pulls: []v1alpha1.Pull{{Number: 1, SHA: "old"}},
+			},
+		}
+		lhjob := jobutil.NewLighthouseJob(spec, nil, nil)
+		lhjob.Name = name
+		lhjob.Namespace = "jx"
+		lhjob.Status.State = v1alpha1.PendingState
+		return &lhjob
+	}
+	lhClient := fake.NewSimpleClientset(newJob("nested", "group/repo"), newJob("other-repo", "repo"))
+	jobClient := lhClient.LighthouseV1alpha1().LighthouseJobs("jx")
+	c := Client{
+		LighthouseClient: jobClient,
+		Logger:           logrus.WithField("plugin", pluginName),
+	}
+	pr := &scm.PullRequest{
+		Number: 1,
+		Base: scm.PullRequestBranch{
+			Repo: scm.Repository{Namespace: "org", Name: "group/repo", FullName: "org/group/repo"},
+		},
+		Head: scm.PullRequestBranch{Sha: "new"},
+	}
+	require.NoError(t, abortSupersededJobs(c, pr))
+
+	jobs, err := jobClient.List(context.TODO(), metav1.ListOptions{})
+	require.NoError(t, err)
+	states := map[string]v1alpha1.PipelineState{}
+	for _, j := range jobs.Items {
+		states[j.Name] = j.Status.State
+	}
+	assert.Equal(t, map[string]v1alpha1.PipelineState{"nested": v1alpha1.AbortedState, "other-repo": v1alpha1.PendingState}, states)
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
@@ -16,6 +15,7 @@ import (
 	"github.com/jenkins-x/lighthouse/pkg/config/job"
 	"github.com/jenkins-x/lighthouse/pkg/filebrowser"
 	"github.com/jenkins-x/lighthouse/pkg/jobutil"
+	"github.com/jenkins-x/lighthouse/pkg/periodic"
 	"github.com/jenkins-x/lighthouse/pkg/plugins"
 	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
 	"github.com/jenkins-x/lighthouse/pkg/triggerconfig/inrepo"
@@ -25,13 +25,14 @@ import (
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	applybatchv1 "k8s.io/client-go/applyconfigurations/batch/v1"
 	applyv1 "k8s.io/client-go/applyconfigurations/core/v1"
 	kubeclient "k8s.io/client-go/kubernetes"
 	typedbatchv1 "k8s.io/client-go/kubernetes/typed/batch/v1"
 	typedv1 "k8s.io/client-go/kubernetes/typed/core/v1"
 )
 
+// PeriodicAgent maintains the ConfigMaps describing the periodics of the repositories, which are
+// scheduled by the periodic.Scheduler
 type PeriodicAgent struct {
 	Namespace string
 	SCMClient *scm.Client
@@ -169,17 +170,27 @@ func (pa *PeriodicAgent) InitializePeriodics(kc kubeclient.Interface, configAgen
 	c := configAgent.Config()
 	cmInterface := kc.CoreV1().ConfigMaps(pa.Namespace)
 	cjInterface := kc.BatchV1().CronJobs(pa.Namespace)
-	cmList, cronList, done := pa.getExistingResources(nil, cmInterface, cjInterface, "app=lighthouse-webhooks,component=periodic,org,repo,trigger")
+	cmList, cronList, done := pa.getExistingResources(nil, cmInterface, cjInterface, periodic.LabelSelector)
 	if done {
 		return
 	}
 	cmMap := make(map[string]map[string]*corev1.ConfigMap)
-	for _, cm := range cmList.Items {
-		cmMap[cm.Labels["org"]+"/"+cm.Labels["repo"]][cm.Labels["trigger"]] = &cm
+	for i := range cmList.Items {
+		cm := &cmList.Items[i]
+		fullName := cm.Labels["org"] + "/" + cm.Labels["repo"]
+		if cmMap[fullName] == nil {
+			cmMap[fullName] = make(map[string]*corev1.ConfigMap)
+		}
+		cmMap[fullName][cm.Labels["trigger"]] = cm
 	}
 	cronMap := make(map[string]map[string]*batchv1.CronJob)
-	for _, cronjob := range cronList.Items {
-		cronMap[cronjob.Labels["org"]+"/"+cronjob.Labels["repo"]][cronjob.Labels["trigger"]] = &cronjob
+	for i := range cronList.Items {
+		cronjob := &cronList.Items[i]
+		fullName := cronjob.Labels["org"] + "/" + cronjob.Labels["repo"]
+		if cronMap[fullName] == nil {
+			cronMap[fullName] = make(map[string]*batchv1.CronJob)
+		}
+		cronMap[fullName][cronjob.Labels["trigger"]] = cronjob
 	}
 
 	for fullName := range pa.filterPeriodics(c.InRepoConfig.Enabled) {
@@ -228,7 +239,7 @@ func (pa *PeriodicAgent) InitializePeriodics(kc kubeclient.Interface, configAgen
 		}
 	}
 
-	// Removing CronJobs not corresponding to any found triggers
+	// Removing the CronJobs of previous versions and the ConfigMaps not corresponding to any found triggers
 	for _, repoCron := range cronMap {
 		for _, aCron := range repoCron {
 			deleteCronJob(cjInterface, aCron)
@@ -268,6 +279,8 @@ func deleteCronJob(cjInterface typedbatchv1.CronJobInterface, cj *batchv1.CronJo
 	}
 }
 
+// UpdatePeriodicsForRepo creates or updates the ConfigMaps describing the periodics of the repository,
+// deleting the CronJobs which scheduled them in previous versions once their last schedule time is recorded
 func (pa *PeriodicAgent) UpdatePeriodicsForRepo(
 	periodics []job.Periodic,
 	l *logrus.Entry,
@@ -279,20 +292,7 @@ func (pa *PeriodicAgent) UpdatePeriodicsForRepo(
 	cjInterface typedbatchv1.CronJobInterface,
 ) bool {
 	for _, p := range periodics {
-		labels := map[string]string{
-			"app":       "lighthouse-webhooks",
-			"component": "periodic",
-			"org":       org,
-			"repo":      repo,
-			"trigger":   p.Name,
-		}
-		for k, v := range p.Labels {
-			// don't overwrite labels since that would disturb the logic
-			_, predef := labels[k]
-			if !predef {
-				labels[k] = v
-			}
-		}
+		labels := periodicLabels(p, org, repo)
 
 		resourceName := fmt.Sprintf("lighthouse-%s-%s-%s", org, repo, p.Name)
 
@@ -317,8 +317,15 @@ func (pa *PeriodicAgent) UpdatePeriodicsForRepo(
 
 		// Only apply if any value have changed
 		existingCm := getExistingConfigMap(p)
+		existingCron := getExistingCron(p)
 
-		if existingCm == nil || existingCm.Data["lighthousejob.json"] != string(lighthouseData) {
+		// the last schedule of the CronJob of a previous version is kept, so that the scheduler carries on from it
+		lastScheduleTime := ""
+		if existingCron != nil && existingCron.Status.LastScheduleTime != nil && (existingCm == nil || existingCm.Data[periodic.LastScheduleTimeDataKey] == "") {
+			lastScheduleTime = existingCron.Status.LastScheduleTime.UTC().Format(time.RFC3339)
+		}
+
+		if existingCm == nil || existingCm.Data[periodic.JobDataKey] != string(lighthouseData) || existingCm.Data[periodic.CronDataKey] != p.Cron || lastScheduleTime != "" {
 			var cm *applyv1.ConfigMapApplyConfiguration
 			if existingCm != nil {
 				cm, err = applyv1.ExtractConfigMap(existingCm, fieldManager)
@@ -332,7 +339,11 @@ func (pa *PeriodicAgent) UpdatePeriodicsForRepo(
 			if cm.Data == nil {
 				cm.Data = make(map[string]string)
 			}
-			cm.Data["lighthousejob.json"] = string(lighthouseData)
+			cm.Data[periodic.JobDataKey] = string(lighthouseData)
+			cm.Data[periodic.CronDataKey] = p.Cron
+			if lastScheduleTime != "" {
+				cm.Data[periodic.LastScheduleTimeDataKey] = lastScheduleTime
+			}
 
 			_, err := cmInterface.Apply(context.TODO(), cm, metav1.ApplyOptions{Force: true, FieldManager: fieldManager})
 			if err != nil {
@@ -340,29 +351,33 @@ func (pa *PeriodicAgent) UpdatePeriodicsForRepo(
 				return false
 			}
 		}
-		existingCron := getExistingCron(p)
-		if existingCron == nil || existingCron.Spec.Schedule != p.Cron {
-			var cj *applybatchv1.CronJobApplyConfiguration
-			if existingCron != nil {
-				cj, err = applybatchv1.ExtractCronJob(existingCron, fieldManager)
-				if err != nil {
-					l.Error(errors.Wrapf(err, "failed to extract CronJob"))
-					return true
-				}
-			} else {
-				cj = pa.constructCronJob(resourceName, resourceName, labels)
-			}
-			cj.Spec.Schedule = &p.Cron
-			_, err := cjInterface.Apply(context.TODO(), cj, metav1.ApplyOptions{Force: true, FieldManager: fieldManager})
-			if err != nil {
-				l.WithError(err).Errorf("failed to apply cronjob")
-				return false
-			}
+		// the periodic is now scheduled by the periodic.Scheduler
+		if existingCron != nil {
+			deleteCronJob(cjInterface, existingCron)
 		}
 	}
 	return false
 }
 
+// periodicLabels returns the labels of the ConfigMap and LighthouseJobs of the periodic of the repository
+func periodicLabels(p job.Periodic, org, repo string) map[string]string {
+	labels := map[string]string{
+		"app":       "lighthouse-webhooks",
+		"component": "periodic",
+		"org":       org,
+		"repo":      repo,
+		"trigger":   p.Name,
+	}
+	for k, v := range p.Labels {
+		// don't overwrite labels since that would disturb the logic
+		_, predef := labels[k]
+		if !predef {
+			labels[k] = v
+		}
+	}
+	return labels
+}
+
 func (pa *PeriodicAgent) getExistingResources(
 	l *logrus.Entry,
 	cmInterface typedv1.ConfigMapInterface,
@@ -387,44 +402,6 @@ func (pa *PeriodicAgent) getExistingResources(
 	return cmList, cronList, false
 }
 
-func (pa *PeriodicAgent) constructCronJob(resourceName, configMapName string, labels map[string]string) *applybatchv1.CronJobApplyConfiguration {
-	const volumeName = "ligthousejob"
-	serviceAccount, found := os.LookupEnv("SERVICE_ACCOUNT")
-	if !found {
-		serviceAccount = "lighthouse-webhooks"
-	}
-	return applybatchv1.CronJob(resourceName, pa.Namespace).
-		WithLabels(labels).
-		WithSpec((&applybatchv1.CronJobSpecApplyConfiguration{}).
-			WithJobTemplate((&applybatchv1.JobTemplateSpecApplyConfiguration{}).
-				WithLabels(labels).
-				WithSpec((&applybatchv1.JobSpecApplyConfiguration{}).
-					WithBackoffLimit(0).
-					WithTemplate((&applyv1.PodTemplateSpecApplyConfiguration{}).
-						WithLabels(labels).
-						WithSpec((&applyv1.PodSpecApplyConfiguration{}).
-							WithEnableServiceLinks(false).
-							WithServiceAccountName(serviceAccount).
-							WithRestartPolicy("Never").
-							WithContainers((&applyv1.ContainerApplyConfiguration{}).
-								WithName("create-lighthousejob").
-								WithImage("bitnami/kubectl").
-								WithCommand("/bin/bash").
-								WithArgs("-c", `
-set -o errexit
-create_output=$(kubectl create -f /config/lighthousejob.json)
-[[ $create_output =~ (.*)\  ]]
-kubectl patch ${BASH_REMATCH[1]} --type=merge --subresource status --patch 'status: {state: triggered}'
-`).
-								WithVolumeMounts((&applyv1.VolumeMountApplyConfiguration{}).
-									WithName(volumeName).
-									WithMountPath("/config"))).
-							WithVolumes((&applyv1.VolumeApplyConfiguration{}).
-								WithName(volumeName).
-								WithConfigMap((&applyv1.ConfigMapVolumeSourceApplyConfiguration{}).
-									WithName(configMapName))))))))
-}
-
 func (pa *PeriodicAgent) filterPeriodics(enabled map[string]*bool) map[string]*bool {
 	if pa.SCMClient.Contents == nil {
 		return enabled
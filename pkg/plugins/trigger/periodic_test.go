@@ -3,6 +3,7 @@ package trigger
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/jenkins-x/go-scm/scm"
 	scmfake "github.com/jenkins-x/go-scm/scm/driver/fake"
@@ -10,6 +11,7 @@ import (
 	"github.com/jenkins-x/lighthouse/pkg/config/lighthouse"
 	"github.com/jenkins-x/lighthouse/pkg/filebrowser"
 	fbfake "github.com/jenkins-x/lighthouse/pkg/filebrowser/fake"
+	"github.com/jenkins-x/lighthouse/pkg/periodic"
 	"github.com/jenkins-x/lighthouse/pkg/plugins"
 	"github.com/jenkins-x/lighthouse/pkg/triggerconfig/inrepo"
 	"github.com/sirupsen/logrus"
@@ -61,6 +63,9 @@ func TestUpdatePeriodics(t *testing.T) {
 		},
 	}
 
+	// a CronJob created by a previous version should be replaced by the scheduler
+	createLegacyCronJob(t, namespace)
+
 	p.UpdatePeriodics(kubeClient, agent, pe)
 
 	selector := "app=lighthouse-webhooks,component=periodic,repo,trigger"
@@ -68,16 +73,13 @@ func TestUpdatePeriodics(t *testing.T) {
 		List(context.TODO(), metav1.ListOptions{LabelSelector: selector})
 	require.NoError(t, err, "failed to get ConfigMaps")
 	require.Len(t, cms.Items, 1)
-	require.Equal(t, lighthouseJob, cms.Items[0].Data["lighthousejob.json"])
+	require.Equal(t, lighthouseJob, cms.Items[0].Data[periodic.JobDataKey])
+	require.Equal(t, "0 4 * * MON-FRI", cms.Items[0].Data[periodic.CronDataKey])
+	require.Equal(t, "2024-05-03T04:00:00Z", cms.Items[0].Data[periodic.LastScheduleTimeDataKey])
 
 	cjs, err := kubeClient.BatchV1().CronJobs(namespace).List(context.TODO(), metav1.ListOptions{})
 	require.NoError(t, err, "failed to get CronJobs")
-	require.Len(t, cjs.Items, 1)
-	cj := cjs.Items[0].Spec
-	require.Equal(t, "0 4 * * MON-FRI", cj.Schedule)
-	containers := cj.JobTemplate.Spec.Template.Spec.Containers
-	require.Len(t, containers, 1)
-	require.Len(t, containers[0].Args, 2)
+	require.Empty(t, cjs.Items)
 }
 
 func TestInitializePeriodics(t *testing.T) {
@@ -95,23 +97,41 @@ func TestInitializePeriodics(t *testing.T) {
 	fileBrowsers, err := filebrowser.NewFileBrowsers(filebrowser.GitHubURL, fbfake.NewFakeFileBrowser("test_data", true))
 	require.NoError(t, err, "failed to create filebrowsers")
 
+	createLegacyCronJob(t, namespace)
+
 	p.InitializePeriodics(kubeClient, configAgent, fileBrowsers)
 
-	selector := "app=lighthouse-webhooks,component=periodic,org,repo,trigger"
 	cms, err := kubeClient.CoreV1().ConfigMaps(namespace).
-		List(context.TODO(), metav1.ListOptions{LabelSelector: selector})
+		List(context.TODO(), metav1.ListOptions{LabelSelector: periodic.LabelSelector})
 	require.NoError(t, err, "failed to get ConfigMaps")
 	require.Len(t, cms.Items, 1)
-	require.Equal(t, lighthouseJob, cms.Items[0].Data["lighthousejob.json"])
+	require.Equal(t, lighthouseJob, cms.Items[0].Data[periodic.JobDataKey])
+	require.Equal(t, "0 4 * * MON-FRI", cms.Items[0].Data[periodic.CronDataKey])
+	require.Equal(t, "2024-05-03T04:00:00Z", cms.Items[0].Data[periodic.LastScheduleTimeDataKey])
 
 	cjs, err := kubeClient.BatchV1().CronJobs(namespace).List(context.TODO(), metav1.ListOptions{})
 	require.NoError(t, err, "failed to get CronJobs")
-	require.Len(t, cjs.Items, 1)
-	cj := cjs.Items[0].Spec
-	require.Equal(t, "0 4 * * MON-FRI", cj.Schedule)
-	containers := cj.JobTemplate.Spec.Template.Spec.Containers
-	require.Len(t, containers, 1)
-	require.Len(t, containers[0].Args, 2)
+	require.Empty(t, cjs.Items)
+}
+
+func createLegacyCronJob(t *testing.T, namespace string) {
+	_, err := kubeClient.BatchV1().CronJobs(namespace).Create(context.TODO(), &batchv1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "lighthouse-testorg-myapp-dailyjob",
+			Labels: map[string]string{
+				"app":       "lighthouse-webhooks",
+				"component": "periodic",
+				"org":       "testorg",
+				"repo":      "myapp",
+				"trigger":   "dailyjob",
+			},
+		},
+		Spec: batchv1.CronJobSpec{Schedule: "0 4 * * MON-FRI"},
+		Status: batchv1.CronJobStatus{
+			LastScheduleTime: &metav1.Time{Time: time.Date(2024, 5, 3, 4, 0, 0, 0, time.UTC)},
+		},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err, "failed to create CronJob")
 }
 
 func setupPeriodicsTest() (string, *PeriodicAgent) {
@@ -67,6 +67,11 @@ func handlePR(c Client, trigger *plugins.Trigger, pr scm.PullRequestHook) error
 			return buildAllIfTrustedOrDraft(c, trigger, pr)
 		}
 	case scm.ActionReopen, scm.ActionSync:
+		if pr.Action == scm.ActionSync && !trigger.SkipAbortSupersededJobs {
+			if err := abortSupersededJobs(c, &pr.PullRequest); err != nil {
+				c.Logger.WithError(err).Warn("Failed to abort superseded jobs.")
+			}
+		}
 		return buildAllIfTrustedOrDraft(c, trigger, pr)
 	case scm.ActionReadyForReview, scm.ActionConvertedToDraft:
 		if trigger.SkipDraftPR {
@@ -0,0 +1,120 @@
+package trigger
+
+import (
+	"strconv"
+
+	"github.com/pkg/errors"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/jobutil"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// handleTag triggers the postsubmits matching a newly created tag
+func handleTag(c Client, th scm.TagHook) error {
+	if th.Action != scm.ActionCreate {
+		return nil
+	}
+	tag := scm.TrimRef(th.Ref.Name)
+	sha := th.Ref.Sha
+	for _, j := range c.Config.GetPostsubmits(th.Repo) {
+		if !j.ShouldRunForTag(tag) {
+			continue
+		}
+		if sha == "" {
+			// not all providers include the commit in tag events
+			resolved, err := c.SCMProviderClient.GetRef(th.Repo.Namespace, th.Repo.Name, "tags/"+tag)
+			if err != nil {
+				return errors.Wrapf(err, "resolving the SHA of tag %s", tag)
+			}
+			sha = resolved
+		}
+		refs := v1alpha1.Refs{
+			Org:      th.Repo.Namespace,
+			Repo:     th.Repo.Name,
+			RepoLink: th.Repo.Link,
+			BaseRef:  tag,
+			BaseSHA:  sha,
+			CloneURI: th.Repo.Clone,
+		}
+		if err := launchTagOrReleaseJob(c, j, refs, tag, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// releaseActions are the raw webhook actions which publish a release. GitHub and Gitea send published
+// (and GitHub also released), GitLab has no drafts so it only sends create.
+var releaseActions = sets.NewString("published", "released", "create")
+
+// handleRelease triggers the postsubmits for a published release
+func handleRelease(c Client, re scmprovider.ReleaseEvent) error {
+	release := re.Release
+	if release.Draft || release.Tag == "" || !releaseActions.Has(releaseAction(re)) {
+		return nil
+	}
+	releaseID := strconv.Itoa(release.ID)
+	sha := ""
+	for _, j := range c.Config.GetPostsubmits(re.Repo) {
+		if !j.Release {
+			continue
+		}
+		if sha == "" {
+			resolved, err := c.SCMProviderClient.GetRef(re.Repo.Namespace, re.Repo.Name, "tags/"+release.Tag)
+			if err != nil {
+				return errors.Wrapf(err, "resolving the SHA of tag %s", release.Tag)
+			}
+			sha = resolved
+		}
+		refs := v1alpha1.Refs{
+			Org:      re.Repo.Namespace,
+			Repo:     re.Repo.Name,
+			RepoLink: re.Repo.Link,
+			BaseRef:  release.Tag,
+			BaseSHA:  sha,
+			BaseLink: release.Link,
+			CloneURI: re.Repo.Clone,
+		}
+		if err := launchTagOrReleaseJob(c, j, refs, release.Tag, releaseID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// releaseAction returns the raw action of the release event. The events which were not read from a webhook
+// payload only have the parsed action, which is only known for the created releases.
+func releaseAction(re scmprovider.ReleaseEvent) string {
+	if re.RawAction == "" && re.Action == scm.ActionCreate {
+		return "create"
+	}
+	return re.RawAction
+}
+
+func launchTagOrReleaseJob(c Client, j job.Postsubmit, refs v1alpha1.Refs, tag, releaseID string) error {
+	labels := make(map[string]string)
+	for k, v := range j.Labels {
+		labels[k] = v
+	}
+	spec := jobutil.PostsubmitSpec(c.Logger, j, refs)
+	spec.Tag = tag
+	spec.ReleaseID = releaseID
+	pj := jobutil.NewLighthouseJob(spec, labels, j.Annotations)
+	if releaseID != "" {
+		// providers send several events for a release, the fixed name makes sure the job is only created once
+		pj.GenerateName = ""
+		pj.Name = jobutil.DeterministicName(&spec, "release-"+releaseID)
+	}
+	c.Logger.WithFields(jobutil.LighthouseJobFields(&pj)).Info("Creating a new LighthouseJob.")
+	_, err := c.LauncherClient.Launch(&pj)
+	if releaseID != "" && apierrors.IsAlreadyExists(err) {
+		c.Logger.WithField("job", j.Name).Debugf("Job already triggered for release %s.", releaseID)
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,204 @@
+package trigger
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	fakelauncher "github.com/jenkins-x/lighthouse/pkg/launcher/fake"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	fake2 "github.com/jenkins-x/lighthouse/pkg/scmprovider/fake"
+	"github.com/jenkins-x/lighthouse/pkg/util"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func releaseTestConfig(t *testing.T) *config.Config {
+	postsubmits := []job.Postsubmit{
+		{
+			Base: job.Base{Name: "build"},
+		},
+		{
+			Base: job.Base{Name: "release-tag"},
+			Tags: []string{`^v\d+\.\d+\.\d+$`},
+		},
+		{
+			Base:    job.Base{Name: "publish-release"},
+			Release: true,
+		},
+	}
+	for i := range postsubmits {
+		require.NoError(t, postsubmits[i].SetRegexes())
+	}
+	return &config.Config{
+		JobConfig: config.JobConfig{
+			Postsubmits: map[string][]job.Postsubmit{"org/repo": postsubmits},
+		},
+	}
+}
+
+// failingRefClient fails to resolve the refs
+type failingRefClient struct {
+	*fake2.SCMClient
+}
+
+func (f *failingRefClient) GetRef(owner, repo, ref string) (string, error) {
+	return "", errors.New("not found")
+}
+
+func TestHandleTag(t *testing.T) {
+	repo := scm.Repository{Namespace: "org", Name: "repo", FullName: "org/repo", Clone: "https://github.com/org/repo.git"}
+	testCases := []struct {
+		name         string
+		hook         scm.TagHook
+		expectedJobs []string
+		expectedSHA  string
+	}{
+		{
+			name:         "matching tag",
+			hook:         scm.TagHook{Action: scm.ActionCreate, Repo: repo, Ref: scm.Reference{Name: "v1.2.3", Sha: "abc"}},
+			expectedJobs: []string{"release-tag"},
+			expectedSHA:  "abc",
+		},
+		{
+			name:         "matching tag without sha",
+			hook:         scm.TagHook{Action: scm.ActionCreate, Repo: repo, Ref: scm.Reference{Name: "refs/tags/v1.2.3"}},
+			expectedJobs: []string{"release-tag"},
+			expectedSHA:  fake2.TestRef,
+		},
+		{
+			name: "tag not matching",
+			hook: scm.TagHook{Action: scm.ActionCreate, Repo: repo, Ref: scm.Reference{Name: "v1.2.3-rc1", Sha: "abc"}},
+		},
+		{
+			name: "tag deleted",
+			hook: scm.TagHook{Action: scm.ActionDelete, Repo: repo, Ref: scm.Reference{Name: "v1.2.3", Sha: "abc"}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeLauncher := fakelauncher.NewLauncher()
+			c := Client{
+				SCMProviderClient: &fake2.SCMClient{},
+				LauncherClient:    fakeLauncher,
+				Config:            releaseTestConfig(t),
+				Logger:            logrus.WithField("plugin", pluginName),
+			}
+			require.NoError(t, handleTag(c, tc.hook))
+
+			var names []string
+			for _, lhjob := range fakeLauncher.Pipelines {
+				names = append(names, lhjob.Spec.Job)
+				assert.Equal(t, job.PostsubmitJob, lhjob.Spec.Type)
+				assert.Equal(t, "v1.2.3", lhjob.Spec.Refs.BaseRef)
+				assert.Equal(t, tc.expectedSHA, lhjob.Spec.Refs.BaseSHA)
+				env := lhjob.Spec.GetEnvVars()
+				assert.Equal(t, "v1.2.3", env[v1alpha1.PullBaseRefEnv])
+				assert.Equal(t, "v1.2.3", env[v1alpha1.TagNameEnv])
+			}
+			assert.Equal(t, tc.expectedJobs, names)
+		})
+	}
+}
+
+func TestHandleTagWithUnresolvedSHA(t *testing.T) {
+	fakeLauncher := fakelauncher.NewLauncher()
+	c := Client{
+		SCMProviderClient: &failingRefClient{SCMClient: &fake2.SCMClient{}},
+		LauncherClient:    fakeLauncher,
+		Config:            releaseTestConfig(t),
+		Logger:            logrus.WithField("plugin", pluginName),
+	}
+	hook := scm.TagHook{Action: scm.ActionCreate, Repo: scm.Repository{Namespace: "org", Name: "repo", FullName: "org/repo"}, Ref: scm.Reference{Name: "v1.2.3"}}
+	assert.EqualError(t, handleTag(c, hook), "resolving the SHA of tag v1.2.3: not found")
+	assert.Empty(t, fakeLauncher.Pipelines)
+}
+
+func TestHandleRelease(t *testing.T) {
+	repo := scm.Repository{Namespace: "org", Name: "repo", FullName: "org/repo"}
+	published := scmprovider.ReleaseEvent{
+		ReleaseHook: scm.ReleaseHook{
+			Repo:    repo,
+			Release: scm.Release{ID: 42, Tag: "v1.2.3"},
+		},
+		RawAction: "published",
+	}
+	fakeLauncher := fakelauncher.NewLauncher()
+	c := Client{
+		SCMProviderClient: &fake2.SCMClient{},
+		LauncherClient:    fakeLauncher,
+		Config:            releaseTestConfig(t),
+		Logger:            logrus.WithField("plugin", pluginName),
+	}
+
+	for _, action := range []string{"created", "prereleased", "unpublished", "edited", "deleted"} {
+		ignored := published
+		ignored.RawAction = action
+		require.NoError(t, handleRelease(c, ignored))
+	}
+	draft := published
+	draft.Release.Draft = true
+	require.NoError(t, handleRelease(c, draft))
+	assert.Empty(t, fakeLauncher.Pipelines)
+
+	require.NoError(t, handleRelease(c, published))
+	require.Len(t, fakeLauncher.Pipelines, 1)
+	lhjob := fakeLauncher.Pipelines[0]
+	assert.Equal(t, "publish-release", lhjob.Spec.Job)
+	assert.Equal(t, "v1.2.3", lhjob.Spec.Refs.BaseRef)
+	assert.Equal(t, fake2.TestRef, lhjob.Spec.Refs.BaseSHA)
+	assert.Equal(t, "42", lhjob.Spec.ReleaseID)
+	assert.Equal(t, "42", lhjob.Spec.GetEnvVars()[v1alpha1.ReleaseIDEnv])
+	assert.Equal(t, "42", lhjob.Labels[util.ReleaseIDLabel])
+	assert.NotEmpty(t, lhjob.Name)
+
+	// the same release is only built once, even if the provider sends several events
+	released := published
+	released.RawAction = "released"
+	require.NoError(t, handleRelease(c, released))
+	require.NoError(t, handleRelease(c, published))
+	assert.Len(t, fakeLauncher.Pipelines, 1)
+
+	other := published
+	other.Release.ID = 43
+	require.NoError(t, handleRelease(c, other))
+	assert.Len(t, fakeLauncher.Pipelines, 2)
+}
+
+func TestHandleReleaseWithoutRawAction(t *testing.T) {
+	// the release hooks which are not read from a webhook payload only have the parsed action
+	created := scmprovider.ReleaseEvent{
+		ReleaseHook: scm.ReleaseHook{
+			Action:  scm.ActionCreate,
+			Repo:    scm.Repository{Namespace: "org", Name: "repo", FullName: "org/repo"},
+			Release: scm.Release{ID: 42, Tag: "v1.2.3"},
+		},
+	}
+	fakeLauncher := fakelauncher.NewLauncher()
+	c := Client{
+		SCMProviderClient: &fake2.SCMClient{},
+		LauncherClient:    fakeLauncher,
+		Config:            releaseTestConfig(t),
+		Logger:            logrus.WithField("plugin", pluginName),
+	}
+
+	deleted := created
+	deleted.Action = scm.ActionDelete
+	require.NoError(t, handleRelease(c, deleted))
+	assert.Empty(t, fakeLauncher.Pipelines)
+
+	require.NoError(t, handleRelease(c, created))
+	require.Len(t, fakeLauncher.Pipelines, 1)
+	assert.Equal(t, "publish-release", fakeLauncher.Pipelines[0].Spec.Job)
+	assert.Equal(t, fake2.TestRef, fakeLauncher.Pipelines[0].Spec.Refs.BaseSHA)
+
+	c.SCMProviderClient = &failingRefClient{SCMClient: &fake2.SCMClient{}}
+	other := created
+	other.Release.ID = 43
+	assert.EqualError(t, handleRelease(c, other), "resolving the SHA of tag v1.2.3: not found")
+	assert.Len(t, fakeLauncher.Pipelines, 1, "no job should be triggered without the SHA of the tag")
+}
@@ -0,0 +1,164 @@
+package trigger
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/jobutil"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+)
+
+// runPeriodicArgPattern matches the name of the periodic followed by the NAME=value parameter overrides
+const runPeriodicArgPattern = `[-\w.]+(?:[ \t]+[-\w.]+=\S*)*`
+
+var runPeriodicParamRe = regexp.MustCompile(`^([-\w.]+)=(\S*)$`)
+
+// ErrParametersNotSupported is returned when overriding the parameters of a periodic which is not a pipeline
+var ErrParametersNotSupported = errors.New("parameters can only be overridden for pipelines")
+
+func handleRunPeriodicEvent(match plugins.CommandMatch, pc plugins.Agent, gc scmprovider.GenericCommentEvent) error {
+	return handleRunPeriodic(getClient(pc), pc.PluginConfig.TriggerFor(gc.Repo.Namespace, gc.Repo.Name), match.Arg, gc)
+}
+
+func handleRunPeriodic(c Client, trigger *plugins.Trigger, arg string, gc scmprovider.GenericCommentEvent) error {
+	org := gc.Repo.Namespace
+	repo := gc.Repo.Name
+	respond := func(resp string) error {
+		c.Logger.Infof("Commenting \"%s\".", resp)
+		return c.SCMProviderClient.CreateComment(org, repo, gc.Number, gc.IsPR, plugins.FormatResponseRaw(gc.Body, gc.Link, c.SCMProviderClient.QuoteAuthorForComment(gc.Author.Login), resp))
+	}
+
+	trusted, err := TrustedUser(c.SCMProviderClient, trigger, gc.Author.Login, org, repo)
+	if err != nil {
+		return fmt.Errorf("error checking trust of %s: %v", gc.Author.Login, err)
+	}
+	if !trusted {
+		return respond("Only trusted users can run periodic jobs.")
+	}
+
+	fields := strings.Fields(arg)
+	params := map[string]string{}
+	for _, field := range fields[1:] {
+		m := runPeriodicParamRe.FindStringSubmatch(field)
+		if m == nil {
+			return respond(fmt.Sprintf("Invalid parameter `%s`, parameters should be of the form `NAME=value`.", field))
+		}
+		params[m[1]] = m[2]
+	}
+
+	lhjob, err := RunPeriodic(c, org, repo, fields[0], params)
+	if err != nil {
+		c.Logger.WithError(err).Warnf("Failed to run periodic %s.", fields[0])
+		return respond(fmt.Sprintf("Failed to run periodic job `%s`: %v", fields[0], err))
+	}
+	return respond(fmt.Sprintf("Started periodic job `%s` as LighthouseJob `%s`.", fields[0], lhjob.Name))
+}
+
+// RunPeriodic launches the periodic job of the repository on demand. The params override the values of the
+// parameters of its pipeline. The job is subject to its max_concurrency like any other run of the periodic.
+func RunPeriodic(c Client, org, repo, name string, params map[string]string) (*v1alpha1.LighthouseJob, error) {
+	found, err := FindPeriodic(c.Config.Periodics, org, repo, name)
+	if err != nil {
+		return nil, err
+	}
+	if found == nil {
+		return nil, fmt.Errorf("no periodic job named %s in %s/%s", name, org, repo)
+	}
+	// lets not modify the shared configuration when loading the pipeline
+	p := *found
+	l := c.Logger.WithField("periodic", name)
+	if err := p.LoadPipeline(l); err != nil {
+		return nil, fmt.Errorf("failed to load pipeline %s from %s: %v", p.Name, p.SourcePath, err)
+	}
+	refs := v1alpha1.Refs{
+		Org:      org,
+		Repo:     repo,
+		BaseRef:  p.Branch,
+		CloneURI: p.CloneURI,
+	}
+	spec := jobutil.PeriodicSpec(l, p, refs)
+	if err := overrideParams(&spec, params); err != nil {
+		return nil, err
+	}
+	pj := jobutil.NewLighthouseJob(spec, periodicLabels(p, org, repo), p.Annotations)
+	l.WithFields(jobutil.LighthouseJobFields(&pj)).Info("Creating a new LighthouseJob.")
+	return c.LauncherClient.Launch(&pj)
+}
+
+// FindPeriodic returns the periodic job with the given name defined by the in-repo configuration of the
+// repository, or nil if there is none. The periodics of the global configuration and of other repositories
+// are ignored, and an error is returned if several periodics of the repository have the name.
+func FindPeriodic(periodics []job.Periodic, org, repo, name string) (*job.Periodic, error) {
+	fullName := scm.Join(org, repo)
+	var found *job.Periodic
+	for i := range periodics {
+		p := &periodics[i]
+		if p.Name != name || p.SourceRepo() != fullName {
+			continue
+		}
+		if found != nil {
+			return nil, fmt.Errorf("periodic job name %s is ambiguous in %s", name, fullName)
+		}
+		found = p
+	}
+	return found, nil
+}
+
+// overrideParams sets the values of the pipeline parameters
+func overrideParams(spec *v1alpha1.LighthouseJobSpec, params map[string]string) error {
+	if len(params) == 0 {
+		return nil
+	}
+	if spec.PipelineRunSpec == nil {
+		return ErrParametersNotSupported
+	}
+	spec.PipelineRunSpec = spec.PipelineRunSpec.DeepCopy()
+	spec.PipelineRunParams = append([]job.PipelineRunParam(nil), spec.PipelineRunParams...)
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		value := params[name]
+		// the pipeline_run_params are evaluated last so they need to be overridden too
+		found := false
+		for i := range spec.PipelineRunParams {
+			if spec.PipelineRunParams[i].Name == name {
+				spec.PipelineRunParams[i].ValueTemplate = literalTemplate(value)
+				found = true
+			}
+		}
+		for i := range spec.PipelineRunSpec.Params {
+			if spec.PipelineRunSpec.Params[i].Name == name {
+				spec.PipelineRunSpec.Params[i].Value = *pipelinev1.NewStructuredValues(value)
+				found = true
+			}
+		}
+		if !found {
+			spec.PipelineRunSpec.Params = append(spec.PipelineRunSpec.Params, pipelinev1.Param{
+				Name:  name,
+				Value: *pipelinev1.NewStructuredValues(value),
+			})
+		}
+	}
+	return nil
+}
+
+// literalTemplate returns a template evaluating to the value
+func literalTemplate(value string) string {
+	if !strings.Contains(value, "{{") {
+		return value
+	}
+	return "{{" + strconv.Quote(value) + "}}"
+}
@@ -0,0 +1,180 @@
+package trigger
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/config"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/filebrowser"
+	fbfake "github.com/jenkins-x/lighthouse/pkg/filebrowser/fake"
+	fakelauncher "github.com/jenkins-x/lighthouse/pkg/launcher/fake"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	fake2 "github.com/jenkins-x/lighthouse/pkg/scmprovider/fake"
+	"github.com/jenkins-x/lighthouse/pkg/triggerconfig"
+	"github.com/jenkins-x/lighthouse/pkg/triggerconfig/inrepo"
+	"github.com/jenkins-x/lighthouse/pkg/triggerconfig/merge"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleRunPeriodic(t *testing.T) {
+	fileBrowsers, err := filebrowser.NewFileBrowsers(filebrowser.GitHubURL, fbfake.NewFakeFileBrowser("test_data", true))
+	require.NoError(t, err)
+	triggerConfig, err := inrepo.LoadTriggerConfig(fileBrowsers, filebrowser.NewFetchCache(), inrepo.NewResolverCache(), "testorg", "myapp", "")
+	require.NoError(t, err)
+	require.Len(t, triggerConfig.Spec.Periodics, 1)
+	triggerConfig.Spec.Periodics[0].MaxConcurrency = 1
+	triggerConfig.Spec.Periodics[0].PipelineRunParams = []job.PipelineRunParam{{Name: "GREETINGS", ValueTemplate: "Howdy!"}}
+	otherConfig := &triggerconfig.Config{}
+	otherConfig.Spec.Periodics = []job.Periodic{{Base: job.Base{Name: "otherjob"}, Cron: "0 4 * * *"}}
+
+	testCases := []struct {
+		name           string
+		author         string
+		arg            string
+		expectedParams map[string]string
+		expectedReply  string
+	}{
+		{
+			name:           "trusted user",
+			author:         "trusted-member",
+			arg:            "dailyjob",
+			expectedParams: map[string]string{"GREETINGS": "Howdy!"},
+			expectedReply:  "Started periodic job `dailyjob`",
+		},
+		{
+			name:          "invalid parameter",
+			author:        "trusted-member",
+			arg:           "dailyjob GREETINGS=Hello {{.Foo}}=x",
+			expectedReply: "Invalid parameter `{{.Foo}}=x`",
+		},
+		{
+			name:           "parameter overrides",
+			author:         "trusted-member",
+			arg:            "dailyjob GREETINGS=Hello EXTRA=more",
+			expectedParams: map[string]string{"GREETINGS": "Hello", "EXTRA": "more"},
+			expectedReply:  "Started periodic job `dailyjob`",
+		},
+		{
+			name:          "untrusted user",
+			author:        "untrusted",
+			arg:           "dailyjob",
+			expectedReply: "Only trusted users can run periodic jobs.",
+		},
+		{
+			name:          "global periodic",
+			author:        "trusted-member",
+			arg:           "globaljob",
+			expectedReply: "Failed to run periodic job `globaljob`: no periodic job named globaljob in testorg/myapp",
+		},
+		{
+			name:          "periodic of another repository",
+			author:        "trusted-member",
+			arg:           "otherjob",
+			expectedReply: "Failed to run periodic job `otherjob`: no periodic job named otherjob in testorg/myapp",
+		},
+		{
+			name:          "unknown periodic",
+			author:        "trusted-member",
+			arg:           "nightlyjob",
+			expectedReply: "Failed to run periodic job `nightlyjob`: no periodic job named nightlyjob in testorg/myapp",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := &fake2.SCMClient{
+				OrgMembers:    map[string][]string{"testorg": {"trusted-member"}},
+				IssueComments: map[int][]*scm.Comment{},
+			}
+			fakeLauncher := fakelauncher.NewLauncher()
+			cfg := &config.Config{
+				JobConfig: config.JobConfig{
+					Periodics: []job.Periodic{{Base: job.Base{Name: "globaljob"}, Cron: "0 4 * * *"}},
+				},
+			}
+			require.NoError(t, merge.ConfigMerge(cfg, &plugins.Configuration{}, triggerConfig, "testorg", "myapp"))
+			require.NoError(t, merge.ConfigMerge(cfg, &plugins.Configuration{}, otherConfig, "testorg", "other"))
+			c := Client{
+				SCMProviderClient: g,
+				LauncherClient:    fakeLauncher,
+				Config:            cfg,
+				Logger:            logrus.WithField("plugin", pluginName),
+			}
+			event := scmprovider.GenericCommentEvent{
+				Action: scm.ActionCreate,
+				Repo: scm.Repository{
+					Namespace: "testorg",
+					Name:      "myapp",
+					FullName:  "testorg/myapp",
+				},
+				Body:   "/run-periodic " + tc.arg,
+				Number: 5,
+				Author: scm.User{Login: tc.author},
+			}
+
+			require.NoError(t, handleRunPeriodic(c, &plugins.Trigger{}, tc.arg, event))
+
+			require.Len(t, g.IssueComments[5], 1)
+			assert.Contains(t, g.IssueComments[5][0].Body, tc.expectedReply)
+			if tc.expectedParams == nil {
+				assert.Empty(t, fakeLauncher.Pipelines)
+				return
+			}
+			require.Len(t, fakeLauncher.Pipelines, 1)
+			lhjob := fakeLauncher.Pipelines[0]
+			assert.Equal(t, job.PeriodicJob, lhjob.Spec.Type)
+			assert.Equal(t, "dailyjob", lhjob.Spec.Job)
+			assert.Equal(t, 1, lhjob.Spec.MaxConcurrency)
+			assert.Equal(t, "testorg", lhjob.Spec.Refs.Org)
+			assert.Equal(t, "myapp", lhjob.Spec.Refs.Repo)
+			assert.Equal(t, "dailyjob", lhjob.Labels["trigger"])
+			require.NotNil(t, lhjob.Spec.PipelineRunSpec)
+
+			params := map[string]string{}
+			for _, p := range lhjob.Spec.PipelineRunParams {
+				params[p.Name] = p.ValueTemplate
+			}
+			for _, p := range lhjob.Spec.PipelineRunSpec.Params {
+				if _, ok := params[p.Name]; !ok {
+					params[p.Name] = p.Value.StringVal
+				}
+			}
+			assert.Equal(t, tc.expectedParams, params)
+		})
+	}
+	assert.Equal(t, "Howdy!", triggerConfig.Spec.Periodics[0].PipelineRunParams[0].ValueTemplate, "the configuration should not be modified")
+}
+
+func TestFindPeriodic(t *testing.T) {
+	periodic := func(name, sourceRepo string) job.Periodic {
+		p := job.Periodic{Base: job.Base{Name: name}}
+		p.SetSourceRepo(sourceRepo)
+		return p
+	}
+	periodics := []job.Periodic{
+		periodic("nightly", ""),
+		periodic("nightly", "org/other"),
+		periodic("nightly", "org/repo"),
+		periodic("weekly", "org/repo"),
+		periodic("weekly", "org/repo"),
+	}
+
+	p, err := FindPeriodic(periodics, "org", "repo", "nightly")
+	require.NoError(t, err)
+	assert.Same(t, &periodics[2], p)
+
+	p, err = FindPeriodic(periodics, "org", "another", "nightly")
+	require.NoError(t, err)
+	assert.Nil(t, p)
+
+	_, err = FindPeriodic(periodics, "org", "repo", "weekly")
+	assert.EqualError(t, err, "periodic job name weekly is ambiguous in org/repo")
+}
+
+func TestLiteralTemplate(t *testing.T) {
+	assert.Equal(t, "plain", literalTemplate("plain"))
+	assert.Equal(t, `{{"{{ .Foo }}"}}`, literalTemplate("{{ .Foo }}"))
+}
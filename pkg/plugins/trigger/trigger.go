@@ -39,16 +39,23 @@ const (
 
 	// environment variable used to enable deployment specific trigger commands
 	customerTriggerCommandEnvVar = "LH_CUSTOM_TRIGGER_COMMAND"
+
+	// SkippedStatusDescription is the description of the statuses reported for skipped contexts
+	SkippedStatusDescription = "Skipped."
 )
 
 var plugin = plugins.Plugin{
 	Description: `The trigger plugin starts tests in reaction to commands and pull request events. It is responsible for ensuring that test jobs are only run on trusted PRs. A PR is considered trusted if the author is a member of the 'trusted organization' for the repository or if such a member has left an '/ok-to-test' command on the PR.
 <br>Trigger starts jobs automatically when a new trusted PR is created or when an untrusted PR becomes trusted, but it can also be used to start jobs manually via the '/test' command.
-<br>The '/retest' command can be used to rerun jobs that have reported failure.`,
+<br>The '/retest' command can be used to rerun jobs that have reported failure.
+<br>The '/run-periodic' command can be used on any issue or PR to start a periodic job of the repository on demand.
+<br>Postsubmits with 'tags' or 'release' set are started when a matching tag is created or a release is published.`,
 	ConfigHelpProvider:      configHelp,
 	PullRequestHandler:      handlePullRequest,
 	PushEventHandler:        handlePush,
 	DeploymentStatusHandler: handleDeploymentStatus,
+	TagEventHandler:         handleTagEvent,
+	ReleaseEventHandler:     handleReleaseEvent,
 	Commands: []plugins.Command{{
 		Name:        "ok-to-test",
 		Description: "Marks a PR as 'trusted' and starts tests.",
@@ -73,6 +80,17 @@ var plugin = plugins.Plugin{
 		Action: plugins.
 			Invoke(handleGenericCommentEvent).
 			When(plugins.Action(scm.ActionCreate), plugins.IsPR(), plugins.IssueState("open")),
+	}, {
+		Name: "run-periodic",
+		Arg: &plugins.CommandArg{
+			Usage:   "<name> [NAME=value...]",
+			Pattern: runPeriodicArgPattern,
+		},
+		Description: "Manually starts a periodic job of the repository, optionally overriding the values of parameters of its pipeline.",
+		WhoCanUse:   "Members of the trusted organization for the repo.",
+		Action: plugins.
+			Invoke(handleRunPeriodicEvent).
+			When(plugins.Action(scm.ActionCreate)),
 	}},
 }
 
@@ -152,6 +170,7 @@ type launcher interface {
 type Client struct {
 	SCMProviderClient scmProviderClient
 	LauncherClient    launcher
+	LighthouseClient  lighthouseJobClient
 	Config            *config.Config
 	Logger            *logrus.Entry
 }
@@ -163,12 +182,16 @@ type trustedUserClient interface {
 }
 
 func getClient(pc plugins.Agent) Client {
-	return Client{
+	c := Client{
 		SCMProviderClient: pc.SCMProviderClient,
 		Config:            pc.Config,
 		LauncherClient:    pc.LauncherClient,
 		Logger:            pc.Logger,
 	}
+	if pc.LighthouseClient != nil {
+		c.LighthouseClient = pc.LighthouseClient
+	}
+	return c
 }
 
 func handlePullRequest(pc plugins.Agent, pr scm.PullRequestHook) error {
@@ -184,6 +207,14 @@ func handlePush(pc plugins.Agent, pe scm.PushHook) error {
 	return handlePE(getClient(pc), pe)
 }
 
+func handleTagEvent(pc plugins.Agent, th scm.TagHook) error {
+	return handleTag(getClient(pc), th)
+}
+
+func handleReleaseEvent(pc plugins.Agent, re scmprovider.ReleaseEvent) error {
+	return handleRelease(getClient(pc), re)
+}
+
 // TrustedUser returns true if user is trusted in repo.
 //
 // Trusted users are either repo collaborators, org members, trusted org members or trusted Github Apps.
@@ -240,7 +271,7 @@ func skippedStatusFor(context string) *scm.StatusInput {
 	return &scm.StatusInput{
 		State: scm.StateSuccess,
 		Label: context,
-		Desc:  "Skipped.",
+		Desc:  SkippedStatusDescription,
 	}
 }
 
@@ -56,6 +56,11 @@ func NewPollingController(repositoryNames []string, gitServer string, scmClient
 	}, nil
 }
 
+// SetPollState replaces the default in memory poll state, e.g. with one that survives restarts
+func (c *pollingController) SetPollState(ps pollstate.Interface) {
+	c.pollstate = ps
+}
+
 func (c *pollingController) Logger() *logrus.Entry {
 	return c.logger
 }
@@ -0,0 +1,100 @@
+package pollstate
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+)
+
+const (
+	// DefaultConfigMapName the default name of the ConfigMap used to store the poll state
+	DefaultConfigMapName = "lighthouse-poller-state"
+
+	// configMapKey the ConfigMap entry containing the poll state as JSON.
+	// A single entry is used as repository names are not valid ConfigMap keys.
+	configMapKey = "state.json"
+)
+
+type configMapStore struct {
+	kubeClient kubernetes.Interface
+	namespace  string
+	name       string
+}
+
+// NewConfigMapPollState creates a new poll state stored in a ConfigMap so that it survives restarts and
+// can be shared by several poller replicas. The ConfigMap is created if it does not exist.
+func NewConfigMapPollState(kubeClient kubernetes.Interface, namespace, name string) Interface {
+	if name == "" {
+		name = DefaultConfigMapName
+	}
+	logger := logrus.WithFields(map[string]interface{}{
+		"Namespace": namespace,
+		"ConfigMap": name,
+	})
+	s := &configMapStore{
+		kubeClient: kubeClient,
+		namespace:  namespace,
+		name:       name,
+	}
+	return newPersistentPollState(s, logger)
+}
+
+func (s *configMapStore) modify(fn func(state map[string]entry) bool) error {
+	// the ConfigMap resource version makes concurrent updates from other replicas fail with a conflict,
+	// in which case the state is read again and the change is reapplied
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		ctx := context.TODO()
+		configMaps := s.kubeClient.CoreV1().ConfigMaps(s.namespace)
+
+		cm, err := configMaps.Get(ctx, s.name, metav1.GetOptions{})
+		create := false
+		if err != nil {
+			if !apierrors.IsNotFound(err) {
+				return errors.Wrapf(err, "failed to get ConfigMap %s in namespace %s", s.name, s.namespace)
+			}
+			create = true
+			cm = &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      s.name,
+					Namespace: s.namespace,
+				},
+			}
+		}
+
+		state := map[string]entry{}
+		if text := cm.Data[configMapKey]; text != "" {
+			if err := json.Unmarshal([]byte(text), &state); err != nil {
+				return errors.Wrapf(err, "failed to unmarshal poll state in ConfigMap %s", s.name)
+			}
+		}
+		if !fn(state) {
+			return nil
+		}
+		data, err := json.Marshal(state)
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal poll state")
+		}
+		if cm.Data == nil {
+			cm.Data = map[string]string{}
+		}
+		cm.Data[configMapKey] = string(data)
+
+		if create {
+			_, err = configMaps.Create(ctx, cm, metav1.CreateOptions{})
+			if apierrors.IsAlreadyExists(err) {
+				// another replica created it first so lets retry as an update
+				return apierrors.NewConflict(v1.Resource("configmaps"), s.name, err)
+			}
+			return err
+		}
+		_, err = configMaps.Update(ctx, cm, metav1.UpdateOptions{})
+		return err
+	})
+}
@@ -0,0 +1,63 @@
+package pollstate
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+type fileStore struct {
+	path string
+	lock sync.Mutex
+}
+
+// NewFilePollState creates a new poll state stored as JSON in the given local file.
+// It is mostly useful for testing and for running the poller outside of kubernetes.
+func NewFilePollState(path string) (Interface, error) {
+	if path == "" {
+		return nil, errors.New("no poll state file given")
+	}
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, errors.Wrapf(err, "failed to create directory %s", dir)
+	}
+	logger := logrus.WithField("PollStateFile", path)
+	return newPersistentPollState(&fileStore{path: path}, logger), nil
+}
+
+func (s *fileStore) modify(fn func(state map[string]entry) bool) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	state := map[string]entry{}
+	data, err := os.ReadFile(s.path)
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrapf(err, "failed to read poll state file %s", s.path)
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &state); err != nil {
+			return errors.Wrapf(err, "failed to unmarshal poll state file %s", s.path)
+		}
+	}
+	if !fn(state) {
+		return nil
+	}
+	data, err = json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal poll state")
+	}
+
+	// write to a temporary file first so that a crash never leaves a truncated state behind
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return errors.Wrapf(err, "failed to write poll state file %s", tmp)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return errors.Wrapf(err, "failed to rename %s to %s", tmp, s.path)
+	}
+	return nil
+}
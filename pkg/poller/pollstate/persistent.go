@@ -0,0 +1,89 @@
+package pollstate
+
+import (
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// stateTTL is how long the entries which are no longer checked, e.g. of closed pull requests, are kept
+	stateTTL = 7 * 24 * time.Hour
+
+	// lastSeenResolution bounds how often the last time an unchanged entry was checked is saved
+	lastSeenResolution = time.Hour
+)
+
+// entry the last value seen for a repository operation
+type entry struct {
+	Value    string    `json:"value"`
+	LastSeen time.Time `json:"lastSeen"`
+}
+
+// store loads and saves the whole poll state
+type store interface {
+	// modify invokes the given function with the current state, saving the state if the function returns true
+	modify(fn func(state map[string]entry) bool) error
+}
+
+type persistentPollstate struct {
+	store  store
+	logger *logrus.Entry
+	now    func() time.Time
+}
+
+func newPersistentPollState(s store, logger *logrus.Entry) Interface {
+	return &persistentPollstate{
+		store:  s,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+func (p *persistentPollstate) IsNew(repository, operation, newValue string) (bool, error) {
+	key := repository + ":" + operation
+
+	answer := false
+	err := p.store.modify(func(state map[string]entry) bool {
+		now := p.now()
+		e, ok := state[key]
+		answer = e.Value != newValue
+		if !answer && ok && now.Sub(e.LastSeen) < lastSeenResolution {
+			return false
+		}
+		state[key] = entry{Value: newValue, LastSeen: now}
+		prune(state, now)
+		return true
+	})
+	if err != nil {
+		return false, err
+	}
+	return answer, nil
+}
+
+func (p *persistentPollstate) Invalidate(repository, operation, invalidValue string) {
+	key := repository + ":" + operation
+
+	err := p.store.modify(func(state map[string]entry) bool {
+		e, ok := state[key]
+		if ok && e.Value == invalidValue {
+			delete(state, key)
+			prune(state, p.now())
+			return true
+		}
+		return false
+	})
+	if err != nil {
+		p.logger.WithError(err).WithField("Key", key).Warn("failed to invalidate poll state")
+	}
+}
+
+// prune removes the entries which have not been checked for longer than the TTL so that the state does not
+// keep growing with the pull requests which have been closed
+func prune(state map[string]entry, now time.Time) {
+	for key, e := range state {
+		if now.Sub(e.LastSeen) > stateTTL {
+			delete(state, key)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package pollstate
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPersistentPollStatePrunesStaleEntries(t *testing.T) {
+	s := &fileStore{path: filepath.Join(t.TempDir(), "state.json")}
+	now := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	ps := &persistentPollstate{
+		store:  s,
+		logger: logrus.WithField("test", t.Name()),
+		now: func() time.Time {
+			return now
+		},
+	}
+	isNew := func(operation, value string) bool {
+		answer, err := ps.IsNew("myorg/myrepo", operation, value)
+		require.NoError(t, err)
+		return answer
+	}
+	keys := func() []string {
+		var answer []string
+		require.NoError(t, s.modify(func(state map[string]entry) bool {
+			for key := range state {
+				answer = append(answer, key)
+			}
+			return false
+		}))
+		return answer
+	}
+
+	assert.True(t, isNew("release", "sha1"))
+	assert.True(t, isNew("PR-1-push", "sha1"))
+
+	// the release is checked at every poll while the pull request has been closed
+	for i := 0; i < 8; i++ {
+		now = now.Add(24 * time.Hour)
+		assert.False(t, isNew("release", "sha1"))
+	}
+	assert.ElementsMatch(t, []string{"myorg/myrepo:release"}, keys())
+}
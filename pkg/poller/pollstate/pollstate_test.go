@@ -0,0 +1,93 @@
+package pollstate_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x/lighthouse/pkg/poller/pollstate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const (
+	repository = "myorg/myrepo"
+	ns         = "jx"
+)
+
+func TestPollState(t *testing.T) {
+	dir := t.TempDir()
+	kubeClient := fake.NewSimpleClientset()
+
+	testCases := []struct {
+		name   string
+		create func() pollstate.Interface
+	}{
+		{
+			name: "memory",
+			create: func() pollstate.Interface {
+				return pollstate.NewMemoryPollState()
+			},
+		},
+		{
+			name: "file",
+			create: func() pollstate.Interface {
+				ps, err := pollstate.NewFilePollState(filepath.Join(dir, "state.json"))
+				require.NoError(t, err)
+				return ps
+			},
+		},
+		{
+			name: "configmap",
+			create: func() pollstate.Interface {
+				return pollstate.NewConfigMapPollState(kubeClient, ns, "")
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ps := tc.create()
+
+			assertIsNew(t, ps, "release", "sha1", true)
+			assertIsNew(t, ps, "release", "sha1", false)
+			assertIsNew(t, ps, "PR-1", "created", true)
+			assertIsNew(t, ps, "release", "sha2", true)
+
+			// invalidating an older value keeps the current one
+			ps.Invalidate(repository, "release", "sha1")
+			assertIsNew(t, ps, "release", "sha2", false)
+
+			ps.Invalidate(repository, "release", "sha2")
+			assertIsNew(t, ps, "release", "sha2", true)
+		})
+	}
+}
+
+func TestPersistentPollStateSurvivesRestarts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "state.json")
+	ps, err := pollstate.NewFilePollState(path)
+	require.NoError(t, err)
+	assertIsNew(t, ps, "release", "sha1", true)
+
+	ps, err = pollstate.NewFilePollState(path)
+	require.NoError(t, err)
+	assertIsNew(t, ps, "release", "sha1", false)
+
+	kubeClient := fake.NewSimpleClientset()
+	replica1 := pollstate.NewConfigMapPollState(kubeClient, ns, "")
+	replica2 := pollstate.NewConfigMapPollState(kubeClient, ns, "")
+	assertIsNew(t, replica1, "PR-1-push", "sha1", true)
+	assertIsNew(t, replica2, "PR-1-push", "sha1", false)
+
+	cm, err := kubeClient.CoreV1().ConfigMaps(ns).Get(context.TODO(), pollstate.DefaultConfigMapName, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Contains(t, cm.Data["state.json"], `"myorg/myrepo:PR-1-push":{"value":"sha1","lastSeen":`)
+}
+
+func assertIsNew(t *testing.T, ps pollstate.Interface, operation, value string, expected bool) {
+	actual, err := ps.IsNew(repository, operation, value)
+	require.NoError(t, err)
+	assert.Equal(t, expected, actual, "IsNew for operation %s with value %s", operation, value)
+}
@@ -0,0 +1,67 @@
+package pubsub
+
+import (
+	"context"
+	"sync"
+)
+
+// Message is a message pulled from a subscription
+type Message struct {
+	// ID is the unique ID of the message, set by the service on publishing
+	ID string
+	// Data is the payload of the message
+	Data []byte
+	// Attributes are the attributes set by the publisher
+	Attributes map[string]string
+
+	once sync.Once
+	ack  func(bool)
+}
+
+// NewMessage creates a message calling done with true when acknowledged or false when
+// negatively acknowledged. It is meant for Client implementations.
+func NewMessage(id string, data []byte, attributes map[string]string, done func(ack bool)) *Message {
+	return &Message{
+		ID:         id,
+		Data:       data,
+		Attributes: attributes,
+		ack:        done,
+	}
+}
+
+// Ack acknowledges the message so that it is not delivered again
+func (m *Message) Ack() {
+	m.done(true)
+}
+
+// Nack negatively acknowledges the message so that it is delivered again
+func (m *Message) Nack() {
+	m.done(false)
+}
+
+func (m *Message) done(ack bool) {
+	m.once.Do(func() {
+		if m.ack != nil {
+			m.ack(ack)
+		}
+	})
+}
+
+// Client gives access to the subscriptions of a message queue service
+type Client interface {
+	// Subscription returns the subscription with the given ID in the given project
+	Subscription(project, id string) Subscription
+}
+
+// Subscription delivers the messages published to a topic
+type Subscription interface {
+	// String returns the fully qualified name of the subscription
+	String() string
+	// Receive calls f with each message until the context is done or a non retryable error occurs.
+	// f must either Ack or Nack the message.
+	Receive(ctx context.Context, f func(context.Context, *Message)) error
+}
+
+func subscriptionName(project, id string) string {
+	return "projects/" + project + "/subscriptions/" + id
+}
@@ -0,0 +1,137 @@
+package pubsub
+
+import (
+	"context"
+	"strconv"
+	"sync"
+)
+
+// MemoryClient is an in-memory emulator of a message queue service, used to run and test
+// lighthouse without the cloud service. Messages are published directly to a subscription
+// and the messages which are negatively acknowledged are delivered again.
+type MemoryClient struct {
+	mut           sync.Mutex
+	nextID        int
+	subscriptions map[string]*memorySubscription
+}
+
+// NewMemoryClient creates a new in-memory client
+func NewMemoryClient() *MemoryClient {
+	return &MemoryClient{
+		subscriptions: map[string]*memorySubscription{},
+	}
+}
+
+// Subscription returns the subscription with the given ID, creating it if it does not exist yet
+func (c *MemoryClient) Subscription(project, id string) Subscription {
+	return c.subscription(project, id)
+}
+
+// Publish adds a message to the given subscription and returns its ID
+func (c *MemoryClient) Publish(project, id string, data []byte, attributes map[string]string) string {
+	c.mut.Lock()
+	c.nextID++
+	msgID := strconv.Itoa(c.nextID)
+	c.mut.Unlock()
+
+	c.subscription(project, id).push(memoryMessage{id: msgID, data: data, attributes: attributes})
+	return msgID
+}
+
+// Acked returns the IDs of the messages of the subscription which have been acknowledged
+func (c *MemoryClient) Acked(project, id string) []string {
+	s := c.subscription(project, id)
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	return append([]string(nil), s.acked...)
+}
+
+// Pending returns the number of messages of the subscription waiting to be delivered or acknowledged
+func (c *MemoryClient) Pending(project, id string) int {
+	s := c.subscription(project, id)
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	return len(s.queue) + s.inFlight
+}
+
+func (c *MemoryClient) subscription(project, id string) *memorySubscription {
+	name := subscriptionName(project, id)
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	s := c.subscriptions[name]
+	if s == nil {
+		s = &memorySubscription{name: name, notify: make(chan struct{}, 1)}
+		c.subscriptions[name] = s
+	}
+	return s
+}
+
+type memoryMessage struct {
+	id         string
+	data       []byte
+	attributes map[string]string
+}
+
+type memorySubscription struct {
+	name     string
+	mut      sync.Mutex
+	queue    []memoryMessage
+	inFlight int
+	acked    []string
+	notify   chan struct{}
+}
+
+func (s *memorySubscription) String() string {
+	return s.name
+}
+
+func (s *memorySubscription) push(m memoryMessage) {
+	s.mut.Lock()
+	s.queue = append(s.queue, m)
+	s.mut.Unlock()
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (s *memorySubscription) pop() (memoryMessage, bool) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	if len(s.queue) == 0 {
+		return memoryMessage{}, false
+	}
+	m := s.queue[0]
+	s.queue = s.queue[1:]
+	s.inFlight++
+	return m, true
+}
+
+// Receive delivers the messages one at a time until the context is done
+func (s *memorySubscription) Receive(ctx context.Context, f func(context.Context, *Message)) error {
+	for {
+		m, ok := s.pop()
+		if !ok {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-s.notify:
+				continue
+			}
+		}
+		f(ctx, NewMessage(m.id, m.data, m.attributes, func(ack bool) {
+			s.mut.Lock()
+			s.inFlight--
+			if ack {
+				s.acked = append(s.acked, m.id)
+			}
+			s.mut.Unlock()
+			if !ack {
+				s.push(m)
+			}
+		}))
+		if ctx.Err() != nil {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package pubsub
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+const (
+	// EmulatorHostEnv is the environment variable holding the host of a local Pub/Sub emulator,
+	// it is honoured the same way as by the Google Cloud client libraries
+	EmulatorHostEnv = "PUBSUB_EMULATOR_HOST"
+
+	defaultEndpoint = "https://pubsub.googleapis.com/v1"
+	pubsubScope     = "https://www.googleapis.com/auth/pubsub"
+
+	maxMessages = 10
+)
+
+// RESTClient pulls messages from Google Cloud Pub/Sub using its REST API
+type RESTClient struct {
+	endpoint string
+
+	lock       sync.Mutex
+	httpClient *http.Client
+}
+
+// NewRESTClient creates a client for Google Cloud Pub/Sub. If $PUBSUB_EMULATOR_HOST is set the client
+// connects to the emulator, otherwise it authenticates with the Application Default Credentials such as
+// Workload Identity or the GOOGLE_APPLICATION_CREDENTIALS environment variable.
+func NewRESTClient() *RESTClient {
+	if host := os.Getenv(EmulatorHostEnv); host != "" {
+		return &RESTClient{
+			endpoint:   "http://" + host + "/v1",
+			httpClient: http.DefaultClient,
+		}
+	}
+	return &RESTClient{endpoint: defaultEndpoint}
+}
+
+// client lazily creates the authenticated HTTP client so that the credentials are only required
+// once a subscription is pulled
+func (c *RESTClient) client() (*http.Client, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.httpClient != nil {
+		return c.httpClient, nil
+	}
+	ts, err := google.DefaultTokenSource(context.Background(), pubsubScope)
+	if err != nil {
+		return nil, errors.Wrap(err, "finding the default Google Cloud credentials")
+	}
+	c.httpClient = oauth2.NewClient(context.Background(), ts)
+	return c.httpClient, nil
+}
+
+// Subscription returns the subscription with the given ID in the given project
+func (c *RESTClient) Subscription(project, id string) Subscription {
+	return &restSubscription{
+		client: c,
+		name:   subscriptionName(project, id),
+	}
+}
+
+type restSubscription struct {
+	client *RESTClient
+	name   string
+}
+
+type pullResponse struct {
+	ReceivedMessages []struct {
+		AckID   string `json:"ackId"`
+		Message struct {
+			Data       string            `json:"data"`
+			Attributes map[string]string `json:"attributes"`
+			MessageID  string            `json:"messageId"`
+		} `json:"message"`
+	} `json:"receivedMessages"`
+}
+
+func (s *restSubscription) String() string {
+	return s.name
+}
+
+// Receive pulls the messages of the subscription until the context is done
+func (s *restSubscription) Receive(ctx context.Context, f func(context.Context, *Message)) error {
+	for ctx.Err() == nil {
+		resp := pullResponse{}
+		err := s.call(ctx, "pull", map[string]interface{}{"maxMessages": maxMessages}, &resp)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		for _, rm := range resp.ReceivedMessages {
+			ackID := rm.AckID
+			data, err := base64.StdEncoding.DecodeString(rm.Message.Data)
+			if err != nil {
+				return errors.Wrapf(err, "failed to decode the data of message %s", rm.Message.MessageID)
+			}
+			f(ctx, NewMessage(rm.Message.MessageID, data, rm.Message.Attributes, func(ack bool) {
+				// the message is delivered again after the ack deadline if acknowledging fails
+				if ack {
+					_ = s.call(context.Background(), "acknowledge", map[string]interface{}{"ackIds": []string{ackID}}, nil)
+				} else {
+					_ = s.call(context.Background(), "modifyAckDeadline", map[string]interface{}{"ackIds": []string{ackID}, "ackDeadlineSeconds": 0}, nil)
+				}
+			}))
+		}
+	}
+	return nil
+}
+
+func (s *restSubscription) call(ctx context.Context, method string, body, result interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	u := fmt.Sprintf("%s/%s:%s", s.client.endpoint, s.name, method)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	httpClient, err := s.client.client()
+	if err != nil {
+		return err
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "failed to call %s on %s", method, s.name)
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read the response of %s on %s", method, s.name)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("failed to call %s on %s: status %d: %s", method, s.name, resp.StatusCode, string(respBody))
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(respBody, result)
+}
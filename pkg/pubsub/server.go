@@ -0,0 +1,96 @@
+package pubsub
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/jenkins-x/lighthouse/pkg/config"
+	"github.com/jenkins-x/lighthouse/pkg/config/lighthouse"
+	"github.com/sirupsen/logrus"
+)
+
+// retryDelay is the time to wait before receiving messages again after a subscription failed
+var retryDelay = 30 * time.Second
+
+// PullServer pulls the messages of the subscriptions listed in the `pubsub_subscriptions` configuration
+type PullServer struct {
+	Subscriber *Subscriber
+	Client     Client
+}
+
+// NewPullServer creates a new pull server
+func NewPullServer(subscriber *Subscriber, client Client) *PullServer {
+	return &PullServer{
+		Subscriber: subscriber,
+		Client:     client,
+	}
+}
+
+// Run pulls messages until the context is done, restarting the subscriptions whenever
+// they are changed in the configuration
+func (p *PullServer) Run(ctx context.Context) {
+	configEvent := make(chan config.Delta, 2)
+	p.Subscriber.ConfigAgent.Subscribe(configEvent)
+
+	subscriptions := p.Subscriber.ConfigAgent.Config().PubSubSubscriptions
+	for {
+		subCtx, cancel := context.WithCancel(ctx)
+		wg := p.receiveAll(subCtx, subscriptions)
+	waitForChange:
+		for {
+			select {
+			case <-ctx.Done():
+				cancel()
+				wg.Wait()
+				return
+			case delta := <-configEvent:
+				if reflect.DeepEqual(delta.Before.PubSubSubscriptions, delta.After.PubSubSubscriptions) {
+					continue
+				}
+				logrus.Info("Pub/Sub subscriptions changed, restarting the subscriptions.")
+				subscriptions = delta.After.PubSubSubscriptions
+				break waitForChange
+			}
+		}
+		cancel()
+		wg.Wait()
+	}
+}
+
+func (p *PullServer) receiveAll(ctx context.Context, subscriptions lighthouse.PubsubSubscriptions) *sync.WaitGroup {
+	wg := &sync.WaitGroup{}
+	for project, ids := range subscriptions {
+		for _, id := range ids {
+			sub := p.Client.Subscription(project, id)
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				p.receive(ctx, sub)
+			}()
+		}
+	}
+	return wg
+}
+
+// receive handles the messages of a subscription until the context is done, receiving again after errors
+func (p *PullServer) receive(ctx context.Context, sub Subscription) {
+	l := logrus.WithField("subscription", sub.String())
+	l.Info("Listening for messages.")
+	for {
+		err := sub.Receive(ctx, func(_ context.Context, msg *Message) {
+			p.Subscriber.handleMessage(msg, sub.String())
+		})
+		if ctx.Err() != nil {
+			l.Info("Stopped listening for messages.")
+			return
+		}
+		l.WithError(err).Warnf("Failed to receive messages, retrying in %s.", retryDelay)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(retryDelay):
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package pubsub
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config"
+	"github.com/jenkins-x/lighthouse/pkg/jobutil"
+	"github.com/jenkins-x/lighthouse/pkg/launcher"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+const (
+	// EventTypeAttribute is the message attribute holding the type of event
+	EventTypeAttribute = "lighthouse.jenkins-x.io/pubsub.EventType"
+	// PeriodicLighthouseJobEvent is the type of event triggering a periodic job
+	PeriodicLighthouseJobEvent = "lighthouse.jenkins-x.io/pubsub.PeriodicLighthouseJobEvent"
+	// AdHocLighthouseJobEvent is the type of event triggering a postsubmit job against the given refs
+	AdHocLighthouseJobEvent = "lighthouse.jenkins-x.io/pubsub.AdHocLighthouseJobEvent"
+
+	// SubscriptionAnnotation is the annotation holding the subscription a LighthouseJob was triggered from
+	SubscriptionAnnotation = "lighthouse.jenkins-x.io/pubsub.subscription"
+	// MessageIDAnnotation is the annotation holding the ID of the message a LighthouseJob was triggered by
+	MessageIDAnnotation = "lighthouse.jenkins-x.io/pubsub.messageID"
+)
+
+var (
+	messageCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "lighthouse_pubsub_message_counter",
+		Help: "A counter of the messages pulled from Pub/Sub subscriptions.",
+	}, []string{"subscription", "event_type"})
+	errorCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "lighthouse_pubsub_error_counter",
+		Help: "A counter of the messages from Pub/Sub subscriptions which failed to trigger a job.",
+	}, []string{"subscription", "event_type"})
+)
+
+// LighthouseJobEvent is the payload of the messages triggering LighthouseJobs
+type LighthouseJobEvent struct {
+	// Name is the name of the job to trigger
+	Name string `json:"name"`
+	// Refs are the git references the job runs against. They are required for ad-hoc jobs,
+	// for periodic jobs they override the repository and branch the job is configured with.
+	Refs *v1alpha1.Refs `json:"refs,omitempty"`
+	// Labels are added to the LighthouseJob
+	Labels map[string]string `json:"labels,omitempty"`
+	// Annotations are added to the LighthouseJob
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// Subscriber turns messages into LighthouseJobs
+type Subscriber struct {
+	ConfigAgent    *config.Agent
+	LauncherClient launcher.PipelineLauncher
+}
+
+// handleMessage triggers the job described by the message. The message is only negatively
+// acknowledged when launching the job failed, invalid messages are dropped. Pub/Sub delivers the
+// messages at least once so the job is named after the message to be only launched once.
+func (s *Subscriber) handleMessage(msg *Message, subscription string) {
+	eventType := msg.Attributes[EventTypeAttribute]
+	l := logrus.WithFields(logrus.Fields{
+		"subscription": subscription,
+		"message-id":   msg.ID,
+		"event-type":   eventType,
+	})
+	messageCounter.WithLabelValues(subscription, eventType).Inc()
+
+	pj, err := s.lighthouseJobForMessage(l, msg, subscription)
+	if err != nil {
+		errorCounter.WithLabelValues(subscription, eventType).Inc()
+		l.WithError(err).Error("Dropping invalid message.")
+		msg.Ack()
+		return
+	}
+	l = l.WithFields(jobutil.LighthouseJobFields(pj))
+	_, err = s.LauncherClient.Launch(pj)
+	if apierrors.IsAlreadyExists(err) {
+		l.Info("LighthouseJob already launched for a previous delivery of the message.")
+		msg.Ack()
+		return
+	}
+	if err != nil {
+		errorCounter.WithLabelValues(subscription, eventType).Inc()
+		l.WithError(err).Error("Failed to launch LighthouseJob, the message will be delivered again.")
+		msg.Nack()
+		return
+	}
+	l.Info("Launched LighthouseJob.")
+	msg.Ack()
+}
+
+func (s *Subscriber) lighthouseJobForMessage(l *logrus.Entry, msg *Message, subscription string) (*v1alpha1.LighthouseJob, error) {
+	event := LighthouseJobEvent{}
+	if err := json.Unmarshal(msg.Data, &event); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal the message data")
+	}
+	if event.Name == "" {
+		return nil, errors.New("no job name in the message")
+	}
+	labels := map[string]string{}
+	annotations := map[string]string{
+		SubscriptionAnnotation: subscription,
+		MessageIDAnnotation:    msg.ID,
+	}
+
+	cfg := s.ConfigAgent.Config()
+	var spec v1alpha1.LighthouseJobSpec
+	switch eventType := msg.Attributes[EventTypeAttribute]; eventType {
+	case PeriodicLighthouseJobEvent:
+		found := false
+		for _, p := range cfg.Periodics {
+			if p.Name != event.Name {
+				continue
+			}
+			refs := v1alpha1.Refs{
+				BaseRef:  p.Branch,
+				CloneURI: p.CloneURI,
+			}
+			if event.Refs != nil {
+				refs = *event.Refs
+			}
+			spec = jobutil.PeriodicSpec(l, p, refs)
+			mergeMaps(labels, p.Labels)
+			mergeMaps(annotations, p.Annotations)
+			found = true
+			break
+		}
+		if !found {
+			return nil, errors.Errorf("failed to find the periodic job %s", event.Name)
+		}
+	case AdHocLighthouseJobEvent:
+		if event.Refs == nil || event.Refs.Org == "" || event.Refs.Repo == "" || event.Refs.BaseRef == "" {
+			return nil, errors.New("refs with an org, repo and base ref are required for ad-hoc jobs")
+		}
+		repo := scm.Repository{
+			Namespace: event.Refs.Org,
+			Name:      event.Refs.Repo,
+			FullName:  fmt.Sprintf("%s/%s", event.Refs.Org, event.Refs.Repo),
+		}
+		found := false
+		for _, p := range cfg.GetPostsubmits(repo) {
+			if p.Name != event.Name {
+				continue
+			}
+			spec = jobutil.PostsubmitSpec(l, p, *event.Refs)
+			mergeMaps(labels, p.Labels)
+			mergeMaps(annotations, p.Annotations)
+			found = true
+			break
+		}
+		if !found {
+			return nil, errors.Errorf("failed to find the postsubmit job %s in %s", event.Name, repo.FullName)
+		}
+	default:
+		return nil, errors.Errorf("unsupported event type %q, expected one of %s", eventType, strings.Join([]string{PeriodicLighthouseJobEvent, AdHocLighthouseJobEvent}, ", "))
+	}
+	mergeMaps(labels, event.Labels)
+	mergeMaps(annotations, event.Annotations)
+
+	pj := jobutil.NewLighthouseJob(spec, labels, annotations)
+	pj.GenerateName = ""
+	pj.Name = jobutil.DeterministicName(&spec, msg.ID)
+	return &pj, nil
+}
+
+func mergeMaps(dest, src map[string]string) {
+	for k, v := range src {
+		dest[k] = v
+	}
+}
@@ -0,0 +1,230 @@
+package pubsub
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/config/lighthouse"
+	fakelauncher "github.com/jenkins-x/lighthouse/pkg/launcher/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+func newTestSubscriber() (*Subscriber, *fakelauncher.Launcher) {
+	configAgent := &config.Agent{}
+	configAgent.Set(&config.Config{
+		JobConfig: config.JobConfig{
+			Periodics: []job.Periodic{
+				{
+					Base:   job.Base{Name: "nightly", Labels: map[string]string{"team": "infra"}},
+					Cron:   "0 0 * * *",
+					Branch: "main",
+				},
+			},
+			Postsubmits: map[string][]job.Postsubmit{
+				"org/repo": {
+					{Base: job.Base{Name: "deploy"}},
+				},
+			},
+		},
+		ProwConfig: lighthouse.Config{
+			PubSubSubscriptions: lighthouse.PubsubSubscriptions{"my-project": {"jobs"}},
+		},
+	})
+	fakeLauncher := fakelauncher.NewLauncher()
+	return &Subscriber{ConfigAgent: configAgent, LauncherClient: fakeLauncher}, fakeLauncher
+}
+
+func eventData(t *testing.T, event LighthouseJobEvent) []byte {
+	data, err := json.Marshal(event)
+	require.NoError(t, err)
+	return data
+}
+
+func TestHandleMessage(t *testing.T) {
+	testCases := []struct {
+		name          string
+		eventType     string
+		data          []byte
+		failJobs      []string
+		expectedJob   string
+		expectedType  job.PipelineKind
+		expectedRefs  *v1alpha1.Refs
+		expectedLabel string
+		expectAck     bool
+	}{
+		{
+			name:          "periodic job",
+			eventType:     PeriodicLighthouseJobEvent,
+			data:          eventData(t, LighthouseJobEvent{Name: "nightly", Labels: map[string]string{"extra": "yes"}}),
+			expectedJob:   "nightly",
+			expectedType:  job.PeriodicJob,
+			expectedRefs:  &v1alpha1.Refs{BaseRef: "main"},
+			expectedLabel: "infra",
+			expectAck:     true,
+		},
+		{
+			name:      "periodic job overriding refs",
+			eventType: PeriodicLighthouseJobEvent,
+			data: eventData(t, LighthouseJobEvent{
+				Name: "nightly",
+				Refs: &v1alpha1.Refs{Org: "org", Repo: "repo", BaseRef: "release"},
+			}),
+			expectedJob:   "nightly",
+			expectedType:  job.PeriodicJob,
+			expectedRefs:  &v1alpha1.Refs{Org: "org", Repo: "repo", BaseRef: "release"},
+			expectedLabel: "infra",
+			expectAck:     true,
+		},
+		{
+			name:      "ad-hoc job",
+			eventType: AdHocLighthouseJobEvent,
+			data: eventData(t, LighthouseJobEvent{
+				Name: "deploy",
+				Refs: &v1alpha1.Refs{Org: "org", Repo: "repo", BaseRef: "main", BaseSHA: "abc"},
+			}),
+			expectedJob:  "deploy",
+			expectedType: job.PostsubmitJob,
+			expectedRefs: &v1alpha1.Refs{Org: "org", Repo: "repo", BaseRef: "main", BaseSHA: "abc"},
+			expectAck:    true,
+		},
+		{
+			name:      "ad-hoc job without refs",
+			eventType: AdHocLighthouseJobEvent,
+			data:      eventData(t, LighthouseJobEvent{Name: "deploy"}),
+			expectAck: true,
+		},
+		{
+			name:      "unknown job",
+			eventType: PeriodicLighthouseJobEvent,
+			data:      eventData(t, LighthouseJobEvent{Name: "weekly"}),
+			expectAck: true,
+		},
+		{
+			name:      "unknown event type",
+			eventType: "something",
+			data:      eventData(t, LighthouseJobEvent{Name: "nightly"}),
+			expectAck: true,
+		},
+		{
+			name:      "invalid payload",
+			eventType: PeriodicLighthouseJobEvent,
+			data:      []byte("not json"),
+			expectAck: true,
+		},
+		{
+			name:      "launch failure",
+			eventType: PeriodicLighthouseJobEvent,
+			data:      eventData(t, LighthouseJobEvent{Name: "nightly"}),
+			failJobs:  []string{"nightly"},
+			expectAck: false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, fakeLauncher := newTestSubscriber()
+			fakeLauncher.FailJobs = sets.NewString(tc.failJobs...)
+			var acked *bool
+			msg := NewMessage("1", tc.data, map[string]string{EventTypeAttribute: tc.eventType}, func(ack bool) {
+				acked = &ack
+			})
+
+			s.handleMessage(msg, "projects/my-project/subscriptions/jobs")
+
+			require.NotNil(t, acked)
+			assert.Equal(t, tc.expectAck, *acked)
+			if tc.expectedJob == "" {
+				assert.Empty(t, fakeLauncher.Pipelines)
+				return
+			}
+			require.Len(t, fakeLauncher.Pipelines, 1)
+			pj := fakeLauncher.Pipelines[0]
+			assert.Equal(t, tc.expectedJob, pj.Spec.Job)
+			assert.Equal(t, tc.expectedType, pj.Spec.Type)
+			assert.Equal(t, tc.expectedRefs.Org, pj.Spec.Refs.Org)
+			assert.Equal(t, tc.expectedRefs.Repo, pj.Spec.Refs.Repo)
+			assert.Equal(t, tc.expectedRefs.BaseRef, pj.Spec.Refs.BaseRef)
+			assert.Equal(t, tc.expectedRefs.BaseSHA, pj.Spec.Refs.BaseSHA)
+			if tc.expectedLabel != "" {
+				assert.Equal(t, tc.expectedLabel, pj.Labels["team"])
+			}
+			assert.Equal(t, "projects/my-project/subscriptions/jobs", pj.Annotations[SubscriptionAnnotation])
+			assert.Equal(t, "1", pj.Annotations[MessageIDAnnotation])
+		})
+	}
+}
+
+func TestHandleMessageRedelivery(t *testing.T) {
+	s, fakeLauncher := newTestSubscriber()
+	data := eventData(t, LighthouseJobEvent{Name: "nightly"})
+	attributes := map[string]string{EventTypeAttribute: PeriodicLighthouseJobEvent}
+	var acks []bool
+	ack := func(ack bool) {
+		acks = append(acks, ack)
+	}
+
+	s.handleMessage(NewMessage("1", data, attributes, ack), "projects/my-project/subscriptions/jobs")
+	s.handleMessage(NewMessage("1", data, attributes, ack), "projects/my-project/subscriptions/jobs")
+	assert.Equal(t, []bool{true, true}, acks, "the redelivered message should be acknowledged")
+	require.Len(t, fakeLauncher.Pipelines, 1, "the job should only be launched once per message")
+
+	s.handleMessage(NewMessage("2", data, attributes, ack), "projects/my-project/subscriptions/jobs")
+	assert.Len(t, fakeLauncher.Pipelines, 2)
+}
+
+func TestPullServerWithMemoryClient(t *testing.T) {
+	s, fakeLauncher := newTestSubscriber()
+	client := NewMemoryClient()
+	attributes := map[string]string{EventTypeAttribute: PeriodicLighthouseJobEvent}
+	client.Publish("my-project", "jobs", eventData(t, LighthouseJobEvent{Name: "nightly"}), attributes)
+	client.Publish("my-project", "jobs", []byte("not json"), attributes)
+	client.Publish("my-project", "other", eventData(t, LighthouseJobEvent{Name: "nightly"}), attributes)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		NewPullServer(s, client).Run(ctx)
+		close(done)
+	}()
+
+	require.Eventually(t, func() bool {
+		return client.Pending("my-project", "jobs") == 0
+	}, 5*time.Second, 10*time.Millisecond)
+	cancel()
+	<-done
+
+	assert.Equal(t, []string{"1", "2"}, client.Acked("my-project", "jobs"))
+	// the subscription is not configured so its messages are left alone
+	assert.Equal(t, 1, client.Pending("my-project", "other"))
+	require.Len(t, fakeLauncher.Pipelines, 1)
+	assert.Equal(t, "nightly", fakeLauncher.Pipelines[0].Spec.Job)
+}
+
+func TestMemoryClientRedeliversNackedMessages(t *testing.T) {
+	client := NewMemoryClient()
+	client.Publish("my-project", "jobs", []byte("hello"), nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	deliveries := 0
+	err := client.Subscription("my-project", "jobs").Receive(ctx, func(_ context.Context, msg *Message) {
+		deliveries++
+		assert.Equal(t, "hello", string(msg.Data))
+		if deliveries == 1 {
+			msg.Nack()
+			return
+		}
+		msg.Ack()
+		cancel()
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, deliveries)
+	assert.Equal(t, []string{"1"}, client.Acked("my-project", "jobs"))
+	assert.Equal(t, 0, client.Pending("my-project", "jobs"))
+}
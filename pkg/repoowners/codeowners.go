@@ -0,0 +1,255 @@
+package repoowners
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const codeOwnersFileName = "CODEOWNERS"
+
+// codeOwnersLocations are the locations searched for a CODEOWNERS file, in the same order as GitHub
+var codeOwnersLocations = []string{
+	filepath.Join(".github", codeOwnersFileName),
+	codeOwnersFileName,
+	filepath.Join("docs", codeOwnersFileName),
+}
+
+// CodeOwnersRule is a single line of a CODEOWNERS file
+type CodeOwnersRule struct {
+	Pattern string
+	Owners  []string
+}
+
+// ParseCodeOwners parses the content of a CODEOWNERS file into its rules, in the order they appear
+func ParseCodeOwners(b []byte) ([]CodeOwnersRule, error) {
+	var rules []CodeOwnersRule
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if idx := strings.Index(line, "#"); idx >= 0 && (idx == 0 || line[idx-1] != '\\') {
+			line = strings.TrimSpace(line[:idx])
+		}
+		if line == "" {
+			continue
+		}
+		fields := strings.Fields(line)
+		pattern := strings.ReplaceAll(fields[0], `\#`, "#")
+		if strings.HasPrefix(pattern, "!") {
+			return nil, fmt.Errorf("line %d: negated pattern %q is not supported", lineNumber, pattern)
+		}
+		rules = append(rules, CodeOwnersRule{
+			Pattern: pattern,
+			Owners:  fields[1:],
+		})
+	}
+	return rules, scanner.Err()
+}
+
+// codeOwnersPath converts a gitignore style CODEOWNERS pattern into the directory it applies to and
+// an optional regexp matching the paths relative to that directory, as used for OWNERS filters.
+// A nil regexp means the pattern owns the whole directory, or the single file at that path. The
+// pattern is only classified from its syntax as the files may not be checked out.
+func codeOwnersPath(pattern string) (string, *regexp.Regexp, error) {
+	dirOnly := strings.HasSuffix(pattern, "/")
+	trimmed := strings.Trim(pattern, "/")
+	anchored := strings.HasPrefix(pattern, "/") || strings.Contains(trimmed, "/")
+	if trimmed == "" || trimmed == "*" || trimmed == "**" {
+		return baseDirConvention, nil, nil
+	}
+
+	segments := strings.Split(trimmed, "/")
+	dir := baseDirConvention
+	if anchored {
+		i := 0
+		for i < len(segments) && !strings.ContainsAny(segments[i], "*?[\\") {
+			i++
+		}
+		dir = strings.Join(segments[:i], "/")
+		segments = segments[i:]
+		if len(segments) == 0 {
+			// a literal path owns the file or the whole directory at that path, whichever it is
+			return dir, nil, nil
+		}
+	}
+
+	expr := globToRegexp(strings.Join(segments, "/"))
+	if !anchored {
+		expr = "(^|.*/)" + expr
+	} else {
+		expr = "^" + expr
+	}
+	if dirOnly {
+		expr += "/.*$"
+	} else {
+		expr += "(/.*)?$"
+	}
+	re, err := regexp.Compile(expr)
+	return dir, re, err
+}
+
+// globToRegexp converts a gitignore style glob into a regular expression
+func globToRegexp(glob string) string {
+	var buf strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			buf.WriteString("(.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "/**"):
+			buf.WriteString("/.*")
+			i += 2
+		case c == '*':
+			buf.WriteString("[^/]*")
+		case c == '?':
+			buf.WriteString("[^/]")
+		case c == '\\' && i+1 < len(glob):
+			i++
+			buf.WriteString(regexp.QuoteMeta(string(glob[i])))
+		case c == '[':
+			end := strings.IndexByte(glob[i:], ']')
+			if end < 0 {
+				buf.WriteString(regexp.QuoteMeta(string(c)))
+				continue
+			}
+			class := glob[i+1 : i+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			buf.WriteString("[" + class + "]")
+			i += end
+		default:
+			buf.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return buf.String()
+}
+
+// codeOwnersEntry is a CODEOWNERS rule resolved into the directory it applies to, the optional regexp
+// matching the paths relative to that directory and the logins of its owners
+type codeOwnersEntry struct {
+	dir    string
+	re     *regexp.Regexp
+	owners sets.String
+}
+
+// loadCodeOwnersFrom applies the first CODEOWNERS file found in the repository to the owners.
+// Owners listed in CODEOWNERS are both approvers and reviewers, teams written as @org/team are
+// expanded into their members. As on GitHub, a path is owned by the last rule matching it only,
+// its owners are added to the ones of the OWNERS files of the directories containing the path.
+func (c *Client) loadCodeOwnersFrom(o *RepoOwners, log *logrus.Entry) {
+	for _, location := range codeOwnersLocations {
+		path := filepath.Join(o.baseDir, location)
+		b, err := os.ReadFile(path) // #nosec
+		if os.IsNotExist(err) {
+			continue
+		}
+		log = log.WithField("path", location)
+		if err != nil {
+			log.WithError(err).Warn("Failed to read the CODEOWNERS file.")
+			return
+		}
+		rules, err := ParseCodeOwners(b)
+		if err != nil {
+			log.WithError(err).Error("Failed to parse the CODEOWNERS file.")
+			return
+		}
+		teams := map[string]sets.String{}
+		for _, rule := range rules {
+			dir, re, err := codeOwnersPath(rule.Pattern)
+			if err != nil {
+				log.WithError(err).Errorf("Invalid CODEOWNERS pattern %q.", rule.Pattern)
+				continue
+			}
+			// a rule without owners is kept so that the paths it matches are no longer owned by the previous rules
+			owners := c.expandCodeOwners(rule.Owners, teams, log)
+			o.codeOwners = append(o.codeOwners, codeOwnersEntry{
+				dir:    dir,
+				re:     re,
+				owners: o.ExpandAliases(normLogins(owners)),
+			})
+		}
+		log.Infof("Loaded %d rules from the CODEOWNERS file.", len(rules))
+		return
+	}
+}
+
+// codeOwnersForFile returns the last CODEOWNERS rule matching the given path, or nil if there is none
+func (o *RepoOwners) codeOwnersForFile(path string) *codeOwnersEntry {
+	path = canonicalize(path)
+	for i := len(o.codeOwners) - 1; i >= 0; i-- {
+		entry := &o.codeOwners[i]
+		if entry.dir != baseDirConvention && path != entry.dir && !strings.HasPrefix(path, entry.dir+"/") {
+			continue
+		}
+		relative, err := filepath.Rel(entry.dir, path)
+		if err != nil {
+			continue
+		}
+		if entry.re == nil || entry.re.MatchString(relative) {
+			return entry
+		}
+	}
+	return nil
+}
+
+// expandCodeOwners returns the logins of the given CODEOWNERS owners, expanding @org/team references
+// into the members of the team. Email addresses cannot be mapped to logins and are ignored.
+func (c *Client) expandCodeOwners(owners []string, teams map[string]sets.String, log *logrus.Entry) []string {
+	var logins []string
+	for _, owner := range owners {
+		if !strings.HasPrefix(owner, "@") {
+			log.Debugf("Ignoring CODEOWNERS owner %q which is not a user or a team.", owner)
+			continue
+		}
+		parts := strings.SplitN(strings.TrimPrefix(owner, "@"), "/", 2)
+		if len(parts) == 1 {
+			logins = append(logins, parts[0])
+			continue
+		}
+		key := strings.ToLower(owner)
+		members, ok := teams[key]
+		if !ok {
+			var err error
+			members, err = c.teamMembers(parts[0], parts[1])
+			if err != nil {
+				log.WithError(err).Warnf("Failed to expand CODEOWNERS team %q.", owner)
+			}
+			teams[key] = members
+		}
+		logins = append(logins, members.List()...)
+	}
+	return logins
+}
+
+// teamMembers returns the logins of the members of a team, matching its slug or name
+func (c *Client) teamMembers(org, team string) (sets.String, error) {
+	members := sets.NewString()
+	teams, err := c.spc.ListTeams(org)
+	if err != nil {
+		return members, err
+	}
+	for _, t := range teams {
+		if !strings.EqualFold(t.Slug, team) && !strings.EqualFold(t.Name, team) {
+			continue
+		}
+		teamMembers, err := c.spc.ListTeamMembers(t.ID, scmprovider.RoleAll)
+		if err != nil {
+			return members, err
+		}
+		for _, m := range teamMembers {
+			members.Insert(m.Login)
+		}
+		return members, nil
+	}
+	return members, fmt.Errorf("team %s not found in %s", team, org)
+}
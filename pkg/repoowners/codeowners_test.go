@@ -0,0 +1,168 @@
+package repoowners
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/lighthouse/pkg/gittest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+func TestParseCodeOwners(t *testing.T) {
+	rules, err := ParseCodeOwners([]byte(`# default owners
+*       @global-owner
+
+/docs/  @org/Leads doc@example.com # docs team
+\#notes @alice
+*.js
+`))
+	require.NoError(t, err)
+	assert.Equal(t, []CodeOwnersRule{
+		{Pattern: "*", Owners: []string{"@global-owner"}},
+		{Pattern: "/docs/", Owners: []string{"@org/Leads", "doc@example.com"}},
+		{Pattern: "#notes", Owners: []string{"@alice"}},
+		{Pattern: "*.js", Owners: []string{}},
+	}, rules)
+
+	_, err = ParseCodeOwners([]byte("!vendor/ @alice"))
+	assert.Error(t, err)
+}
+
+func TestCodeOwnersPath(t *testing.T) {
+	testCases := []struct {
+		pattern     string
+		expectedDir string
+		matches     []string
+		nonMatches  []string
+	}{
+		{
+			pattern:     "*",
+			expectedDir: "",
+		},
+		{
+			pattern:     "/build/logs/",
+			expectedDir: "build/logs",
+		},
+		{
+			pattern:     "/build/logs",
+			expectedDir: "build/logs",
+		},
+		{
+			pattern:     "/src/main.go",
+			expectedDir: "src/main.go",
+		},
+		{
+			pattern:     "apps/",
+			expectedDir: "",
+			matches:     []string{"apps/main.go", "src/apps/main.go"},
+			nonMatches:  []string{"apps", "src/apps.go"},
+		},
+		{
+			pattern:     "*.js",
+			expectedDir: "",
+			matches:     []string{"main.js", "src/lib/main.js"},
+			nonMatches:  []string{"main.json", "main.go"},
+		},
+		{
+			pattern:     "/docs/*.md",
+			expectedDir: "docs",
+			matches:     []string{"README.md"},
+			nonMatches:  []string{"api/README.md", "main.go"},
+		},
+		{
+			pattern:     "src/**/test",
+			expectedDir: "src",
+			matches:     []string{"test/main.go", "a/b/test/main.go"},
+			nonMatches:  []string{"a/tests/main.go"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.pattern, func(t *testing.T) {
+			dir, re, err := codeOwnersPath(tc.pattern)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedDir, dir)
+			if len(tc.matches) == 0 && len(tc.nonMatches) == 0 {
+				assert.Nil(t, re)
+				return
+			}
+			require.NotNil(t, re)
+			for _, path := range tc.matches {
+				assert.True(t, re.MatchString(path), "%s should match %s", re, path)
+			}
+			for _, path := range tc.nonMatches {
+				assert.False(t, re.MatchString(path), "%s should not match %s", re, path)
+			}
+		})
+	}
+}
+
+func TestLoadRepoOwnersCodeOwners(t *testing.T) {
+	defaultBranch := gittest.GetDefaultBranch(t)
+	files := map[string][]byte{
+		"src/OWNERS": []byte(`approvers:
+- carl`),
+		"Makefile": []byte("all:\n"),
+		".github/CODEOWNERS": []byte(`* @cjwagner
+/src/dir/ @org/leads @Alice
+Makefile @bob
+*.go @mml
+/src/util.go @bob
+`),
+	}
+
+	client, cleanup, err := getTestClient(defaultBranch, files, false, true, false, nil, nil, nil)
+	require.NoError(t, err)
+	defer cleanup()
+	client.codeOwnersEnabled = func(org, repo string) bool {
+		return true
+	}
+
+	r, err := client.LoadRepoOwners("org", "repo", defaultBranch)
+	require.NoError(t, err)
+	ro := r.(*RepoOwners)
+
+	assert.Equal(t, sets.NewString("mml"), ro.Approvers("main.go"))
+	assert.Equal(t, sets.NewString("cjwagner"), ro.Approvers("README.md"))
+	assert.Equal(t, sets.NewString("carl", "mml"), ro.Approvers("src/main.go"))
+	assert.Equal(t, sets.NewString("carl", "sig-lead", "alice"), ro.Approvers("src/dir/README.md"))
+	assert.Equal(t, sets.NewString("sig-lead", "alice"), ro.Reviewers("src/dir/README.md"))
+	assert.Equal(t, sets.NewString("carl", "mml"), ro.Approvers("src/dir/main.go"))
+	assert.Equal(t, sets.NewString("bob"), ro.Approvers("Makefile"))
+	assert.Equal(t, "src/dir", ro.FindApproverOwnersForFile("src/dir/README.md"))
+	assert.Equal(t, "src", ro.FindApproverOwnersForFile("src/dir/main.go"))
+	assert.Equal(t, sets.NewString("carl", "bob"), ro.Approvers("src/util.go"))
+	assert.Equal(t, "src/util.go", ro.FindApproverOwnersForFile("src/util.go"))
+
+	client.codeOwnersEnabled = func(org, repo string) bool {
+		return false
+	}
+	r, err = client.LoadRepoOwners("org", "repo", defaultBranch)
+	require.NoError(t, err)
+	assert.Equal(t, sets.NewString(), r.Approvers("main.go"))
+}
+
+func TestLoadRepoOwnersCodeOwnersLastMatchWins(t *testing.T) {
+	defaultBranch := gittest.GetDefaultBranch(t)
+	files := map[string][]byte{
+		"CODEOWNERS": []byte(`* @a
+*.js @b
+/vendor/
+`),
+	}
+
+	client, cleanup, err := getTestClient(defaultBranch, files, false, true, false, nil, nil, nil)
+	require.NoError(t, err)
+	defer cleanup()
+	client.codeOwnersEnabled = func(org, repo string) bool {
+		return true
+	}
+
+	r, err := client.LoadRepoOwners("org", "repo", defaultBranch)
+	require.NoError(t, err)
+
+	assert.Equal(t, sets.NewString("a"), r.Approvers("main.go"))
+	assert.Equal(t, sets.NewString("b"), r.Approvers("web/app.js"), "a later pattern should override the earlier ones")
+	assert.Equal(t, sets.NewString("b"), r.LeafApprovers("web/app.js"))
+	assert.Equal(t, sets.NewString(), r.Approvers("vendor/lib.go"), "a later rule without owners should leave the path unowned")
+}
@@ -56,6 +56,8 @@ type Config struct {
 	RequiredReviewers []string `json:"required_reviewers,omitempty"`
 	Labels            []string `json:"labels,omitempty"`
 	MinimumReviewers  *int     `json:"minimum_reviewers,omitempty"`
+	// MinApprovals is an alias of MinimumReviewers, the highest of the two is used when both are set
+	MinApprovals *int `json:"min_approvals,omitempty"`
 }
 
 // SimpleConfig holds options and Config applied to everything under the containing directory
@@ -66,7 +68,7 @@ type SimpleConfig struct {
 
 // Empty checks if a SimpleConfig could be considered empty
 func (s *SimpleConfig) Empty() bool {
-	return len(s.Approvers) == 0 && len(s.Reviewers) == 0 && len(s.RequiredReviewers) == 0 && len(s.Labels) == 0 && s.MinimumReviewers == nil
+	return len(s.Approvers) == 0 && len(s.Reviewers) == 0 && len(s.RequiredReviewers) == 0 && len(s.Labels) == 0 && s.MinimumReviewers == nil && s.MinApprovals == nil
 }
 
 // FullConfig contains Filters which apply specific Config to files matching its regexp
@@ -79,6 +81,8 @@ type scmProviderClient interface {
 	ListCollaborators(org, repo string) ([]scm.User, error)
 	GetRef(org, repo, ref string) (string, error)
 	GetFile(org, repo, filepath, ref string) ([]byte, error)
+	ListTeams(org string) ([]*scm.Team, error)
+	ListTeamMembers(id int, role string) ([]*scm.TeamMember, error)
 }
 
 type cacheEntry struct {
@@ -106,6 +110,7 @@ type Client struct {
 
 	mdYAMLEnabled     func(org, repo string) bool
 	skipCollaborators func(org, repo string) bool
+	codeOwnersEnabled func(org, repo string) bool
 
 	lock  sync.Mutex
 	cache map[string]cacheEntry
@@ -118,6 +123,7 @@ func NewClient(
 	config *prowConf.Config,
 	mdYAMLEnabled func(org, repo string) bool,
 	skipCollaborators func(org, repo string) bool,
+	codeOwnersEnabled func(org, repo string) bool,
 ) *Client {
 	return &Client{
 		git:    gc,
@@ -127,6 +133,7 @@ func NewClient(
 
 		mdYAMLEnabled:     mdYAMLEnabled,
 		skipCollaborators: skipCollaborators,
+		codeOwnersEnabled: codeOwnersEnabled,
 
 		config: config,
 	}
@@ -174,10 +181,12 @@ type RepoOwners struct {
 	labels            map[string]map[*regexp.Regexp]sets.String
 	minimumReviewers  map[string]map[*regexp.Regexp]int
 	options           map[string]dirOptions
+	codeOwners        []codeOwnersEntry
 
-	baseDir      string
-	enableMDYAML bool
-	dirExcludes  sets.String
+	baseDir          string
+	enableMDYAML     bool
+	enableCodeOwners bool
+	dirExcludes      sets.String
 
 	log *logrus.Entry
 }
@@ -225,6 +234,7 @@ func (c *Client) LoadRepoOwners(org, repo, base string) (RepoOwner, error) {
 	cloneRef := fmt.Sprintf("%s/%s", org, repo)
 	fullName := fmt.Sprintf("%s:%s", cloneRef, base)
 	mdYaml := c.mdYAMLEnabled(org, repo)
+	codeOwners := c.codeOwnersEnabled != nil && c.codeOwnersEnabled(org, repo)
 	sparseCheckout, _ := strconv.ParseBool(os.Getenv("SPARSE_CHECKOUT"))
 
 	sha, err := c.spc.GetRef(org, repo, fmt.Sprintf("heads/%s", base))
@@ -235,13 +245,16 @@ func (c *Client) LoadRepoOwners(org, repo, base string) (RepoOwner, error) {
 	c.lock.Lock()
 	defer c.lock.Unlock()
 	entry, ok := c.cache[fullName]
-	if !ok || entry.sha != sha || entry.owners == nil || entry.owners.enableMDYAML != mdYaml {
+	if !ok || entry.sha != sha || entry.owners == nil || entry.owners.enableMDYAML != mdYaml || entry.owners.enableCodeOwners != codeOwners {
 		var gitRepo *git2.Repo
 		if sparseCheckout {
 			sparseCheckoutPatterns := []string{"/OWNERS_ALIASES", "OWNERS"}
 			if mdYaml {
 				sparseCheckoutPatterns = append(sparseCheckoutPatterns, "*.md")
 			}
+			if codeOwners {
+				sparseCheckoutPatterns = append(sparseCheckoutPatterns, codeOwnersFileName)
+			}
 			gitRepo, err = c.git.SparseClone(cloneRef, sparseCheckoutPatterns)
 		} else {
 			gitRepo, err = c.git.Clone(cloneRef)
@@ -278,6 +291,10 @@ func (c *Client) LoadRepoOwners(org, repo, base string) (RepoOwner, error) {
 		if err != nil {
 			return nil, fmt.Errorf("failed to load RepoOwners for %s: %v", fullName, err)
 		}
+		if codeOwners {
+			entry.owners.enableCodeOwners = true
+			c.loadCodeOwnersFrom(entry.owners, log)
+		}
 		entry.sha = sha
 		c.cache[fullName] = entry
 	}
@@ -559,8 +576,8 @@ func (o *RepoOwners) applyConfigToPath(path string, re *regexp.Regexp, config *C
 		o.labels[path][re] = sets.NewString(config.Labels...)
 	}
 
-	if config.MinimumReviewers != nil {
-		minReviewers := *config.MinimumReviewers
+	if minReviewersPtr := config.minimumApprovals(); minReviewersPtr != nil {
+		minReviewers := *minReviewersPtr
 		if minReviewers < 1 {
 			o.log.WithField("path", path).Warnf("minimum_reviewers value %d is invalid, must be >= 1; defaulting to 1", minReviewers)
 			minReviewers = 1
@@ -572,6 +589,17 @@ func (o *RepoOwners) applyConfigToPath(path string, re *regexp.Regexp, config *C
 	}
 }
 
+// minimumApprovals returns the highest of minimum_reviewers and min_approvals or nil if none is set
+func (c *Config) minimumApprovals() *int {
+	if c.MinApprovals == nil {
+		return c.MinimumReviewers
+	}
+	if c.MinimumReviewers == nil || *c.MinApprovals > *c.MinimumReviewers {
+		return c.MinApprovals
+	}
+	return c.MinimumReviewers
+}
+
 func (o *RepoOwners) applyOptionsToPath(path string, opts dirOptions) {
 	if opts != defaultDirOptions {
 		o.options[path] = opts
@@ -598,15 +626,23 @@ func (o *RepoOwners) filterCollaborators(toKeep []scm.User) *RepoOwners {
 	result := *o
 	result.approvers = filter(o.approvers)
 	result.reviewers = filter(o.reviewers)
+	result.codeOwners = make([]codeOwnersEntry, 0, len(o.codeOwners))
+	for _, entry := range o.codeOwners {
+		entry.owners = entry.owners.Intersection(collabs)
+		result.codeOwners = append(result.codeOwners, entry)
+	}
 	return &result
 }
 
 // findOwnersForFile returns the OWNERS file path furthest down the tree for a specified file
-// using ownerMap to check for entries
-func findOwnersForFile(log *logrus.Entry, path string, ownerMap map[string]map[*regexp.Regexp]sets.String) string {
+// using ownerMap and the CODEOWNERS rule owning the file, if any, to check for entries
+func findOwnersForFile(log *logrus.Entry, path string, ownerMap map[string]map[*regexp.Regexp]sets.String, codeOwners *codeOwnersEntry) string {
 	d := path
 
 	for ; d != baseDirConvention; d = canonicalize(filepath.Dir(d)) {
+		if codeOwners != nil && codeOwners.dir == d && len(codeOwners.owners) != 0 {
+			return d
+		}
 		relative, err := filepath.Rel(d, path)
 		if err != nil {
 			log.WithError(err).WithField("path", path).Errorf("Unable to find relative path between %q and path.", d)
@@ -627,19 +663,19 @@ func findOwnersForFile(log *logrus.Entry, path string, ownerMap map[string]map[*
 // FindApproverOwnersForFile returns the OWNERS file path furthest down the tree for a specified file
 // that contains an approvers section
 func (o *RepoOwners) FindApproverOwnersForFile(path string) string {
-	return findOwnersForFile(o.log, path, o.approvers)
+	return findOwnersForFile(o.log, path, o.approvers, o.codeOwnersForFile(path))
 }
 
 // FindReviewersOwnersForFile returns the OWNERS file path furthest down the tree for a specified file
 // that contains a reviewers section
 func (o *RepoOwners) FindReviewersOwnersForFile(path string) string {
-	return findOwnersForFile(o.log, path, o.reviewers)
+	return findOwnersForFile(o.log, path, o.reviewers, o.codeOwnersForFile(path))
 }
 
 // FindLabelsForFile returns a set of labels which should be applied to PRs
 // modifying files under the given path.
 func (o *RepoOwners) FindLabelsForFile(path string) sets.String {
-	return o.entriesForFile(path, o.labels, false)
+	return o.entriesForFile(path, o.labels, nil, false)
 }
 
 // IsNoParentOwners checks if an OWNERS file path refers to an OWNERS file with NoParentOwners enabled.
@@ -653,8 +689,10 @@ func (o *RepoOwners) IsNoParentOwners(path string) bool {
 // The walk starts at path itself so that .md files with a YAML header
 // (keyed by their exact file path) contribute as the leaf entry, and so
 // that dir inputs return entries at that directory rather than at its
-// parent. leafOnly returns as soon as any level of the walk contributes.
-func (o *RepoOwners) entriesForFile(path string, people map[string]map[*regexp.Regexp]sets.String, leafOnly bool) sets.String {
+// parent. The owners of the codeOwners rule, if any, contribute at the
+// directory of the rule. leafOnly returns as soon as any level of the walk
+// contributes.
+func (o *RepoOwners) entriesForFile(path string, people map[string]map[*regexp.Regexp]sets.String, codeOwners *codeOwnersEntry, leafOnly bool) sets.String {
 	d := path
 	out := sets.NewString()
 	for {
@@ -668,6 +706,9 @@ func (o *RepoOwners) entriesForFile(path string, people map[string]map[*regexp.R
 				out.Insert(s.List()...)
 			}
 		}
+		if codeOwners != nil && codeOwners.dir == d {
+			out.Insert(codeOwners.owners.List()...)
+		}
 		if leafOnly && out.Len() > 0 {
 			break
 		}
@@ -734,7 +775,7 @@ func (o *RepoOwners) MinimumReviewersForFile(path string) int {
 // requested file. If pkg/OWNERS has user1 and pkg/util/OWNERS has user2 this
 // will only return user2 for the path pkg/util/sets/file.go
 func (o *RepoOwners) LeafApprovers(path string) sets.String {
-	return o.entriesForFile(path, o.approvers, true)
+	return o.entriesForFile(path, o.approvers, o.codeOwnersForFile(path), true)
 }
 
 // Approvers returns ALL of the users who are approvers for the
@@ -742,14 +783,14 @@ func (o *RepoOwners) LeafApprovers(path string) sets.String {
 // If pkg/OWNERS has user1 and pkg/util/OWNERS has user2 this
 // will return both user1 and user2 for the path pkg/util/sets/file.go
 func (o *RepoOwners) Approvers(path string) sets.String {
-	return o.entriesForFile(path, o.approvers, false)
+	return o.entriesForFile(path, o.approvers, o.codeOwnersForFile(path), false)
 }
 
 // LeafReviewers returns a set of users who are the closest reviewers to the
 // requested file. If pkg/OWNERS has user1 and pkg/util/OWNERS has user2 this
 // will only return user2 for the path pkg/util/sets/file.go
 func (o *RepoOwners) LeafReviewers(path string) sets.String {
-	return o.entriesForFile(path, o.reviewers, true)
+	return o.entriesForFile(path, o.reviewers, o.codeOwnersForFile(path), true)
 }
 
 // Reviewers returns ALL of the users who are reviewers for the
@@ -757,7 +798,7 @@ func (o *RepoOwners) LeafReviewers(path string) sets.String {
 // If pkg/OWNERS has user1 and pkg/util/OWNERS has user2 this
 // will return both user1 and user2 for the path pkg/util/sets/file.go
 func (o *RepoOwners) Reviewers(path string) sets.String {
-	return o.entriesForFile(path, o.reviewers, false)
+	return o.entriesForFile(path, o.reviewers, o.codeOwnersForFile(path), false)
 }
 
 // RequiredReviewers returns ALL of the users who are required_reviewers for the
@@ -765,5 +806,5 @@ func (o *RepoOwners) Reviewers(path string) sets.String {
 // If pkg/OWNERS has user1 and pkg/util/OWNERS has user2 this
 // will return both user1 and user2 for the path pkg/util/sets/file.go
 func (o *RepoOwners) RequiredReviewers(path string) sets.String {
-	return o.entriesForFile(path, o.requiredReviewers, false)
+	return o.entriesForFile(path, o.requiredReviewers, nil, false)
 }
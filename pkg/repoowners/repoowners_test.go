@@ -1013,3 +1013,42 @@ func TestMinimumReviewersForFile(t *testing.T) {
 		})
 	}
 }
+
+func TestMinApprovals(t *testing.T) {
+	testCases := []struct {
+		name     string
+		owners   string
+		expected int
+	}{
+		{
+			name:     "min_approvals",
+			owners:   "approvers:\n- bob\nmin_approvals: 2",
+			expected: 2,
+		},
+		{
+			name:     "highest of min_approvals and minimum_reviewers",
+			owners:   "approvers:\n- bob\nminimum_reviewers: 3\nmin_approvals: 2",
+			expected: 3,
+		},
+		{
+			name:     "invalid min_approvals defaults to 1",
+			owners:   "min_approvals: 0",
+			expected: 1,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			simple, err := ParseSimpleConfig([]byte(tc.owners))
+			assert.NoError(t, err)
+			assert.False(t, simple.Empty())
+
+			ro := &RepoOwners{
+				approvers:        map[string]map[*regexp.Regexp]sets.String{},
+				minimumReviewers: map[string]map[*regexp.Regexp]int{},
+				log:              logrus.WithField("plugin", "repoowners"),
+			}
+			ro.applyConfigToPath("a", nil, &simple.Config)
+			assert.Equal(t, tc.expected, ro.MinimumReviewersForFile("a/main.go"))
+		})
+	}
+}
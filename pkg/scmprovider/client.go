@@ -69,9 +69,12 @@ type SCMClient interface {
 	ClosePR(string, string, int) error
 	ListAllPullRequestsForFullNameRepo(string, scm.PullRequestListOptions) ([]*scm.PullRequest, error)
 	FindPullRequestsByAuthor(string, string, string) ([]*scm.PullRequest, error)
+	CreatePullRequest(string, string, *scm.PullRequestInput) (*scm.PullRequest, error)
+	GetPullRequestPatch(string, string, int) ([]byte, error)
 
 	// Functions implemented in repositories.go
 	GetRepoLabels(string, string) ([]*scm.Label, error)
+	EnsureFork(string, string) (string, error)
 	IsCollaborator(string, string, string) (bool, error)
 	ListCollaborators(string, string) ([]scm.User, error)
 	CreateStatus(string, string, string, *scm.StatusInput) (*scm.Status, error)
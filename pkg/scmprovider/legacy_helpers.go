@@ -152,6 +152,14 @@ type GenericCommentEvent struct {
 	HeadSha     string
 }
 
+// ReleaseEvent is a release hook together with the action of the webhook payload.
+// go-scm maps the published, released, prereleased and unpublished actions to the same unknown
+// scm.Action so RawAction is needed to tell them apart.
+type ReleaseEvent struct {
+	scm.ReleaseHook
+	RawAction string
+}
+
 // ReviewAction is the action that a review can be made with.
 type ReviewAction string
 
@@ -2,6 +2,9 @@ package scmprovider
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"net/http"
 	"strings"
 
 	"github.com/jenkins-x/go-scm/scm"
@@ -218,6 +221,39 @@ func (c *Client) FindPullRequestsByAuthor(owner, repo string, author string) ([]
 	return allPullRequests, err
 }
 
+// CreatePullRequest creates a new pull request
+func (c *Client) CreatePullRequest(owner, repo string, input *scm.PullRequestInput) (*scm.PullRequest, error) {
+	ctx := context.Background()
+	fullName := c.repositoryName(owner, repo)
+	pr, _, err := c.client.PullRequests.Create(ctx, fullName, input)
+	return pr, err
+}
+
+// GetPullRequestPatch returns the changes of a pull request formatted as a patch which can be applied with `git am`
+func (c *Client) GetPullRequestPatch(owner, repo string, number int) ([]byte, error) {
+	if c.client.Driver != scm.DriverGithub {
+		return nil, errors.Errorf("getting the patch of a pull request is not supported for provider %s", c.ProviderType())
+	}
+	req := &scm.Request{
+		Method: http.MethodGet,
+		Path:   fmt.Sprintf("repos/%s/pulls/%d", c.repositoryName(owner, repo), number),
+		Header: http.Header{"Accept": []string{"application/vnd.github.v3.patch"}},
+	}
+	res, err := c.client.Do(context.Background(), req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading the patch of %s/%s#%d", owner, repo, number)
+	}
+	if res.Status >= http.StatusMultipleChoices {
+		return nil, errors.Errorf("getting the patch of %s/%s#%d failed with status %d: %s", owner, repo, number, res.Status, string(body))
+	}
+	return body, nil
+}
+
 // AssignPR assigns pr
 func (c *Client) AssignPR(owner, repo string, number int, logins []string) error {
 	ctx := context.Background()
@@ -163,3 +163,30 @@ func (c *Client) IsMember(org, user string) (bool, error) {
 	member, _, err := c.client.Organizations.IsMember(ctx, org, user)
 	return member, err
 }
+
+// EnsureFork makes sure the bot user has a fork of the repository, creating it if required,
+// and returns the full name of the fork
+func (c *Client) EnsureFork(owner, repo string) (string, error) {
+	botName, err := c.BotName()
+	if err != nil {
+		return "", err
+	}
+	forkName := c.repositoryName(botName, repo)
+	if botName == owner {
+		return forkName, nil
+	}
+	ctx := context.Background()
+	if _, _, err := c.client.Repositories.Find(ctx, forkName); err == nil {
+		return forkName, nil
+	} else if err != scm.ErrNotFound {
+		return "", err
+	}
+	fork, _, err := c.client.Repositories.Fork(ctx, &scm.RepositoryInput{}, c.repositoryName(owner, repo))
+	if err != nil {
+		return "", err
+	}
+	if fork != nil && fork.FullName != "" {
+		forkName = fork.FullName
+	}
+	return forkName, nil
+}
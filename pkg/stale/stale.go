@@ -0,0 +1,272 @@
+// Package stale marks inactive issues and pull requests as stale, then as rotten and eventually
+// closes them, as configured by the `lifecycle` section of the configuration.
+package stale
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/config/lighthouse"
+	"github.com/jenkins-x/lighthouse/pkg/labels"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// Action is the lifecycle transition applied to an issue or pull request
+type Action string
+
+const (
+	// StaleAction marks the issue or pull request as stale
+	StaleAction Action = "stale"
+	// RottenAction marks the stale issue or pull request as rotten
+	RottenAction Action = "rotten"
+	// CloseAction closes the rotten issue or pull request
+	CloseAction Action = "close"
+)
+
+// Change is a lifecycle transition of an issue or pull request
+type Change struct {
+	Org    string `json:"org"`
+	Repo   string `json:"repo"`
+	Number int    `json:"number"`
+	PR     bool   `json:"pr"`
+	Link   string `json:"link,omitempty"`
+	Action Action `json:"action"`
+}
+
+// Client is the subset of the SCM provider client used to manage the lifecycle of issues and pull requests
+type Client interface {
+	Search(opts scm.SearchOptions) ([]*scm.SearchIssue, *scmprovider.RateLimits, error)
+	AddLabel(owner, repo string, number int, label string, pr bool) error
+	RemoveLabel(owner, repo string, number int, label string, pr bool) error
+	CreateComment(owner, repo string, number int, pr bool, comment string) error
+	CloseIssue(owner, repo string, number int) error
+	ClosePR(owner, repo string, number int) error
+}
+
+// Manager applies the lifecycle transitions to the inactive issues and pull requests
+type Manager struct {
+	Client Client
+	Config *lighthouse.Lifecycle
+	// DryRun only reports the changes without applying them
+	DryRun bool
+	Logger *logrus.Entry
+
+	now func() time.Time
+}
+
+// NewManager creates a new lifecycle manager
+func NewManager(client Client, cfg *lighthouse.Lifecycle, dryRun bool) *Manager {
+	return &Manager{
+		Client: client,
+		Config: cfg,
+		DryRun: dryRun,
+		Logger: logrus.WithField("component", "stale"),
+		now:    time.Now,
+	}
+}
+
+// commentData is the data available to the comment templates
+type commentData struct {
+	Org         string
+	Repo        string
+	Number      int
+	Title       string
+	Author      string
+	Kind        string
+	StaleAfter  string
+	RottenAfter string
+	CloseAfter  string
+}
+
+// stage is a lifecycle transition together with the search qualifiers selecting the issues and
+// pull requests it applies to
+type stage struct {
+	action     Action
+	qualifiers string
+	inactive   time.Duration
+}
+
+// Run applies the lifecycle transitions of all the configured queries, returning the changes which were
+// made or, in dry-run mode, would have been made. The later stages are processed first so that an issue
+// or pull request goes through at most one transition per run.
+func (m *Manager) Run() ([]Change, error) {
+	var changes []Change
+	var errs []string
+	for i := range m.Config.Queries {
+		q := &m.Config.Queries[i]
+		stages := []stage{
+			{
+				action:     CloseAction,
+				qualifiers: fmt.Sprintf("label:\"%s\"", labels.LifecycleRotten),
+				inactive:   q.CloseAfter,
+			},
+			{
+				action:     RottenAction,
+				qualifiers: fmt.Sprintf("label:\"%s\" -label:\"%s\"", labels.LifecycleStale, labels.LifecycleRotten),
+				inactive:   q.RottenAfter,
+			},
+			{
+				action:     StaleAction,
+				qualifiers: fmt.Sprintf("-label:\"%s\" -label:\"%s\"", labels.LifecycleStale, labels.LifecycleRotten),
+				inactive:   q.StaleAfter,
+			},
+		}
+		for _, s := range stages {
+			c, err := m.runStage(q, s)
+			changes = append(changes, c...)
+			if err != nil {
+				errs = append(errs, err.Error())
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return changes, errors.Errorf("failed to apply the lifecycle: %s", strings.Join(errs, ", "))
+	}
+	return changes, nil
+}
+
+func (m *Manager) runStage(q *lighthouse.LifecycleQuery, s stage) ([]Change, error) {
+	query := fmt.Sprintf("%s -label:\"%s\" %s", q.SearchQuery(), labels.LifecycleFrozen, s.qualifiers)
+	log := m.Logger.WithFields(logrus.Fields{"action": s.action, "query": query})
+	results, err := m.search(query, m.now().Add(-s.inactive))
+	if err != nil {
+		return nil, err
+	}
+	log.Debugf("Found %d issues and pull requests.", len(results))
+
+	var changes []Change
+	var errs []string
+	for _, result := range results {
+		// the search qualifiers are not supported by every provider so check the labels again
+		if result.Closed || hasLabel(result.Labels, labels.LifecycleFrozen) {
+			continue
+		}
+		change := Change{
+			Org:    result.Repository.Namespace,
+			Repo:   result.Repository.Name,
+			Number: result.Number,
+			PR:     result.PullRequest != nil,
+			Link:   result.Link,
+			Action: s.action,
+		}
+		changes = append(changes, change)
+		if m.DryRun {
+			log.Infof("Would mark %s/%s#%d as %s.", change.Org, change.Repo, change.Number, s.action)
+			continue
+		}
+		if err := m.apply(q, &result.Issue, change); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return changes, errors.New(strings.Join(errs, ", "))
+	}
+	return changes, nil
+}
+
+// search returns all the issues and pull requests matching the query which were last updated before the
+// given time. The providers only return the first page of the results so the search is repeated from the
+// last update time of the previous results, which are sorted by update time, until nothing new is found.
+func (m *Manager) search(query string, until time.Time) ([]*scm.SearchIssue, error) {
+	from := "*"
+	seen := map[string]bool{}
+	var all []*scm.SearchIssue
+	for {
+		q := fmt.Sprintf("%s updated:%s..%s", query, from, until.UTC().Format(scmprovider.SearchTimeFormat))
+		results, _, err := m.Client.Search(scm.SearchOptions{Query: q, Sort: "updated", Ascending: true})
+		if err != nil {
+			return all, errors.Wrapf(err, "searching %q", q)
+		}
+		var last time.Time
+		found := false
+		for _, result := range results {
+			key := fmt.Sprintf("%s/%s#%d", result.Repository.Namespace, result.Repository.Name, result.Number)
+			if result.Updated.After(last) {
+				last = result.Updated
+			}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			found = true
+			all = append(all, result)
+		}
+		if !found || last.IsZero() {
+			return all, nil
+		}
+		from = last.UTC().Format(scmprovider.SearchTimeFormat)
+	}
+}
+
+// apply comments on the issue or pull request and updates its lifecycle label, or closes it
+func (m *Manager) apply(q *lighthouse.LifecycleQuery, issue *scm.Issue, change Change) error {
+	kind := "issue"
+	if change.PR {
+		kind = "pull request"
+	}
+	data := commentData{
+		Org:         change.Org,
+		Repo:        change.Repo,
+		Number:      change.Number,
+		Title:       issue.Title,
+		Author:      issue.Author.Login,
+		Kind:        kind,
+		StaleAfter:  formatDuration(q.StaleAfter),
+		RottenAfter: formatDuration(q.RottenAfter),
+		CloseAfter:  formatDuration(q.CloseAfter),
+	}
+	tmpl := q.StaleComment
+	switch change.Action {
+	case RottenAction:
+		tmpl = q.RottenComment
+	case CloseAction:
+		tmpl = q.CloseComment
+	}
+	var comment bytes.Buffer
+	if err := tmpl.Execute(&comment, data); err != nil {
+		return errors.Wrapf(err, "executing the %s comment template for %s/%s#%d", change.Action, change.Org, change.Repo, change.Number)
+	}
+
+	m.Logger.Infof("Marking %s/%s#%d as %s.", change.Org, change.Repo, change.Number, change.Action)
+	if err := m.Client.CreateComment(change.Org, change.Repo, change.Number, change.PR, comment.String()); err != nil {
+		return errors.Wrapf(err, "commenting on %s/%s#%d", change.Org, change.Repo, change.Number)
+	}
+	switch change.Action {
+	case StaleAction:
+		return m.Client.AddLabel(change.Org, change.Repo, change.Number, labels.LifecycleStale, change.PR)
+	case RottenAction:
+		if err := m.Client.AddLabel(change.Org, change.Repo, change.Number, labels.LifecycleRotten, change.PR); err != nil {
+			return err
+		}
+		return m.Client.RemoveLabel(change.Org, change.Repo, change.Number, labels.LifecycleStale, change.PR)
+	case CloseAction:
+		if change.PR {
+			return m.Client.ClosePR(change.Org, change.Repo, change.Number)
+		}
+		return m.Client.CloseIssue(change.Org, change.Repo, change.Number)
+	}
+	return nil
+}
+
+func hasLabel(issueLabels []string, label string) bool {
+	for _, l := range issueLabels {
+		if strings.EqualFold(l, label) {
+			return true
+		}
+	}
+	return false
+}
+
+// formatDuration formats whole days as "90d" rather than "2160h0m0s"
+func formatDuration(d time.Duration) string {
+	day := 24 * time.Hour
+	if d%day == 0 {
+		return fmt.Sprintf("%dd", d/day)
+	}
+	return d.String()
+}
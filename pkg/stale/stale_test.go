@@ -0,0 +1,209 @@
+package stale
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/config/lighthouse"
+	"github.com/jenkins-x/lighthouse/pkg/labels"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClient struct {
+	// results are the search results keyed by the action whose qualifiers the query contains
+	results map[Action][]*scm.SearchIssue
+	// pageSize limits the number of results returned by a search, like the providers only return the first page
+	pageSize int
+	queries  []string
+	comments map[int]string
+	added    []string
+	removed  []string
+	closed   []string
+}
+
+func (f *fakeClient) Search(opts scm.SearchOptions) ([]*scm.SearchIssue, *scmprovider.RateLimits, error) {
+	f.queries = append(f.queries, opts.Query)
+	results := f.results[StaleAction]
+	switch {
+	case strings.Contains(opts.Query, fmt.Sprintf(" label:\"%s\" -label:\"%s\"", labels.LifecycleStale, labels.LifecycleRotten)):
+		results = f.results[RottenAction]
+	case strings.Contains(opts.Query, fmt.Sprintf(" label:\"%s\"", labels.LifecycleRotten)):
+		results = f.results[CloseAction]
+	}
+	if f.pageSize == 0 {
+		return results, nil, nil
+	}
+	from := strings.TrimPrefix(strings.Split(opts.Query[strings.LastIndex(opts.Query, "updated:"):], "..")[0], "updated:")
+	var page []*scm.SearchIssue
+	for _, r := range results {
+		if from != "*" && r.Updated.Format(scmprovider.SearchTimeFormat) < from {
+			continue
+		}
+		if len(page) < f.pageSize {
+			page = append(page, r)
+		}
+	}
+	return page, nil, nil
+}
+
+func (f *fakeClient) AddLabel(owner, repo string, number int, label string, pr bool) error {
+	f.added = append(f.added, fmt.Sprintf("#%d:%s", number, label))
+	return nil
+}
+
+func (f *fakeClient) RemoveLabel(owner, repo string, number int, label string, pr bool) error {
+	f.removed = append(f.removed, fmt.Sprintf("#%d:%s", number, label))
+	return nil
+}
+
+func (f *fakeClient) CreateComment(owner, repo string, number int, pr bool, comment string) error {
+	f.comments[number] = comment
+	return nil
+}
+
+func (f *fakeClient) CloseIssue(owner, repo string, number int) error {
+	f.closed = append(f.closed, fmt.Sprintf("issue #%d", number))
+	return nil
+}
+
+func (f *fakeClient) ClosePR(owner, repo string, number int) error {
+	f.closed = append(f.closed, fmt.Sprintf("pr #%d", number))
+	return nil
+}
+
+func searchIssue(number int, pr bool, issueLabels ...string) *scm.SearchIssue {
+	issue := &scm.SearchIssue{
+		Issue: scm.Issue{
+			Number: number,
+			Title:  fmt.Sprintf("issue %d", number),
+			Author: scm.User{Login: "author"},
+			Labels: issueLabels,
+		},
+		Repository: scm.Repository{Namespace: "org", Name: "repo"},
+	}
+	if pr {
+		issue.PullRequest = &scm.PullRequest{}
+	}
+	return issue
+}
+
+func newTestManager(t *testing.T, client *fakeClient, q lighthouse.LifecycleQuery, dryRun bool) *Manager {
+	cfg := &lighthouse.Lifecycle{Queries: []lighthouse.LifecycleQuery{q}}
+	require.NoError(t, cfg.Parse())
+	m := NewManager(client, cfg, dryRun)
+	m.Logger = logrus.WithField("test", t.Name())
+	m.now = func() time.Time {
+		return time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	}
+	return m
+}
+
+func TestRun(t *testing.T) {
+	client := &fakeClient{
+		results: map[Action][]*scm.SearchIssue{
+			StaleAction:  {searchIssue(1, false), searchIssue(2, true), searchIssue(3, false, labels.LifecycleFrozen)},
+			RottenAction: {searchIssue(4, true, labels.LifecycleStale)},
+			CloseAction:  {searchIssue(5, false, labels.LifecycleRotten), searchIssue(6, true, labels.LifecycleRotten)},
+		},
+		comments: map[int]string{},
+	}
+	m := newTestManager(t, client, lighthouse.LifecycleQuery{
+		Orgs:          []string{"org"},
+		ExcludedRepos: []string{"org/excluded"},
+		Query:         "is:issue",
+	}, false)
+
+	changes, err := m.Run()
+	require.NoError(t, err)
+
+	require.Len(t, client.queries, 3)
+	assert.Equal(t, `state:open org:"org" -repo:"org/excluded" is:issue -label:"lifecycle/frozen" label:"lifecycle/rotten" updated:*..2020-05-02T00:00:00Z`, client.queries[0])
+	assert.Equal(t, `state:open org:"org" -repo:"org/excluded" is:issue -label:"lifecycle/frozen" label:"lifecycle/stale" -label:"lifecycle/rotten" updated:*..2020-05-02T00:00:00Z`, client.queries[1])
+	assert.Equal(t, `state:open org:"org" -repo:"org/excluded" is:issue -label:"lifecycle/frozen" -label:"lifecycle/stale" -label:"lifecycle/rotten" updated:*..2020-03-03T00:00:00Z`, client.queries[2])
+
+	var actions []string
+	for _, c := range changes {
+		actions = append(actions, fmt.Sprintf("#%d:%s", c.Number, c.Action))
+	}
+	assert.Equal(t, []string{"#5:close", "#6:close", "#4:rotten", "#1:stale", "#2:stale"}, actions)
+	assert.Equal(t, []string{"#4:" + labels.LifecycleRotten, "#1:" + labels.LifecycleStale, "#2:" + labels.LifecycleStale}, client.added)
+	assert.Equal(t, []string{"#4:" + labels.LifecycleStale}, client.removed)
+	assert.Equal(t, []string{"issue #5", "pr #6"}, client.closed)
+
+	assert.Contains(t, client.comments[1], "Inactive issues go stale after 90d of inactivity.")
+	assert.Contains(t, client.comments[2], "Mark this pull request as fresh with `/remove-lifecycle stale`.")
+	assert.Contains(t, client.comments[4], "Rotten pull requests close after an additional 30d of inactivity.")
+	assert.Contains(t, client.comments[5], "Reopen this issue with `/reopen`.")
+	assert.NotContains(t, client.comments, 3)
+}
+
+func TestRunCustomComments(t *testing.T) {
+	client := &fakeClient{
+		results: map[Action][]*scm.SearchIssue{
+			StaleAction: {searchIssue(1, false)},
+		},
+		comments: map[int]string{},
+	}
+	m := newTestManager(t, client, lighthouse.LifecycleQuery{
+		Repos:                []string{"org/repo"},
+		StaleAfterString:     "36h",
+		StaleCommentTemplate: "@{{.Author}} {{.Org}}/{{.Repo}}#{{.Number}} has been inactive for {{.StaleAfter}}",
+	}, false)
+
+	_, err := m.Run()
+	require.NoError(t, err)
+	assert.Equal(t, "@author org/repo#1 has been inactive for 36h0m0s", client.comments[1])
+}
+
+func TestRunDryRun(t *testing.T) {
+	client := &fakeClient{
+		results: map[Action][]*scm.SearchIssue{
+			StaleAction: {searchIssue(1, false)},
+			CloseAction: {searchIssue(2, true, labels.LifecycleRotten)},
+		},
+		comments: map[int]string{},
+	}
+	m := newTestManager(t, client, lighthouse.LifecycleQuery{Repos: []string{"org/repo"}}, true)
+
+	changes, err := m.Run()
+	require.NoError(t, err)
+	assert.Equal(t, []Change{
+		{Org: "org", Repo: "repo", Number: 2, PR: true, Action: CloseAction},
+		{Org: "org", Repo: "repo", Number: 1, Action: StaleAction},
+	}, changes)
+	assert.Empty(t, client.comments)
+	assert.Empty(t, client.added)
+	assert.Empty(t, client.closed)
+}
+
+func TestRunPagesThroughSearchResults(t *testing.T) {
+	var results []*scm.SearchIssue
+	for i := 1; i <= 7; i++ {
+		issue := searchIssue(i, false)
+		// two issues updated at the same time end up on both pages
+		issue.Updated = time.Date(2020, 1, 1+i/2, 0, 0, 0, 0, time.UTC)
+		results = append(results, issue)
+	}
+	client := &fakeClient{
+		results:  map[Action][]*scm.SearchIssue{StaleAction: results},
+		pageSize: 3,
+		comments: map[int]string{},
+	}
+	m := newTestManager(t, client, lighthouse.LifecycleQuery{Repos: []string{"org/repo"}}, true)
+
+	changes, err := m.Run()
+	require.NoError(t, err)
+
+	var numbers []int
+	for _, c := range changes {
+		numbers = append(numbers, c.Number)
+	}
+	assert.Equal(t, []int{1, 2, 3, 4, 5, 6, 7}, numbers)
+	assert.Contains(t, client.queries, `state:open repo:"org/repo" -label:"lifecycle/frozen" -label:"lifecycle/stale" -label:"lifecycle/rotten" updated:2020-01-03T00:00:00Z..2020-03-03T00:00:00Z`)
+}
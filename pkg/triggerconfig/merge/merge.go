@@ -65,7 +65,24 @@ func ConfigMerge(cfg *config.Config, pluginsCfg *plugins.Configuration, repoConf
 		cfg.Postsubmits[repoKey] = ps
 	}
 	if len(repoConfig.Spec.Periodics) > 0 {
-		cfg.Periodics = append(cfg.Periodics, repoConfig.Spec.Periodics...)
+		// lets make a new slice to avoid concurrent modifications
+		ps := append([]job.Periodic{}, cfg.Periodics...)
+		for _, p := range repoConfig.Spec.Periodics {
+			p.SetSourceRepo(repoKey)
+			found := false
+			for i := range ps {
+				pt2 := &ps[i]
+				if pt2.Name == p.Name && pt2.SourceRepo() == repoKey {
+					*pt2 = p
+					found = true
+					break
+				}
+			}
+			if !found {
+				ps = append(ps, p)
+			}
+		}
+		cfg.Periodics = ps
 	}
 	if len(repoConfig.Spec.Deployments) > 0 {
 		// lets make a new map to avoid concurrent modifications
@@ -76,6 +76,29 @@ func TestMergeTriggerConfig(t *testing.T) {
 	}
 }
 
+func TestMergePeriodics(t *testing.T) {
+	periodic := func(name, branch string) job.Periodic {
+		return job.Periodic{Base: job.Base{Name: name}, Cron: "0 4 * * *", Branch: branch}
+	}
+	cfg := &config.Config{}
+	cfg.Periodics = []job.Periodic{periodic("nightly", "global")}
+	mainConfig := &triggerconfig.Config{Spec: triggerconfig.ConfigSpec{Periodics: []job.Periodic{periodic("nightly", "main")}}}
+	branchConfig := &triggerconfig.Config{Spec: triggerconfig.ConfigSpec{Periodics: []job.Periodic{periodic("nightly", "feature")}}}
+
+	require.NoError(t, merge.ConfigMerge(cfg, &plugins.Configuration{}, mainConfig, "myorg", "other"))
+	require.NoError(t, merge.ConfigMerge(cfg, &plugins.Configuration{}, mainConfig, "myorg", "myrepo"))
+	require.NoError(t, merge.ConfigMerge(cfg, &plugins.Configuration{}, branchConfig, "myorg", "myrepo"))
+
+	require.Len(t, cfg.Periodics, 3, "the periodic of the branch should replace the one of the main branch")
+	var sources, branches []string
+	for i := range cfg.Periodics {
+		sources = append(sources, cfg.Periodics[i].SourceRepo())
+		branches = append(branches, cfg.Periodics[i].Branch)
+	}
+	assert.Equal(t, []string{"", "myorg/other", "myorg/myrepo"}, sources)
+	assert.Equal(t, []string{"global", "main", "feature"}, branches)
+}
+
 func TestMergeTriggerConfigFiles(t *testing.T) {
 	sourceData := "test_data"
 	fileNames, err := os.ReadDir(sourceData)
@@ -59,6 +59,9 @@ const (
 	// BaseSHALabel is added in resources created by Lighthouse and contains the base SHA (for PRs) to be merged against..
 	BaseSHALabel = "lighthouse.jenkins-x.io/baseSHA"
 
+	// ReleaseIDLabel is added in resources created by Lighthouse and contains the ID of the release which triggered the job.
+	ReleaseIDLabel = "lighthouse.jenkins-x.io/releaseID"
+
 	// CloneURIAnnotation is added in resources created by Lighthouse and contains the clone URI for the git repo.
 	CloneURIAnnotation = "lighthouse.jenkins-x.io/cloneURI"
 
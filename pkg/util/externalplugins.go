@@ -1,17 +1,12 @@
 package util
 
 import (
-	"bytes"
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"strings"
 	"sync"
-	"time"
 
 	goscmhmac "github.com/jenkins-x/go-scm/pkg/hmac"
 	"github.com/jenkins-x/go-scm/scm"
@@ -158,24 +153,14 @@ func parseWebhook(l *logrus.Entry, req *http.Request, data []byte) (scm.Webhook,
 }
 
 // callExternalPlugins dispatches the provided payload to the external plugins.
-func callExternalPlugins(l *logrus.Entry, externalPlugins []plugins.ExternalPlugin, payload []byte, headers http.Header, hmacToken string, wg *sync.WaitGroup) {
+func (d *ExternalPluginDispatcher) callExternalPlugins(l *logrus.Entry, externalPlugins []plugins.ExternalPlugin, payload []byte, headers http.Header, hmacToken string, wg *sync.WaitGroup) {
 	headers.Set("User-Agent", LighthouseUserAgent)
-	mac := hmac.New(sha256.New, []byte(hmacToken))
-	_, err := mac.Write(payload)
-	if err != nil {
-		l.WithError(err).Error("Unable to generate signature for relayed payload")
-		return
-	}
-	sum := mac.Sum(nil)
-	signature := "sha256=" + hex.EncodeToString(sum)
-	headers.Set(LighthouseSignatureHeader, signature)
+	headers.Set(LighthouseSignatureHeader, CreateHMACHeader(payload, hmacToken))
 	for _, p := range externalPlugins {
 		wg.Add(1)
 		go func(p plugins.ExternalPlugin) {
 			defer wg.Done()
-			if err := dispatch(p.Endpoint, payload, headers); err != nil {
-				l.WithError(err).WithField("external-plugin", p.Name).Warning("Error dispatching event to external plugin.")
-			} else {
+			if err := d.Deliver(l, p, payload, headers); err == nil {
 				l.WithField("external-plugin", p.Name).Info("Dispatched event to external plugin")
 			}
 		}(p)
@@ -184,6 +169,11 @@ func callExternalPlugins(l *logrus.Entry, externalPlugins []plugins.ExternalPlug
 
 // CallExternalPluginsWithActivityRecord dispatches the provided activity record to the external plugins.
 func CallExternalPluginsWithActivityRecord(l *logrus.Entry, externalPlugins []plugins.ExternalPlugin, activity *v1alpha1.ActivityRecord, hmacToken string, wg *sync.WaitGroup) {
+	DefaultExternalPluginDispatcher().CallWithActivityRecord(l, externalPlugins, activity, hmacToken, wg)
+}
+
+// CallWithActivityRecord dispatches the provided activity record to the external plugins.
+func (d *ExternalPluginDispatcher) CallWithActivityRecord(l *logrus.Entry, externalPlugins []plugins.ExternalPlugin, activity *v1alpha1.ActivityRecord, hmacToken string, wg *sync.WaitGroup) {
 	headers := http.Header{}
 	headers.Set(LighthousePayloadTypeHeader, LighthousePayloadTypeActivity)
 	payload, err := json.Marshal(activity)
@@ -191,11 +181,16 @@ func CallExternalPluginsWithActivityRecord(l *logrus.Entry, externalPlugins []pl
 		l.WithError(err).Errorf("Unable to marshal activity for relaying to external plugins. Activity is: %v", activity)
 		return
 	}
-	callExternalPlugins(l, externalPlugins, payload, headers, hmacToken, wg)
+	d.callExternalPlugins(l, externalPlugins, payload, headers, hmacToken, wg)
 }
 
 // CallExternalPluginsWithWebhook dispatches the provided webhook to the external plugins.
 func CallExternalPluginsWithWebhook(l *logrus.Entry, externalPlugins []plugins.ExternalPlugin, webhook scm.Webhook, hmacToken string, wg *sync.WaitGroup) {
+	DefaultExternalPluginDispatcher().CallWithWebhook(l, externalPlugins, webhook, hmacToken, wg)
+}
+
+// CallWithWebhook dispatches the provided webhook to the external plugins.
+func (d *ExternalPluginDispatcher) CallWithWebhook(l *logrus.Entry, externalPlugins []plugins.ExternalPlugin, webhook scm.Webhook, hmacToken string, wg *sync.WaitGroup) {
 	headers := http.Header{}
 	headers.Set(LighthouseWebhookKindHeader, string(webhook.Kind()))
 	headers.Set(LighthousePayloadTypeHeader, LighthousePayloadTypeWebhook)
@@ -204,42 +199,7 @@ func CallExternalPluginsWithWebhook(l *logrus.Entry, externalPlugins []plugins.E
 		l.WithError(err).Errorf("Unable to marshal webhook for relaying to external plugins. Webhook is: %v", webhook)
 		return
 	}
-	callExternalPlugins(l, externalPlugins, payload, headers, hmacToken, wg)
-}
-
-// dispatch creates a new request using the provided payload and headers
-// and dispatches the request to the provided endpoint.
-func dispatch(endpoint string, payload []byte, h http.Header) error {
-	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewBuffer(payload))
-	if err != nil {
-		return err
-	}
-	req.Header = h
-	var resp *http.Response
-	backoff := 100 * time.Millisecond
-	maxRetries := 5
-
-	c := &http.Client{}
-	for retries := 0; retries < maxRetries; retries++ {
-		resp, err = c.Do(req)
-		if err == nil {
-			break
-		}
-		time.Sleep(backoff)
-		backoff *= 2
-	}
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-	rb, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return err
-	}
-	if resp.StatusCode < 200 || resp.StatusCode > 299 {
-		return fmt.Errorf("response has status %q and body %q", resp.Status, string(rb))
-	}
-	return nil
+	d.callExternalPlugins(l, externalPlugins, payload, headers, hmacToken, wg)
 }
 
 // ExternalPluginsForEvent returns whether there are any external plugins that need to
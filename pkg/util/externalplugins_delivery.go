@@ -0,0 +1,399 @@
+package util
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/uuid"
+)
+
+const (
+	// DefaultExternalPluginTimeout the default maximum duration of an attempt to deliver an event to an external plugin
+	DefaultExternalPluginTimeout = 30 * time.Second
+
+	// DefaultExternalPluginConcurrency the default maximum number of events delivered at the same time to the
+	// endpoint of an external plugin
+	DefaultExternalPluginConcurrency = 10
+
+	// DefaultDeadLetterSize the default number of undelivered events kept in the dead-letter store
+	DefaultDeadLetterSize = 100
+
+	// deadLetterSizeEnvVar the environment variable used to change the number of undelivered events kept, 0 disables
+	// the dead-letter store
+	deadLetterSizeEnvVar = "LIGHTHOUSE_DEAD_LETTER_SIZE"
+
+	externalPluginMaxAttempts    = 5
+	externalPluginInitialBackoff = 100 * time.Millisecond
+	externalPluginMaxBackoff     = 10 * time.Second
+
+	// maxExternalPluginResponseSize bounds the part of the response body which is read and reported in errors
+	maxExternalPluginResponseSize = 4096
+)
+
+var (
+	externalPluginDeliveries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "lighthouse_external_plugin_deliveries",
+		Help: "A counter of the events delivered to the external plugins, by result.",
+	}, []string{"plugin", "result"})
+	externalPluginAttempts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "lighthouse_external_plugin_delivery_attempts",
+		Help: "A counter of the attempts to deliver events to the external plugins, by response code.",
+	}, []string{"plugin", "response_code"})
+	externalPluginDeliveryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "lighthouse_external_plugin_delivery_duration_seconds",
+		Help:    "Histogram of the duration of the deliveries to the external plugins, including retries.",
+		Buckets: []float64{0.05, 0.1, 0.5, 1, 5, 10, 30, 60, 120},
+	}, []string{"plugin"})
+	externalPluginDeadLetters = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "lighthouse_external_plugin_dead_letters",
+		Help: "The number of events which could not be delivered to the external plugins and are kept in the dead-letter stores.",
+	})
+)
+
+// deadLetterSensitiveHeaders are not kept in the dead letters, the payload being signed again when it is redelivered
+var deadLetterSensitiveHeaders = []string{LighthouseSignatureHeader, "Authorization"}
+
+// defaultExternalPluginDispatcher delivers the events of the package functions, such as the activity records sent
+// by foghorn. It has no dead-letter store as there is no endpoint serving the dead letters of these processes.
+var defaultExternalPluginDispatcher = NewExternalPluginDispatcher(nil)
+
+// DefaultExternalPluginDispatcher returns the dispatcher used by the package functions
+func DefaultExternalPluginDispatcher() *ExternalPluginDispatcher {
+	return defaultExternalPluginDispatcher
+}
+
+// NewDeadLetterStoreFromEnv creates a dead-letter store sized from the environment, returning nil if it is disabled
+func NewDeadLetterStoreFromEnv() *DeadLetterStore {
+	size := DefaultDeadLetterSize
+	if text := os.Getenv(deadLetterSizeEnvVar); text != "" {
+		value, err := strconv.Atoi(text)
+		if err != nil {
+			logrus.WithError(err).Warnf("invalid $%s %q, using %d", deadLetterSizeEnvVar, text, size)
+		} else {
+			size = value
+		}
+	}
+	if size <= 0 {
+		return nil
+	}
+	return NewDeadLetterStore(size)
+}
+
+// DeadLetter an event which could not be delivered to an external plugin
+type DeadLetter struct {
+	ID       string                 `json:"id"`
+	Plugin   plugins.ExternalPlugin `json:"plugin"`
+	Kind     string                 `json:"kind,omitempty"`
+	FailedAt time.Time              `json:"failedAt"`
+	Attempts int                    `json:"attempts"`
+	Error    string                 `json:"error"`
+	Headers  http.Header            `json:"headers,omitempty"`
+	Payload  string                 `json:"payload,omitempty"`
+}
+
+// summary returns a copy of the dead letter without the raw request
+func (d *DeadLetter) summary() *DeadLetter {
+	answer := *d
+	answer.Headers = nil
+	answer.Payload = ""
+	return &answer
+}
+
+// DeadLetterStore keeps the most recent undelivered events in memory
+type DeadLetterStore struct {
+	lock    sync.RWMutex
+	maxSize int
+	letters []*DeadLetter
+}
+
+// NewDeadLetterStore creates a dead-letter store keeping at most the given number of events
+func NewDeadLetterStore(maxSize int) *DeadLetterStore {
+	return &DeadLetterStore{
+		maxSize: maxSize,
+	}
+}
+
+// Add adds the dead letter, discarding the oldest one when the store is full
+func (s *DeadLetterStore) Add(d *DeadLetter) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.letters = append(s.letters, d)
+	if len(s.letters) > s.maxSize {
+		externalPluginDeadLetters.Sub(float64(len(s.letters) - s.maxSize))
+		s.letters = s.letters[len(s.letters)-s.maxSize:]
+	}
+	externalPluginDeadLetters.Inc()
+}
+
+// List returns a summary of the dead letters, most recent first
+func (s *DeadLetterStore) List() []*DeadLetter {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	answer := make([]*DeadLetter, 0, len(s.letters))
+	for i := len(s.letters) - 1; i >= 0; i-- {
+		answer = append(answer, s.letters[i].summary())
+	}
+	return answer
+}
+
+// Get returns the dead letter with the given ID or nil if it is not found
+func (s *DeadLetterStore) Get(id string) *DeadLetter {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	for _, d := range s.letters {
+		if d.ID == id {
+			answer := *d
+			return &answer
+		}
+	}
+	return nil
+}
+
+// Remove removes the dead letter with the given ID, returning false if it is not found
+func (s *DeadLetterStore) Remove(id string) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for i, d := range s.letters {
+		if d.ID == id {
+			s.letters = append(s.letters[:i], s.letters[i+1:]...)
+			externalPluginDeadLetters.Dec()
+			return true
+		}
+	}
+	return false
+}
+
+// ExternalPluginDispatcher delivers the events to the external plugins, retrying on transport errors, timeouts,
+// 5xx and 429 responses with a jittered exponential backoff. The events which cannot be delivered are kept in the
+// dead-letter store so that they can be inspected and redelivered.
+type ExternalPluginDispatcher struct {
+	// DeadLetters keeps the undelivered events, if not nil
+	DeadLetters *DeadLetterStore
+
+	client         *http.Client
+	maxAttempts    int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+
+	// ctx is cancelled on shutdown to stop the deliveries in flight and their retries
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	lock      sync.Mutex
+	endpoints map[endpointKey]chan struct{}
+}
+
+// endpointKey identifies the semaphore limiting the concurrent deliveries of a plugin to its endpoint
+type endpointKey struct {
+	plugin   string
+	endpoint string
+}
+
+// NewExternalPluginDispatcher creates a dispatcher keeping the undelivered events in the given dead-letter store
+func NewExternalPluginDispatcher(deadLetters *DeadLetterStore) *ExternalPluginDispatcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &ExternalPluginDispatcher{
+		DeadLetters:    deadLetters,
+		client:         &http.Client{},
+		maxAttempts:    externalPluginMaxAttempts,
+		initialBackoff: externalPluginInitialBackoff,
+		maxBackoff:     externalPluginMaxBackoff,
+		ctx:            ctx,
+		cancel:         cancel,
+		endpoints:      map[endpointKey]chan struct{}{},
+	}
+}
+
+// Shutdown cancels the deliveries in flight and their retries so that they do not delay the shutdown of the process.
+// The events which are not delivered yet are added to the dead-letter store.
+func (d *ExternalPluginDispatcher) Shutdown() {
+	d.cancel()
+}
+
+// Deliver delivers the payload to the external plugin, adding it to the dead-letter store if it cannot be delivered
+func (d *ExternalPluginDispatcher) Deliver(l *logrus.Entry, p plugins.ExternalPlugin, payload []byte, headers http.Header) error {
+	l = l.WithField("external-plugin", p.Name)
+	start := time.Now()
+	attempts, err := d.deliver(l, p, payload, headers)
+	externalPluginDeliveryDuration.WithLabelValues(p.Name).Observe(time.Since(start).Seconds())
+	if err == nil {
+		externalPluginDeliveries.WithLabelValues(p.Name, "success").Inc()
+		return nil
+	}
+	externalPluginDeliveries.WithLabelValues(p.Name, "failure").Inc()
+	if d.DeadLetters != nil {
+		letter := &DeadLetter{
+			ID:       string(uuid.NewUUID()),
+			Plugin:   p,
+			Kind:     headers.Get(LighthouseWebhookKindHeader),
+			FailedAt: time.Now(),
+			Attempts: attempts,
+			Error:    err.Error(),
+			Headers:  headers.Clone(),
+			Payload:  string(payload),
+		}
+		for _, h := range deadLetterSensitiveHeaders {
+			letter.Headers.Del(h)
+		}
+		if letter.Kind == "" {
+			letter.Kind = headers.Get(LighthousePayloadTypeHeader)
+		}
+		d.DeadLetters.Add(letter)
+		l = l.WithField("dead-letter", letter.ID)
+	}
+	l.WithError(err).WithField("attempts", attempts).Warning("Error dispatching event to external plugin.")
+	return err
+}
+
+// Redeliver signs the payload of the dead letter with the HMAC token and delivers it again, removing it from the
+// dead-letter store. It is added back as a new dead letter if it still cannot be delivered.
+func (d *ExternalPluginDispatcher) Redeliver(l *logrus.Entry, letter *DeadLetter, hmacToken string) error {
+	payload := []byte(letter.Payload)
+	headers := letter.Headers.Clone()
+	if headers == nil {
+		headers = http.Header{}
+	}
+	headers.Set(LighthouseSignatureHeader, CreateHMACHeader(payload, hmacToken))
+	if d.DeadLetters != nil {
+		d.DeadLetters.Remove(letter.ID)
+	}
+	return d.Deliver(l.WithField("redelivery-of", letter.ID), letter.Plugin, payload, headers)
+}
+
+// deliver makes the delivery attempts, returning the number of attempts
+func (d *ExternalPluginDispatcher) deliver(l *logrus.Entry, p plugins.ExternalPlugin, payload []byte, headers http.Header) (int, error) {
+	backoff := d.initialBackoff
+	var err error
+	for attempt := 1; ; attempt++ {
+		var statusCode int
+		var retryAfter time.Duration
+		statusCode, retryAfter, err = d.attempt(p, payload, headers)
+		externalPluginAttempts.WithLabelValues(p.Name, responseCodeLabel(statusCode)).Inc()
+		if err == nil || !retryable(statusCode) || attempt >= d.maxAttempts {
+			return attempt, err
+		}
+
+		// the jitter spreads the retries of the events sent at the same time
+		sleep := backoff/2 + time.Duration(rand.Int63n(int64(backoff)+1)) // #nosec
+		if retryAfter > sleep {
+			sleep = retryAfter
+		}
+		if sleep > d.maxBackoff {
+			sleep = d.maxBackoff
+		}
+		l.WithError(err).WithField("attempt", attempt).Debugf("retrying the delivery to the external plugin in %s", sleep)
+		timer := time.NewTimer(sleep)
+		select {
+		case <-d.ctx.Done():
+			timer.Stop()
+			return attempt, errors.Wrap(err, "delivery cancelled by the shutdown")
+		case <-timer.C:
+		}
+		backoff *= 2
+		if backoff > d.maxBackoff {
+			backoff = d.maxBackoff
+		}
+	}
+}
+
+// attempt posts the payload to the endpoint of the plugin once, returning the status code of the response or 0
+// if no response was received
+func (d *ExternalPluginDispatcher) attempt(p plugins.ExternalPlugin, payload []byte, headers http.Header) (int, time.Duration, error) {
+	release, err := d.acquire(p)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer release()
+
+	timeout := p.TimeoutDuration
+	if timeout <= 0 {
+		timeout = DefaultExternalPluginTimeout
+	}
+	ctx, cancel := context.WithTimeout(d.ctx, timeout)
+	defer cancel()
+
+	// the request is created for each attempt as its body is consumed when it is sent
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.Endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return 0, 0, err
+	}
+	req.Header = headers.Clone()
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer resp.Body.Close()
+	rb, err := io.ReadAll(io.LimitReader(resp.Body, maxExternalPluginResponseSize))
+	if err != nil {
+		return 0, 0, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, parseRetryAfter(resp.Header.Get("Retry-After")), fmt.Errorf("response has status %q and body %q", resp.Status, string(rb))
+	}
+	return resp.StatusCode, 0, nil
+}
+
+// acquire waits until fewer than the maximum concurrent deliveries of the plugin are in flight to its endpoint,
+// returning the function to call once the delivery is done. Each plugin has its own semaphore which is only
+// replaced when its max_concurrency changes, i.e. when the configuration is reloaded.
+func (d *ExternalPluginDispatcher) acquire(p plugins.ExternalPlugin) (func(), error) {
+	size := p.MaxConcurrency
+	if size <= 0 {
+		size = DefaultExternalPluginConcurrency
+	}
+	key := endpointKey{plugin: p.Name, endpoint: p.Endpoint}
+	d.lock.Lock()
+	sem := d.endpoints[key]
+	if sem == nil || cap(sem) != size {
+		sem = make(chan struct{}, size)
+		d.endpoints[key] = sem
+	}
+	d.lock.Unlock()
+
+	select {
+	case sem <- struct{}{}:
+	case <-d.ctx.Done():
+		return nil, errors.New("delivery cancelled by the shutdown")
+	}
+	return func() {
+		<-sem
+	}, nil
+}
+
+// retryable returns true if a delivery failing with the status code, or 0 without response, should be retried
+func retryable(statusCode int) bool {
+	return statusCode == 0 || statusCode == http.StatusTooManyRequests || statusCode >= 500
+}
+
+func responseCodeLabel(statusCode int) string {
+	if statusCode == 0 {
+		return "error"
+	}
+	return strconv.Itoa(statusCode)
+}
+
+// parseRetryAfter parses the number of seconds of a Retry-After header, returning 0 if it is not set or is a date
+func parseRetryAfter(value string) time.Duration {
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
@@ -0,0 +1,239 @@
+package util
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestDispatcher(deadLetters *DeadLetterStore) *ExternalPluginDispatcher {
+	d := NewExternalPluginDispatcher(deadLetters)
+	d.initialBackoff = time.Millisecond
+	d.maxBackoff = 5 * time.Millisecond
+	return d
+}
+
+func TestDeliverRetries(t *testing.T) {
+	var bodies []string
+	responses := []int{http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusOK}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		assert.Equal(t, "abc", r.Header.Get(LighthouseSignatureHeader))
+		w.WriteHeader(responses[len(bodies)-1])
+	}))
+	defer server.Close()
+
+	deadLetters := NewDeadLetterStore(10)
+	d := newTestDispatcher(deadLetters)
+	headers := http.Header{}
+	headers.Set(LighthouseSignatureHeader, "abc")
+	p := plugins.ExternalPlugin{Name: "plugin", Endpoint: server.URL}
+
+	require.NoError(t, d.Deliver(logrus.WithField("test", t.Name()), p, []byte("payload"), headers))
+	assert.Equal(t, []string{"payload", "payload", "payload"}, bodies, "the whole payload should be sent on each attempt")
+	assert.Empty(t, deadLetters.List())
+}
+
+func TestDeliverDeadLetter(t *testing.T) {
+	var calls int32
+	status := int32(http.StatusBadRequest)
+	var signature atomic.Value
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		signature.Store(r.Header.Get(LighthouseSignatureHeader))
+		w.WriteHeader(int(atomic.LoadInt32(&status)))
+		_, _ = w.Write([]byte("invalid event"))
+	}))
+	defer server.Close()
+
+	deadLetters := NewDeadLetterStore(10)
+	d := newTestDispatcher(deadLetters)
+	l := logrus.WithField("test", t.Name())
+	headers := http.Header{}
+	headers.Set(LighthouseWebhookKindHeader, "push")
+	headers.Set(LighthouseSignatureHeader, CreateHMACHeader([]byte("payload"), "secret"))
+	headers.Set("Authorization", "Bearer token")
+	p := plugins.ExternalPlugin{Name: "plugin", Endpoint: server.URL}
+
+	err := d.Deliver(l, p, []byte("payload"), headers)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid event")
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls), "client errors should not be retried")
+
+	letters := deadLetters.List()
+	require.Len(t, letters, 1)
+	assert.Equal(t, "plugin", letters[0].Plugin.Name)
+	assert.Equal(t, "push", letters[0].Kind)
+	assert.Equal(t, 1, letters[0].Attempts)
+	assert.Empty(t, letters[0].Payload, "listing should not include the raw request")
+	letter := deadLetters.Get(letters[0].ID)
+	require.NotNil(t, letter)
+	assert.Equal(t, "payload", letter.Payload)
+	assert.Equal(t, "push", letter.Headers.Get(LighthouseWebhookKindHeader))
+	assert.Empty(t, letter.Headers.Get(LighthouseSignatureHeader), "the signature should not be kept")
+	assert.Empty(t, letter.Headers.Get("Authorization"), "the credentials should not be kept")
+
+	// a failed redelivery is recorded as a new dead letter
+	require.Error(t, d.Redeliver(l, letter, "secret"))
+	assert.Equal(t, CreateHMACHeader([]byte("payload"), "secret"), signature.Load(), "the redelivered payload should be signed again")
+	letters = deadLetters.List()
+	require.Len(t, letters, 1)
+	assert.NotEqual(t, letter.ID, letters[0].ID)
+
+	atomic.StoreInt32(&status, http.StatusOK)
+	require.NoError(t, d.Redeliver(l, deadLetters.Get(letters[0].ID), "secret"))
+	assert.Empty(t, deadLetters.List())
+}
+
+func TestDeliverTimeout(t *testing.T) {
+	var calls int32
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		select {
+		case <-r.Context().Done():
+		case <-done:
+		}
+	}))
+	defer server.Close()
+	defer close(done)
+
+	deadLetters := NewDeadLetterStore(10)
+	d := newTestDispatcher(deadLetters)
+	d.maxAttempts = 2
+	p := plugins.ExternalPlugin{Name: "plugin", Endpoint: server.URL, TimeoutDuration: 20 * time.Millisecond}
+
+	require.Error(t, d.Deliver(logrus.WithField("test", t.Name()), p, []byte("payload"), http.Header{}))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls), "timeouts should be retried")
+	require.Len(t, deadLetters.List(), 1)
+	assert.Equal(t, 2, deadLetters.List()[0].Attempts)
+}
+
+func TestDeliverMaxConcurrency(t *testing.T) {
+	var inFlight, maxInFlight int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			observed := atomic.LoadInt32(&maxInFlight)
+			if current <= observed || atomic.CompareAndSwapInt32(&maxInFlight, observed, current) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	d := newTestDispatcher(nil)
+	p := plugins.ExternalPlugin{Name: "plugin", Endpoint: server.URL, MaxConcurrency: 2}
+	wg := sync.WaitGroup{}
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, d.Deliver(logrus.WithField("test", t.Name()), p, []byte("payload"), http.Header{}))
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(2), atomic.LoadInt32(&maxInFlight))
+}
+
+func TestDeliverMaxConcurrencyPerPlugin(t *testing.T) {
+	var lock sync.Mutex
+	inFlight := map[string]int{}
+	maxInFlight := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		plugin := r.Header.Get("X-Plugin")
+		lock.Lock()
+		inFlight[plugin]++
+		if inFlight[plugin] > maxInFlight[plugin] {
+			maxInFlight[plugin] = inFlight[plugin]
+		}
+		lock.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		lock.Lock()
+		inFlight[plugin]--
+		lock.Unlock()
+	}))
+	defer server.Close()
+
+	// plugins sharing an endpoint with different limits should not replace each other's semaphore
+	d := newTestDispatcher(nil)
+	wg := sync.WaitGroup{}
+	for i := 0; i < 8; i++ {
+		for name, limit := range map[string]int{"one": 1, "three": 3} {
+			p := plugins.ExternalPlugin{Name: name, Endpoint: server.URL, MaxConcurrency: limit}
+			headers := http.Header{}
+			headers.Set("X-Plugin", name)
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				assert.NoError(t, d.Deliver(logrus.WithField("test", t.Name()), p, []byte("payload"), headers))
+			}()
+		}
+	}
+	wg.Wait()
+	assert.Equal(t, 1, maxInFlight["one"])
+	assert.LessOrEqual(t, maxInFlight["three"], 3)
+}
+
+func TestDeliverStopsRetryingOnShutdown(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	deadLetters := NewDeadLetterStore(10)
+	d := NewExternalPluginDispatcher(deadLetters)
+	d.initialBackoff = time.Hour
+	d.maxBackoff = time.Hour
+	p := plugins.ExternalPlugin{Name: "plugin", Endpoint: server.URL}
+
+	done := make(chan error)
+	go func() {
+		done <- d.Deliver(logrus.WithField("test", t.Name()), p, []byte("payload"), http.Header{})
+	}()
+	time.Sleep(50 * time.Millisecond)
+	d.Shutdown()
+	select {
+	case err := <-done:
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "shutdown")
+	case <-time.After(5 * time.Second):
+		t.Fatal("the retry should be cancelled by the shutdown")
+	}
+	assert.Len(t, deadLetters.List(), 1)
+}
+
+func TestDeadLetterStoreIsBounded(t *testing.T) {
+	store := NewDeadLetterStore(2)
+	for _, id := range []string{"1", "2", "3"} {
+		store.Add(&DeadLetter{ID: id, Payload: "payload"})
+	}
+
+	letters := store.List()
+	require.Len(t, letters, 2)
+	assert.Equal(t, "3", letters[0].ID)
+	assert.Equal(t, "2", letters[1].ID)
+	assert.Nil(t, store.Get("1"))
+
+	assert.True(t, store.Remove("2"))
+	assert.False(t, store.Remove("2"))
+	assert.Len(t, store.List(), 1)
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	assert.Equal(t, 3*time.Second, parseRetryAfter("3"))
+	assert.Equal(t, time.Duration(0), parseRetryAfter(""))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("Wed, 21 Oct 2015 07:28:00 GMT"))
+}
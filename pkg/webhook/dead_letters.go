@@ -0,0 +1,68 @@
+package webhook
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/jenkins-x/lighthouse/pkg/util"
+	"github.com/sirupsen/logrus"
+)
+
+// DeadLettersPath the URL path of the endpoints listing and redelivering the events which could not be
+// delivered to the external plugins
+const DeadLettersPath = "/external-plugins/dead-letters"
+
+// HandleDeadLetters lists the undelivered events on GET <path>, returns a single event including the raw request
+// on GET <path>/<id>, redelivers an event on POST <path>/<id>/redeliver and discards it on DELETE <path>/<id>
+func (o *WebhooksController) HandleDeadLetters(w http.ResponseWriter, r *http.Request) {
+	if o.externalPlugins == nil || o.externalPlugins.DeadLetters == nil {
+		responseHTTPError(w, http.StatusNotFound, "404 Not Found: the dead-letter store is disabled")
+		return
+	}
+	if !authorizedAdminRequest(r) {
+		responseHTTPError(w, http.StatusUnauthorized, "401 Unauthorized")
+		return
+	}
+
+	deadLetters := o.externalPlugins.DeadLetters
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, DeadLettersPath), "/")
+	parts := strings.Split(path, "/")
+	switch {
+	case path == "" && r.Method == http.MethodGet:
+		writeJSON(w, deadLetters.List())
+	case len(parts) == 1 && r.Method == http.MethodGet:
+		letter := deadLetters.Get(parts[0])
+		if letter == nil {
+			responseHTTPError(w, http.StatusNotFound, fmt.Sprintf("404 Not Found: no dead letter %s", parts[0]))
+			return
+		}
+		writeJSON(w, letter)
+	case len(parts) == 1 && r.Method == http.MethodDelete:
+		if !deadLetters.Remove(parts[0]) {
+			responseHTTPError(w, http.StatusNotFound, fmt.Sprintf("404 Not Found: no dead letter %s", parts[0]))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case len(parts) == 2 && parts[1] == "redeliver" && r.Method == http.MethodPost:
+		o.redeliverDeadLetter(w, parts[0])
+	default:
+		responseHTTPError(w, http.StatusNotFound, fmt.Sprintf("404 Not Found: unknown request %s %s", r.Method, r.URL.Path))
+	}
+}
+
+// redeliverDeadLetter delivers an undelivered event to its external plugin again
+func (o *WebhooksController) redeliverDeadLetter(w http.ResponseWriter, id string) {
+	letter := o.externalPlugins.DeadLetters.Get(id)
+	if letter == nil {
+		responseHTTPError(w, http.StatusNotFound, fmt.Sprintf("404 Not Found: no dead letter %s", id))
+		return
+	}
+	l := logrus.WithField("DeadLetter", id).WithField("external-plugin", letter.Plugin.Name)
+	l.Info("redelivering event to external plugin")
+	if err := o.externalPlugins.Redeliver(l, letter, util.HMACToken()); err != nil {
+		responseHTTPError(w, http.StatusBadGateway, fmt.Sprintf("502 Bad Gateway: %s", err.Error()))
+		return
+	}
+	_, _ = w.Write([]byte(fmt.Sprintf("redelivered %s to external plugin %s", id, letter.Plugin.Name)))
+}
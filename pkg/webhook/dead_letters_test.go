@@ -0,0 +1,82 @@
+package webhook
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/jenkins-x/lighthouse/pkg/util"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleDeadLetters(t *testing.T) {
+	t.Setenv(adminTokenEnvVar, "admin-token")
+	t.Setenv("HMAC_TOKEN", "hmac-token")
+
+	status := int32(http.StatusBadRequest)
+	var signature atomic.Value
+	plugin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signature.Store(r.Header.Get(util.LighthouseSignatureHeader))
+		w.WriteHeader(int(atomic.LoadInt32(&status)))
+	}))
+	defer plugin.Close()
+
+	o := &WebhooksController{
+		externalPlugins: util.NewExternalPluginDispatcher(util.NewDeadLetterStore(10)),
+	}
+	p := plugins.ExternalPlugin{Name: "plugin", Endpoint: plugin.URL}
+	headers := http.Header{}
+	headers.Set(util.LighthouseSignatureHeader, "sha256=signature")
+	require.Error(t, o.externalPlugins.Deliver(logrus.WithField("test", t.Name()), p, []byte("payload"), headers))
+
+	request := func(method, path, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		o.HandleDeadLetters(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, request(http.MethodGet, DeadLettersPath, "").Code)
+	assert.Equal(t, http.StatusUnauthorized, request(http.MethodGet, DeadLettersPath, "hmac-token").Code, "the HMAC token should not be accepted")
+
+	w := request(http.MethodGet, DeadLettersPath, "admin-token")
+	require.Equal(t, http.StatusOK, w.Code)
+	var letters []*util.DeadLetter
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &letters))
+	require.Len(t, letters, 1)
+	assert.Equal(t, "plugin", letters[0].Plugin.Name)
+	id := letters[0].ID
+
+	w = request(http.MethodGet, DeadLettersPath+"/"+id, "admin-token")
+	require.Equal(t, http.StatusOK, w.Code)
+	letter := &util.DeadLetter{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), letter))
+	assert.Equal(t, "payload", letter.Payload)
+	assert.Empty(t, letter.Headers.Get(util.LighthouseSignatureHeader), "the signature should not be served")
+
+	assert.Equal(t, http.StatusNotFound, request(http.MethodGet, DeadLettersPath+"/unknown", "admin-token").Code)
+	assert.Equal(t, http.StatusNotFound, request(http.MethodPost, DeadLettersPath+"/unknown/redeliver", "admin-token").Code)
+
+	atomic.StoreInt32(&status, http.StatusOK)
+	w = request(http.MethodPost, DeadLettersPath+"/"+id+"/redeliver", "admin-token")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Empty(t, o.externalPlugins.DeadLetters.List())
+	assert.Equal(t, util.CreateHMACHeader([]byte("payload"), "hmac-token"), signature.Load(), "the redelivered payload should be signed again")
+
+	atomic.StoreInt32(&status, http.StatusBadRequest)
+	require.Error(t, o.externalPlugins.Deliver(logrus.WithField("test", t.Name()), p, []byte("payload"), http.Header{}))
+	id = o.externalPlugins.DeadLetters.List()[0].ID
+	assert.Equal(t, http.StatusNoContent, request(http.MethodDelete, DeadLettersPath+"/"+id, "admin-token").Code)
+	assert.Empty(t, o.externalPlugins.DeadLetters.List())
+
+	o.externalPlugins.DeadLetters = nil
+	assert.Equal(t, http.StatusNotFound, request(http.MethodGet, DeadLettersPath, "admin-token").Code)
+}
@@ -0,0 +1,273 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/uuid"
+)
+
+const (
+	// DeliveriesPath the URL path of the endpoints listing and replaying deliveries
+	DeliveriesPath = "/deliveries"
+
+	// deliveryLogSizeEnvVar the environment variable enabling the delivery log with the number of deliveries kept
+	deliveryLogSizeEnvVar = "LIGHTHOUSE_DELIVERY_LOG_SIZE"
+)
+
+// deliveryIDHeaders the headers used by the git providers to identify a webhook delivery
+var deliveryIDHeaders = []string{
+	"X-GitHub-Delivery",
+	"X-Gitea-Delivery",
+	"X-Gogs-Delivery",
+	"X-Gitlab-Event-UUID",
+	"X-Request-Id",
+	"X-Hook-UUID",
+}
+
+// Delivery a webhook or poll request received by the webhooks service
+type Delivery struct {
+	GUID       string        `json:"guid"`
+	Operation  string        `json:"operation"`
+	Kind       string        `json:"kind,omitempty"`
+	Repository string        `json:"repository,omitempty"`
+	ReceivedAt time.Time     `json:"receivedAt"`
+	Duration   time.Duration `json:"duration"`
+	StatusCode int           `json:"statusCode"`
+	Result     string        `json:"result,omitempty"`
+	ReplayOf   string        `json:"replayOf,omitempty"`
+	Headers    http.Header   `json:"headers,omitempty"`
+	Body       string        `json:"body,omitempty"`
+
+	// verified is true once the request has been parsed with its signature checked, only these deliveries are replayed
+	verified bool
+}
+
+// summary returns a copy of the delivery without the raw request
+func (d *Delivery) summary() *Delivery {
+	answer := *d
+	answer.Headers = nil
+	answer.Body = ""
+	return &answer
+}
+
+// DeliveryLog keeps the most recent deliveries in memory
+type DeliveryLog struct {
+	lock       sync.RWMutex
+	maxSize    int
+	deliveries []*Delivery
+}
+
+// NewDeliveryLog creates a delivery log keeping at most the given number of deliveries
+func NewDeliveryLog(maxSize int) *DeliveryLog {
+	return &DeliveryLog{
+		maxSize: maxSize,
+	}
+}
+
+// newDeliveryLogFromEnv creates a delivery log sized from the environment, returning nil if it is not enabled
+func newDeliveryLogFromEnv() *DeliveryLog {
+	text := os.Getenv(deliveryLogSizeEnvVar)
+	if text == "" {
+		return nil
+	}
+	size, err := strconv.Atoi(text)
+	if err != nil {
+		logrus.WithError(err).Warnf("invalid $%s %q, the delivery log is disabled", deliveryLogSizeEnvVar, text)
+		return nil
+	}
+	if size <= 0 {
+		return nil
+	}
+	return NewDeliveryLog(size)
+}
+
+// Add adds the delivery, discarding the oldest one when the log is full
+func (l *DeliveryLog) Add(d *Delivery) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.deliveries = append(l.deliveries, d)
+	if len(l.deliveries) > l.maxSize {
+		l.deliveries = l.deliveries[len(l.deliveries)-l.maxSize:]
+	}
+}
+
+// List returns a summary of the deliveries, most recent first
+func (l *DeliveryLog) List() []*Delivery {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+
+	answer := make([]*Delivery, 0, len(l.deliveries))
+	for i := len(l.deliveries) - 1; i >= 0; i-- {
+		answer = append(answer, l.deliveries[i].summary())
+	}
+	return answer
+}
+
+// Get returns the most recent delivery with the given GUID or nil if it is not found
+func (l *DeliveryLog) Get(guid string) *Delivery {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+
+	for i := len(l.deliveries) - 1; i >= 0; i-- {
+		if l.deliveries[i].GUID == guid {
+			d := *l.deliveries[i]
+			return &d
+		}
+	}
+	return nil
+}
+
+// isSensitiveHeader returns true for the headers holding a secret or a signature made with it, such as
+// X-Hub-Signature-256 or X-Gitlab-Token, which are not kept in the delivery log
+func isSensitiveHeader(name string) bool {
+	name = strings.ToLower(name)
+	return name == "authorization" || strings.Contains(name, "signature") || strings.HasSuffix(name, "-token")
+}
+
+// newDelivery creates a delivery for the given request without its sensitive headers, replays are given a new GUID
+func newDelivery(operation, replayOf string, header http.Header, body []byte) *Delivery {
+	guid := ""
+	if replayOf == "" {
+		for _, name := range deliveryIDHeaders {
+			if guid = header.Get(name); guid != "" {
+				break
+			}
+		}
+	}
+	if guid == "" {
+		guid = string(uuid.NewUUID())
+	}
+	headers := header.Clone()
+	for name := range headers {
+		if isSensitiveHeader(name) {
+			delete(headers, name)
+		}
+	}
+	return &Delivery{
+		GUID:       guid,
+		Operation:  operation,
+		ReplayOf:   replayOf,
+		ReceivedAt: time.Now(),
+		Headers:    headers,
+		Body:       string(body),
+	}
+}
+
+// deliveryResponseWriter records the response sent for a delivery
+type deliveryResponseWriter struct {
+	http.ResponseWriter
+	statusCode int
+	body       bytes.Buffer
+}
+
+func (w *deliveryResponseWriter) WriteHeader(statusCode int) {
+	w.statusCode = statusCode
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *deliveryResponseWriter) Write(data []byte) (int, error) {
+	if w.statusCode == 0 {
+		w.statusCode = http.StatusOK
+	}
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+// complete records the outcome of the delivery
+func (w *deliveryResponseWriter) complete(d *Delivery) {
+	d.Duration = time.Since(d.ReceivedAt)
+	d.StatusCode = w.statusCode
+	if d.StatusCode == 0 {
+		d.StatusCode = http.StatusOK
+	}
+	d.Result = strings.TrimSpace(w.body.String())
+}
+
+// HandleDeliveries lists the recent deliveries on GET <path>, returns a single delivery including the raw request
+// on GET <path>/<guid> and replays a delivery on POST <path>/<guid>/replay
+func (o *WebhooksController) HandleDeliveries(w http.ResponseWriter, r *http.Request) {
+	if o.deliveries == nil {
+		responseHTTPError(w, http.StatusNotFound, "404 Not Found: the delivery log is disabled")
+		return
+	}
+	if !authorizedAdminRequest(r) {
+		responseHTTPError(w, http.StatusUnauthorized, "401 Unauthorized")
+		return
+	}
+
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, DeliveriesPath), "/")
+	parts := strings.Split(path, "/")
+	switch {
+	case path == "" && r.Method == http.MethodGet:
+		writeJSON(w, o.deliveries.List())
+	case len(parts) == 1 && r.Method == http.MethodGet:
+		d := o.deliveries.Get(parts[0])
+		if d == nil {
+			responseHTTPError(w, http.StatusNotFound, fmt.Sprintf("404 Not Found: no delivery %s", parts[0]))
+			return
+		}
+		writeJSON(w, d)
+	case len(parts) == 2 && parts[1] == "replay" && r.Method == http.MethodPost:
+		o.replayDelivery(w, parts[0])
+	default:
+		responseHTTPError(w, http.StatusNotFound, fmt.Sprintf("404 Not Found: unknown request %s %s", r.Method, r.URL.Path))
+	}
+}
+
+// replayDelivery feeds a stored delivery back through the webhook or poll handler. The signature is not kept in
+// the log so it is not checked again, only the deliveries whose signature was checked when received are replayed.
+func (o *WebhooksController) replayDelivery(w http.ResponseWriter, guid string) {
+	d := o.deliveries.Get(guid)
+	if d == nil {
+		responseHTTPError(w, http.StatusNotFound, fmt.Sprintf("404 Not Found: no delivery %s", guid))
+		return
+	}
+	if !d.verified {
+		responseHTTPError(w, http.StatusConflict, fmt.Sprintf("409 Conflict: delivery %s was not accepted so it cannot be replayed", guid))
+		return
+	}
+	req, err := http.NewRequest(http.MethodPost, o.path, io.NopCloser(strings.NewReader(d.Body)))
+	if err != nil {
+		responseHTTPError(w, http.StatusInternalServerError, fmt.Sprintf("500 Internal Server Error: %s", err.Error()))
+		return
+	}
+	req.Header = d.Headers.Clone()
+
+	logrus.WithField("GUID", guid).WithField("Operation", d.Operation).Info("replaying delivery")
+	if d.Operation == pollOperation {
+		o.handleWebhookOrPollRequest(w, req, d.Operation, guid, func(scmClient *scm.Client, r *http.Request) (scm.Webhook, error) {
+			return parsePollRequest(r, noSecret)
+		})
+		return
+	}
+	o.handleWebhookOrPollRequest(w, req, d.Operation, guid, func(scmClient *scm.Client, r *http.Request) (scm.Webhook, error) {
+		return scmClient.Webhooks.Parse(r, noSecret)
+	})
+}
+
+// noSecret skips the signature check of the replayed deliveries
+func noSecret(scm.Webhook) (string, error) {
+	return "", nil
+}
+
+func writeJSON(w http.ResponseWriter, value interface{}) {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		responseHTTPError(w, http.StatusInternalServerError, fmt.Sprintf("500 Internal Server Error: %s", err.Error()))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(data)
+}
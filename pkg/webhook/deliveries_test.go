@@ -0,0 +1,126 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/config"
+	"github.com/jenkins-x/lighthouse/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeliveryLogIsBounded(t *testing.T) {
+	log := NewDeliveryLog(2)
+	for i := 1; i <= 3; i++ {
+		log.Add(&Delivery{GUID: strconv.Itoa(i), Body: "body"})
+	}
+
+	deliveries := log.List()
+	require.Len(t, deliveries, 2)
+	assert.Equal(t, "3", deliveries[0].GUID)
+	assert.Equal(t, "2", deliveries[1].GUID)
+	assert.Empty(t, deliveries[0].Body, "listing should not include the raw request")
+
+	assert.Nil(t, log.Get("1"))
+	require.NotNil(t, log.Get("2"))
+	assert.Equal(t, "body", log.Get("2").Body)
+}
+
+func TestHandleDeliveries(t *testing.T) {
+	t.Setenv("GIT_TOKEN", "abc123")
+	t.Setenv("HMAC_TOKEN", "secret")
+	t.Setenv(adminTokenEnvVar, "admin-token")
+	t.Setenv("KUBECONFIG", filepath.Join(t.TempDir(), "missing"))
+
+	configBytes, err := os.ReadFile(filepath.Join("test_data", "test_config.yaml"))
+	require.NoError(t, err)
+	loadedConfig, err := config.LoadYAMLConfig(configBytes)
+	require.NoError(t, err)
+	configAgent := &config.Agent{}
+	configAgent.Set(loadedConfig)
+
+	o := &WebhooksController{
+		path:       "/hook",
+		server:     &Server{ConfigAgent: configAgent},
+		deliveries: NewDeliveryLog(10),
+	}
+
+	data, err := json.Marshal(&scm.WebhookWrapper{
+		PushHook: &scm.PushHook{
+			Ref:  "refs/heads/master",
+			Repo: scm.Repository{Namespace: "test-org", Name: "test-repo", FullName: "test-org/test-repo"},
+		},
+	})
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, "/hook/poll", bytes.NewReader(data))
+	req.Header.Set("X-Hub-Signature", util.CreateHMACHeader(data, "secret"))
+	req.Header.Set("X-Request-Id", "delivery-1")
+	o.HandlePollingRequests(httptest.NewRecorder(), req)
+
+	request := func(method, path, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		o.HandleDeliveries(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, request(http.MethodGet, DeliveriesPath, "").Code)
+	assert.Equal(t, http.StatusUnauthorized, request(http.MethodGet, DeliveriesPath, "secret").Code)
+
+	w := request(http.MethodGet, DeliveriesPath, "admin-token")
+	require.Equal(t, http.StatusOK, w.Code)
+	var deliveries []*Delivery
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &deliveries))
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, "delivery-1", deliveries[0].GUID)
+	assert.Equal(t, pollOperation, deliveries[0].Operation)
+	assert.Equal(t, string(scm.WebhookKindPush), deliveries[0].Kind)
+	assert.Equal(t, "test-org/test-repo", deliveries[0].Repository)
+
+	w = request(http.MethodGet, DeliveriesPath+"/delivery-1", "admin-token")
+	require.Equal(t, http.StatusOK, w.Code)
+	delivery := &Delivery{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), delivery))
+	assert.Equal(t, string(data), delivery.Body)
+	assert.Equal(t, "delivery-1", delivery.Headers.Get("X-Request-Id"))
+	assert.Empty(t, delivery.Headers.Get("X-Hub-Signature"), "the signature should not be kept")
+
+	assert.Equal(t, http.StatusNotFound, request(http.MethodPost, DeliveriesPath+"/unknown/replay", "admin-token").Code)
+	request(http.MethodPost, DeliveriesPath+"/delivery-1/replay", "admin-token")
+
+	deliveries = o.deliveries.List()
+	require.Len(t, deliveries, 2)
+	replay := deliveries[0]
+	assert.NotEqual(t, "delivery-1", replay.GUID)
+	assert.Equal(t, "delivery-1", replay.ReplayOf)
+	assert.Equal(t, string(scm.WebhookKindPush), replay.Kind, "the replayed delivery should have been parsed again")
+
+	// the deliveries which were rejected are not replayed as their signature is not kept
+	req = httptest.NewRequest(http.MethodPost, "/hook/poll", bytes.NewReader(data))
+	req.Header.Set("X-Hub-Signature", util.CreateHMACHeader(data, "wrong"))
+	req.Header.Set("X-Request-Id", "forged")
+	o.HandlePollingRequests(httptest.NewRecorder(), req)
+	assert.Equal(t, http.StatusConflict, request(http.MethodPost, DeliveriesPath+"/forged/replay", "admin-token").Code)
+	assert.Len(t, o.deliveries.List(), 3)
+}
+
+func TestNewDeliveryLogFromEnv(t *testing.T) {
+	t.Setenv(deliveryLogSizeEnvVar, "")
+	assert.Nil(t, newDeliveryLogFromEnv(), "the delivery log should be disabled by default")
+	t.Setenv(deliveryLogSizeEnvVar, "invalid")
+	assert.Nil(t, newDeliveryLogFromEnv())
+	t.Setenv(deliveryLogSizeEnvVar, "5")
+	require.NotNil(t, newDeliveryLogFromEnv())
+	assert.Equal(t, 5, newDeliveryLogFromEnv().maxSize)
+}
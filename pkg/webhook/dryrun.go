@@ -0,0 +1,673 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	lighthouseclient "github.com/jenkins-x/lighthouse/pkg/client/clientset/versioned/typed/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	gitv2 "github.com/jenkins-x/lighthouse/pkg/git/v2"
+	"github.com/jenkins-x/lighthouse/pkg/launcher"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/jenkins-x/lighthouse/pkg/plugins/trigger"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	applycorev1 "k8s.io/client-go/applyconfigurations/core/v1"
+	kubeclient "k8s.io/client-go/kubernetes"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+)
+
+// DryRunHeader is the request header which, when set to true, processes a single event in dry-run mode
+const DryRunHeader = "X-Lighthouse-Dry-Run"
+
+// DryRunResult describes what Lighthouse would have done for an event processed in dry-run mode
+type DryRunResult struct {
+	// Output is the message returned when processing the event
+	Output string `json:"output,omitempty"`
+	// Jobs are the LighthouseJobs which would have been triggered
+	Jobs []DryRunJob `json:"jobs"`
+	// SkippedContexts are the contexts which would have been reported as skipped
+	SkippedContexts []string `json:"skippedContexts"`
+	// Statuses are the commit statuses which would have been created
+	Statuses []DryRunStatus `json:"statuses"`
+	// Labels are the labels which would have been added or removed
+	Labels []DryRunLabel `json:"labels"`
+	// Comments are the comments which would have been created or edited
+	Comments []DryRunComment `json:"comments"`
+	// Actions are the other changes which would have been made
+	Actions []string `json:"actions"`
+	// ExternalPlugins are the external plugins which would have been called
+	ExternalPlugins []string `json:"externalPlugins"`
+}
+
+// DryRunJob is a LighthouseJob which would have been triggered
+type DryRunJob struct {
+	Name    string           `json:"name"`
+	Type    job.PipelineKind `json:"type"`
+	Agent   string           `json:"agent,omitempty"`
+	Context string           `json:"context,omitempty"`
+	Refs    *v1alpha1.Refs   `json:"refs,omitempty"`
+}
+
+// DryRunStatus is a commit status which would have been created
+type DryRunStatus struct {
+	Repo        string    `json:"repo"`
+	Ref         string    `json:"ref"`
+	Context     string    `json:"context"`
+	State       scm.State `json:"state"`
+	Description string    `json:"description,omitempty"`
+}
+
+// DryRunLabel is a label which would have been added to or removed from an issue or pull request
+type DryRunLabel struct {
+	Repo    string `json:"repo"`
+	Number  int    `json:"number"`
+	Label   string `json:"label"`
+	Removed bool   `json:"removed,omitempty"`
+}
+
+// DryRunComment is a comment which would have been created or edited on an issue or pull request
+type DryRunComment struct {
+	Repo   string `json:"repo"`
+	Number int    `json:"number"`
+	Body   string `json:"body"`
+	Edited bool   `json:"edited,omitempty"`
+}
+
+// isDryRunRequest returns true if the request asks to be processed in dry-run mode
+func isDryRunRequest(r *http.Request) bool {
+	dryRun, _ := strconv.ParseBool(r.Header.Get(DryRunHeader))
+	return dryRun
+}
+
+// handleDryRun processes the webhook with clients recording their changes instead of making them, waits for
+// the plugins to complete and responds with the changes which would have been made
+func (o *WebhooksController) handleDryRun(w http.ResponseWriter, l *logrus.Entry, webhook scm.Webhook, clientAgent *plugins.ClientAgent, external []plugins.ExternalPlugin) {
+	recorder := newDryRunRecorder()
+	server, err := newDryRunServer(o.server, recorder.ClientAgent(clientAgent))
+	if err != nil {
+		responseHTTPError(w, http.StatusInternalServerError, fmt.Sprintf("500 Internal Server Error: %s", err.Error()))
+		return
+	}
+	l, output, err := o.processWebHook(server, l, webhook)
+	if err != nil {
+		responseHTTPError(w, http.StatusInternalServerError, fmt.Sprintf("500 Internal Server Error: %s", err.Error()))
+		return
+	}
+	server.wg.Wait()
+
+	result := recorder.Result()
+	result.Output = output
+	for _, p := range external {
+		result.ExternalPlugins = append(result.ExternalPlugins, p.Name)
+	}
+	data, err := json.Marshal(&result)
+	if err != nil {
+		responseHTTPError(w, http.StatusInternalServerError, fmt.Sprintf("500 Internal Server Error: %s", err.Error()))
+		return
+	}
+	l.WithFields(logrus.Fields{
+		"jobs":     len(result.Jobs),
+		"comments": len(result.Comments),
+		"labels":   len(result.Labels),
+	}).Info("processed webhook in dry-run mode")
+	w.Header().Set("Content-Type", "application/json")
+	_, err = w.Write(data)
+	if err != nil {
+		l.Debugf("failed to write the dry-run result: %v", err)
+	}
+}
+
+// newDryRunServer returns a server sharing the configuration of the given one which uses the given clients.
+// It has its own in-repo cache so that agents holding recording clients are never reused by real events,
+// and neither updates periodics nor reports metrics.
+func newDryRunServer(s *Server, clientAgent *plugins.ClientAgent) (*Server, error) {
+	cache, err := lru.New(100)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create in-repo LRU cache")
+	}
+	return &Server{
+		ClientAgent:    clientAgent,
+		Plugins:        s.Plugins,
+		ConfigAgent:    s.ConfigAgent,
+		ServerURL:      s.ServerURL,
+		TokenGenerator: s.TokenGenerator,
+		FileBrowsers:   s.FileBrowsers,
+		InRepoCache:    cache,
+	}, nil
+}
+
+// dryRunRecorder records the changes made through the clients it wraps instead of applying them
+type dryRunRecorder struct {
+	lock   sync.Mutex
+	result DryRunResult
+}
+
+func newDryRunRecorder() *dryRunRecorder {
+	return &dryRunRecorder{
+		result: DryRunResult{
+			Jobs:            []DryRunJob{},
+			SkippedContexts: []string{},
+			Statuses:        []DryRunStatus{},
+			Labels:          []DryRunLabel{},
+			Comments:        []DryRunComment{},
+			Actions:         []string{},
+			ExternalPlugins: []string{},
+		},
+	}
+}
+
+func (r *dryRunRecorder) record(fn func(result *DryRunResult)) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	fn(&r.result)
+}
+
+func (r *dryRunRecorder) recordAction(format string, args ...interface{}) {
+	r.record(func(result *DryRunResult) {
+		result.Actions = append(result.Actions, fmt.Sprintf(format, args...))
+	})
+}
+
+// Result returns a copy of the recorded changes
+func (r *dryRunRecorder) Result() DryRunResult {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	result := r.result
+	result.Jobs = append([]DryRunJob{}, r.result.Jobs...)
+	result.SkippedContexts = append([]string{}, r.result.SkippedContexts...)
+	result.Statuses = append([]DryRunStatus{}, r.result.Statuses...)
+	result.Labels = append([]DryRunLabel{}, r.result.Labels...)
+	result.Comments = append([]DryRunComment{}, r.result.Comments...)
+	result.Actions = append([]string{}, r.result.Actions...)
+	result.ExternalPlugins = append([]string{}, r.result.ExternalPlugins...)
+	return result
+}
+
+// ClientAgent returns a copy of the client agent whose launcher and mutating calls are recorded
+func (r *dryRunRecorder) ClientAgent(ca *plugins.ClientAgent) *plugins.ClientAgent {
+	answer := *ca
+	answer.LauncherClient = &dryRunLauncher{recorder: r}
+	if ca.SCMProviderClient != nil {
+		answer.SCMProviderClient = r.SCMClient(ca.SCMProviderClient)
+	}
+	if ca.LighthouseClient != nil {
+		answer.LighthouseClient = &dryRunLighthouseJobs{LighthouseJobInterface: ca.LighthouseClient, recorder: r}
+	}
+	if ca.KubernetesClient != nil {
+		answer.KubernetesClient = &dryRunKubeClient{Interface: ca.KubernetesClient, recorder: r}
+	}
+	if ca.GitClientFactory != nil {
+		answer.GitClientFactory = &dryRunGitClientFactory{ClientFactory: ca.GitClientFactory, recorder: r}
+	}
+	return &answer
+}
+
+// SCMClient returns a client sharing the given client's transport whose mutating calls are recorded
+func (r *dryRunRecorder) SCMClient(client *scm.Client) *scm.Client {
+	return &scm.Client{
+		Client:        client.Client,
+		BaseURL:       client.BaseURL,
+		GraphQLURL:    client.GraphQLURL,
+		Username:      client.Username,
+		Driver:        client.Driver,
+		Apps:          client.Apps,
+		Contents:      client.Contents,
+		Deployments:   client.Deployments,
+		Git:           &dryRunGitService{GitService: client.Git, recorder: r},
+		GraphQL:       client.GraphQL,
+		Organizations: client.Organizations,
+		Issues:        &dryRunIssueService{IssueService: client.Issues, recorder: r},
+		Milestones:    client.Milestones,
+		Releases:      client.Releases,
+		PullRequests:  &dryRunPullRequestService{PullRequestService: client.PullRequests, recorder: r},
+		Repositories:  &dryRunRepositoryService{RepositoryService: client.Repositories, recorder: r},
+		Reviews:       &dryRunReviewService{ReviewService: client.Reviews, recorder: r},
+		Users:         client.Users,
+		Webhooks:      client.Webhooks,
+		Commits:       client.Commits,
+		DumpResponse:  client.DumpResponse,
+	}
+}
+
+func (r *dryRunRecorder) recordLabel(repo string, number int, label string, removed bool) (*scm.Response, error) {
+	r.record(func(result *DryRunResult) {
+		result.Labels = append(result.Labels, DryRunLabel{Repo: repo, Number: number, Label: label, Removed: removed})
+	})
+	return &scm.Response{}, nil
+}
+
+func (r *dryRunRecorder) recordComment(repo string, number int, input *scm.CommentInput, edited bool) (*scm.Comment, *scm.Response, error) {
+	r.record(func(result *DryRunResult) {
+		result.Comments = append(result.Comments, DryRunComment{Repo: repo, Number: number, Body: input.Body, Edited: edited})
+	})
+	return &scm.Comment{Body: input.Body}, &scm.Response{}, nil
+}
+
+// dryRunLauncher records the LighthouseJobs it is asked to launch
+type dryRunLauncher struct {
+	recorder *dryRunRecorder
+}
+
+var _ launcher.PipelineLauncher = &dryRunLauncher{}
+
+// Launch records the job instead of creating it
+func (l *dryRunLauncher) Launch(lhjob *v1alpha1.LighthouseJob) (*v1alpha1.LighthouseJob, error) {
+	l.recorder.record(func(result *DryRunResult) {
+		result.Jobs = append(result.Jobs, DryRunJob{
+			Name:    lhjob.Spec.Job,
+			Type:    lhjob.Spec.Type,
+			Agent:   lhjob.Spec.Agent,
+			Context: lhjob.Spec.Context,
+			Refs:    lhjob.Spec.Refs,
+		})
+	})
+	answer := lhjob.DeepCopy()
+	answer.Status.State = v1alpha1.TriggeredState
+	return answer, nil
+}
+
+// dryRunIssueService records the mutating issue calls
+type dryRunIssueService struct {
+	scm.IssueService
+	recorder *dryRunRecorder
+}
+
+func (s *dryRunIssueService) Create(_ context.Context, repo string, input *scm.IssueInput) (*scm.Issue, *scm.Response, error) {
+	s.recorder.recordAction("create issue %q in %s", input.Title, repo)
+	return &scm.Issue{Title: input.Title, Body: input.Body}, &scm.Response{}, nil
+}
+
+func (s *dryRunIssueService) CreateComment(_ context.Context, repo string, number int, input *scm.CommentInput) (*scm.Comment, *scm.Response, error) {
+	return s.recorder.recordComment(repo, number, input, false)
+}
+
+func (s *dryRunIssueService) DeleteComment(_ context.Context, repo string, number, id int) (*scm.Response, error) {
+	s.recorder.recordAction("delete comment %d on %s#%d", id, repo, number)
+	return &scm.Response{}, nil
+}
+
+func (s *dryRunIssueService) EditComment(_ context.Context, repo string, number, _ int, input *scm.CommentInput) (*scm.Comment, *scm.Response, error) {
+	return s.recorder.recordComment(repo, number, input, true)
+}
+
+func (s *dryRunIssueService) Close(_ context.Context, repo string, number int) (*scm.Response, error) {
+	s.recorder.recordAction("close %s#%d", repo, number)
+	return &scm.Response{}, nil
+}
+
+func (s *dryRunIssueService) Reopen(_ context.Context, repo string, number int) (*scm.Response, error) {
+	s.recorder.recordAction("reopen %s#%d", repo, number)
+	return &scm.Response{}, nil
+}
+
+func (s *dryRunIssueService) Lock(_ context.Context, repo string, number int) (*scm.Response, error) {
+	s.recorder.recordAction("lock %s#%d", repo, number)
+	return &scm.Response{}, nil
+}
+
+func (s *dryRunIssueService) Unlock(_ context.Context, repo string, number int) (*scm.Response, error) {
+	s.recorder.recordAction("unlock %s#%d", repo, number)
+	return &scm.Response{}, nil
+}
+
+func (s *dryRunIssueService) AddLabel(_ context.Context, repo string, number int, label string) (*scm.Response, error) {
+	return s.recorder.recordLabel(repo, number, label, false)
+}
+
+func (s *dryRunIssueService) DeleteLabel(_ context.Context, repo string, number int, label string) (*scm.Response, error) {
+	return s.recorder.recordLabel(repo, number, label, true)
+}
+
+func (s *dryRunIssueService) AssignIssue(_ context.Context, repo string, number int, logins []string) (*scm.Response, error) {
+	s.recorder.recordAction("assign %s to %s#%d", strings.Join(logins, ", "), repo, number)
+	return &scm.Response{}, nil
+}
+
+func (s *dryRunIssueService) UnassignIssue(_ context.Context, repo string, number int, logins []string) (*scm.Response, error) {
+	s.recorder.recordAction("unassign %s from %s#%d", strings.Join(logins, ", "), repo, number)
+	return &scm.Response{}, nil
+}
+
+func (s *dryRunIssueService) SetMilestone(_ context.Context, repo string, number, milestone int) (*scm.Response, error) {
+	s.recorder.recordAction("set milestone %d on %s#%d", milestone, repo, number)
+	return &scm.Response{}, nil
+}
+
+func (s *dryRunIssueService) ClearMilestone(_ context.Context, repo string, number int) (*scm.Response, error) {
+	s.recorder.recordAction("clear milestone on %s#%d", repo, number)
+	return &scm.Response{}, nil
+}
+
+// dryRunPullRequestService records the mutating pull request calls
+type dryRunPullRequestService struct {
+	scm.PullRequestService
+	recorder *dryRunRecorder
+}
+
+func (s *dryRunPullRequestService) Update(_ context.Context, repo string, number int, input *scm.PullRequestInput) (*scm.PullRequest, *scm.Response, error) {
+	s.recorder.recordAction("update %s#%d", repo, number)
+	return &scm.PullRequest{Number: number, Title: input.Title, Body: input.Body}, &scm.Response{}, nil
+}
+
+func (s *dryRunPullRequestService) Merge(_ context.Context, repo string, number int, _ *scm.PullRequestMergeOptions) (*scm.Response, error) {
+	s.recorder.recordAction("merge %s#%d", repo, number)
+	return &scm.Response{}, nil
+}
+
+func (s *dryRunPullRequestService) Close(_ context.Context, repo string, number int) (*scm.Response, error) {
+	s.recorder.recordAction("close %s#%d", repo, number)
+	return &scm.Response{}, nil
+}
+
+func (s *dryRunPullRequestService) Reopen(_ context.Context, repo string, number int) (*scm.Response, error) {
+	s.recorder.recordAction("reopen %s#%d", repo, number)
+	return &scm.Response{}, nil
+}
+
+func (s *dryRunPullRequestService) CreateComment(_ context.Context, repo string, number int, input *scm.CommentInput) (*scm.Comment, *scm.Response, error) {
+	return s.recorder.recordComment(repo, number, input, false)
+}
+
+func (s *dryRunPullRequestService) DeleteComment(_ context.Context, repo string, number, id int) (*scm.Response, error) {
+	s.recorder.recordAction("delete comment %d on %s#%d", id, repo, number)
+	return &scm.Response{}, nil
+}
+
+func (s *dryRunPullRequestService) EditComment(_ context.Context, repo string, number, _ int, input *scm.CommentInput) (*scm.Comment, *scm.Response, error) {
+	return s.recorder.recordComment(repo, number, input, true)
+}
+
+func (s *dryRunPullRequestService) AddLabel(_ context.Context, repo string, number int, label string) (*scm.Response, error) {
+	return s.recorder.recordLabel(repo, number, label, false)
+}
+
+func (s *dryRunPullRequestService) DeleteLabel(_ context.Context, repo string, number int, label string) (*scm.Response, error) {
+	return s.recorder.recordLabel(repo, number, label, true)
+}
+
+func (s *dryRunPullRequestService) AssignIssue(_ context.Context, repo string, number int, logins []string) (*scm.Response, error) {
+	s.recorder.recordAction("assign %s to %s#%d", strings.Join(logins, ", "), repo, number)
+	return &scm.Response{}, nil
+}
+
+func (s *dryRunPullRequestService) UnassignIssue(_ context.Context, repo string, number int, logins []string) (*scm.Response, error) {
+	s.recorder.recordAction("unassign %s from %s#%d", strings.Join(logins, ", "), repo, number)
+	return &scm.Response{}, nil
+}
+
+func (s *dryRunPullRequestService) Create(_ context.Context, repo string, input *scm.PullRequestInput) (*scm.PullRequest, *scm.Response, error) {
+	s.recorder.recordAction("create pull request %q in %s", input.Title, repo)
+	return &scm.PullRequest{Title: input.Title, Body: input.Body}, &scm.Response{}, nil
+}
+
+func (s *dryRunPullRequestService) RequestReview(_ context.Context, repo string, number int, logins []string) (*scm.Response, error) {
+	s.recorder.recordAction("request review from %s on %s#%d", strings.Join(logins, ", "), repo, number)
+	return &scm.Response{}, nil
+}
+
+func (s *dryRunPullRequestService) UnrequestReview(_ context.Context, repo string, number int, logins []string) (*scm.Response, error) {
+	s.recorder.recordAction("unrequest review from %s on %s#%d", strings.Join(logins, ", "), repo, number)
+	return &scm.Response{}, nil
+}
+
+func (s *dryRunPullRequestService) SetMilestone(_ context.Context, repo string, number, milestone int) (*scm.Response, error) {
+	s.recorder.recordAction("set milestone %d on %s#%d", milestone, repo, number)
+	return &scm.Response{}, nil
+}
+
+func (s *dryRunPullRequestService) ClearMilestone(_ context.Context, repo string, number int) (*scm.Response, error) {
+	s.recorder.recordAction("clear milestone on %s#%d", repo, number)
+	return &scm.Response{}, nil
+}
+
+func (s *dryRunPullRequestService) DeletePullRequest(_ context.Context, repo string, number int) (*scm.Response, error) {
+	s.recorder.recordAction("delete %s#%d", repo, number)
+	return &scm.Response{}, nil
+}
+
+// dryRunRepositoryService records the mutating repository calls
+type dryRunRepositoryService struct {
+	scm.RepositoryService
+	recorder *dryRunRecorder
+}
+
+func (s *dryRunRepositoryService) Create(_ context.Context, input *scm.RepositoryInput) (*scm.Repository, *scm.Response, error) {
+	s.recorder.recordAction("create repository %s/%s", input.Namespace, input.Name)
+	return &scm.Repository{Namespace: input.Namespace, Name: input.Name}, &scm.Response{}, nil
+}
+
+func (s *dryRunRepositoryService) Fork(_ context.Context, input *scm.RepositoryInput, origRepo string) (*scm.Repository, *scm.Response, error) {
+	s.recorder.recordAction("fork %s", origRepo)
+	return &scm.Repository{Namespace: input.Namespace, Name: input.Name}, &scm.Response{}, nil
+}
+
+func (s *dryRunRepositoryService) CreateHook(_ context.Context, repo string, input *scm.HookInput) (*scm.Hook, *scm.Response, error) {
+	s.recorder.recordAction("create hook %s in %s", input.Target, repo)
+	return &scm.Hook{Name: input.Name, Target: input.Target}, &scm.Response{}, nil
+}
+
+func (s *dryRunRepositoryService) UpdateHook(_ context.Context, repo string, input *scm.HookInput) (*scm.Hook, *scm.Response, error) {
+	s.recorder.recordAction("update hook %s in %s", input.Target, repo)
+	return &scm.Hook{Name: input.Name, Target: input.Target}, &scm.Response{}, nil
+}
+
+func (s *dryRunRepositoryService) DeleteHook(_ context.Context, repo, id string) (*scm.Response, error) {
+	s.recorder.recordAction("delete hook %s in %s", id, repo)
+	return &scm.Response{}, nil
+}
+
+func (s *dryRunRepositoryService) CreateStatus(_ context.Context, repo, ref string, input *scm.StatusInput) (*scm.Status, *scm.Response, error) {
+	s.recorder.record(func(result *DryRunResult) {
+		result.Statuses = append(result.Statuses, DryRunStatus{
+			Repo:        repo,
+			Ref:         ref,
+			Context:     input.Label,
+			State:       input.State,
+			Description: input.Desc,
+		})
+		if input.Desc == trigger.SkippedStatusDescription {
+			result.SkippedContexts = append(result.SkippedContexts, input.Label)
+		}
+	})
+	return &scm.Status{State: input.State, Label: input.Label, Desc: input.Desc, Target: input.Target}, &scm.Response{}, nil
+}
+
+func (s *dryRunRepositoryService) AddCollaborator(_ context.Context, repo, user, permission string) (bool, bool, *scm.Response, error) {
+	s.recorder.recordAction("add collaborator %s to %s with %s permission", user, repo, permission)
+	return true, false, &scm.Response{}, nil
+}
+
+func (s *dryRunRepositoryService) Delete(_ context.Context, repo string) (*scm.Response, error) {
+	s.recorder.recordAction("delete repository %s", repo)
+	return &scm.Response{}, nil
+}
+
+// dryRunGitService records the mutating git calls
+type dryRunGitService struct {
+	scm.GitService
+	recorder *dryRunRecorder
+}
+
+func (s *dryRunGitService) CreateRef(_ context.Context, repo, ref, sha string) (*scm.Reference, *scm.Response, error) {
+	s.recorder.recordAction("create ref %s at %s in %s", ref, sha, repo)
+	return &scm.Reference{Name: ref, Sha: sha}, &scm.Response{}, nil
+}
+
+func (s *dryRunGitService) DeleteRef(_ context.Context, repo, ref string) (*scm.Response, error) {
+	s.recorder.recordAction("delete ref %s in %s", ref, repo)
+	return &scm.Response{}, nil
+}
+
+// dryRunGitClientFactory creates git clients whose pushes are recorded
+type dryRunGitClientFactory struct {
+	gitv2.ClientFactory
+	recorder *dryRunRecorder
+}
+
+func (f *dryRunGitClientFactory) ClientFor(org, repo string, sparseCheckoutPatterns []string) (gitv2.RepoClient, error) {
+	client, err := f.ClientFactory.ClientFor(org, repo, sparseCheckoutPatterns)
+	if err != nil {
+		return nil, err
+	}
+	return &dryRunRepoClient{RepoClient: client, repo: org + "/" + repo, recorder: f.recorder}, nil
+}
+
+// dryRunRepoClient records the pushes of a local clone
+type dryRunRepoClient struct {
+	gitv2.RepoClient
+	repo     string
+	recorder *dryRunRecorder
+}
+
+func (c *dryRunRepoClient) PushToFork(branch string, force bool) error {
+	c.recorder.recordAction("push branch %s to the fork of %s", branch, c.repo)
+	return nil
+}
+
+func (c *dryRunRepoClient) PushToNamedFork(forkName, branch string, force bool) error {
+	c.recorder.recordAction("push branch %s to the %s fork of %s", branch, forkName, c.repo)
+	return nil
+}
+
+func (c *dryRunRepoClient) PushToCentral(branch string, force bool) error {
+	c.recorder.recordAction("push branch %s to %s", branch, c.repo)
+	return nil
+}
+
+// dryRunReviewService records the mutating review calls
+type dryRunReviewService struct {
+	scm.ReviewService
+	recorder *dryRunRecorder
+}
+
+func (s *dryRunReviewService) Create(_ context.Context, repo string, number int, input *scm.ReviewInput) (*scm.Review, *scm.Response, error) {
+	s.recorder.recordAction("create review on %s#%d", repo, number)
+	return &scm.Review{Body: input.Body}, &scm.Response{}, nil
+}
+
+func (s *dryRunReviewService) Delete(_ context.Context, repo string, number, id int) (*scm.Response, error) {
+	s.recorder.recordAction("delete review %d on %s#%d", id, repo, number)
+	return &scm.Response{}, nil
+}
+
+func (s *dryRunReviewService) Update(_ context.Context, repo string, number, id int, body string) (*scm.Review, *scm.Response, error) {
+	s.recorder.recordAction("update review %d on %s#%d", id, repo, number)
+	return &scm.Review{ID: id, Body: body}, &scm.Response{}, nil
+}
+
+func (s *dryRunReviewService) Submit(_ context.Context, repo string, number, id int, input *scm.ReviewSubmitInput) (*scm.Review, *scm.Response, error) {
+	s.recorder.recordAction("submit review %d on %s#%d", id, repo, number)
+	return &scm.Review{ID: id, Body: input.Body}, &scm.Response{}, nil
+}
+
+func (s *dryRunReviewService) Dismiss(_ context.Context, repo string, number, id int, msg string) (*scm.Review, *scm.Response, error) {
+	s.recorder.recordAction("dismiss review %d on %s#%d", id, repo, number)
+	return &scm.Review{ID: id}, &scm.Response{}, nil
+}
+
+// dryRunLighthouseJobs records the changes to LighthouseJobs
+type dryRunLighthouseJobs struct {
+	lighthouseclient.LighthouseJobInterface
+	recorder *dryRunRecorder
+}
+
+func (c *dryRunLighthouseJobs) Create(_ context.Context, lhjob *v1alpha1.LighthouseJob, _ metav1.CreateOptions) (*v1alpha1.LighthouseJob, error) {
+	c.recorder.recordAction("create LighthouseJob %s", lhjob.Spec.Job)
+	return lhjob, nil
+}
+
+func (c *dryRunLighthouseJobs) Update(_ context.Context, lhjob *v1alpha1.LighthouseJob, _ metav1.UpdateOptions) (*v1alpha1.LighthouseJob, error) {
+	c.recorder.recordAction("update LighthouseJob %s", lhjob.Name)
+	return lhjob, nil
+}
+
+func (c *dryRunLighthouseJobs) UpdateStatus(_ context.Context, lhjob *v1alpha1.LighthouseJob, _ metav1.UpdateOptions) (*v1alpha1.LighthouseJob, error) {
+	c.recorder.recordAction("update LighthouseJob %s status to %s", lhjob.Name, lhjob.Status.State)
+	return lhjob, nil
+}
+
+func (c *dryRunLighthouseJobs) Delete(_ context.Context, name string, _ metav1.DeleteOptions) error {
+	c.recorder.recordAction("delete LighthouseJob %s", name)
+	return nil
+}
+
+func (c *dryRunLighthouseJobs) DeleteCollection(_ context.Context, _ metav1.DeleteOptions, listOpts metav1.ListOptions) error {
+	c.recorder.recordAction("delete LighthouseJobs matching %q", listOpts.LabelSelector)
+	return nil
+}
+
+func (c *dryRunLighthouseJobs) Patch(ctx context.Context, name string, _ types.PatchType, _ []byte, _ metav1.PatchOptions, _ ...string) (*v1alpha1.LighthouseJob, error) {
+	c.recorder.recordAction("patch LighthouseJob %s", name)
+	return c.Get(ctx, name, metav1.GetOptions{})
+}
+
+// dryRunKubeClient records the changes to ConfigMaps, such as the ones made by the updateconfig plugin
+type dryRunKubeClient struct {
+	kubeclient.Interface
+	recorder *dryRunRecorder
+}
+
+func (c *dryRunKubeClient) CoreV1() corev1client.CoreV1Interface {
+	return &dryRunCoreV1{CoreV1Interface: c.Interface.CoreV1(), recorder: c.recorder}
+}
+
+type dryRunCoreV1 struct {
+	corev1client.CoreV1Interface
+	recorder *dryRunRecorder
+}
+
+func (c *dryRunCoreV1) ConfigMaps(namespace string) corev1client.ConfigMapInterface {
+	return &dryRunConfigMaps{ConfigMapInterface: c.CoreV1Interface.ConfigMaps(namespace), namespace: namespace, recorder: c.recorder}
+}
+
+type dryRunConfigMaps struct {
+	corev1client.ConfigMapInterface
+	namespace string
+	recorder  *dryRunRecorder
+}
+
+func (c *dryRunConfigMaps) Create(_ context.Context, cm *corev1.ConfigMap, _ metav1.CreateOptions) (*corev1.ConfigMap, error) {
+	c.recorder.recordAction("create ConfigMap %s/%s", c.namespace, cm.Name)
+	return cm, nil
+}
+
+func (c *dryRunConfigMaps) Update(_ context.Context, cm *corev1.ConfigMap, _ metav1.UpdateOptions) (*corev1.ConfigMap, error) {
+	c.recorder.recordAction("update ConfigMap %s/%s", c.namespace, cm.Name)
+	return cm, nil
+}
+
+func (c *dryRunConfigMaps) Delete(_ context.Context, name string, _ metav1.DeleteOptions) error {
+	c.recorder.recordAction("delete ConfigMap %s/%s", c.namespace, name)
+	return nil
+}
+
+func (c *dryRunConfigMaps) DeleteCollection(_ context.Context, _ metav1.DeleteOptions, listOpts metav1.ListOptions) error {
+	c.recorder.recordAction("delete ConfigMaps in %s matching %q", c.namespace, listOpts.LabelSelector)
+	return nil
+}
+
+func (c *dryRunConfigMaps) Patch(ctx context.Context, name string, _ types.PatchType, _ []byte, _ metav1.PatchOptions, _ ...string) (*corev1.ConfigMap, error) {
+	c.recorder.recordAction("patch ConfigMap %s/%s", c.namespace, name)
+	return c.Get(ctx, name, metav1.GetOptions{})
+}
+
+func (c *dryRunConfigMaps) Apply(ctx context.Context, cm *applycorev1.ConfigMapApplyConfiguration, _ metav1.ApplyOptions) (*corev1.ConfigMap, error) {
+	name := ""
+	if cm.Name != nil {
+		name = *cm.Name
+	}
+	c.recorder.recordAction("apply ConfigMap %s/%s", c.namespace, name)
+	return c.Get(ctx, name, metav1.GetOptions{})
+}
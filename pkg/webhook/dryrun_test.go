@@ -0,0 +1,100 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm"
+	scmfake "github.com/jenkins-x/go-scm/scm/driver/fake"
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/client/clientset/versioned/fake"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/jenkins-x/lighthouse/pkg/plugins/trigger"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestDryRunClientAgent(t *testing.T) {
+	scmClient, data := scmfake.NewDefault()
+	lhClient := fake.NewSimpleClientset()
+	kubeClient := kubefake.NewSimpleClientset()
+	recorder := newDryRunRecorder()
+	ca := recorder.ClientAgent(&plugins.ClientAgent{
+		BotName:           "bot",
+		SCMProviderClient: scmClient,
+		KubernetesClient:  kubeClient,
+		LighthouseClient:  lhClient.LighthouseV1alpha1().LighthouseJobs("jx"),
+	})
+	spc := scmprovider.ToClient(ca.SCMProviderClient, "bot")
+
+	require.NoError(t, spc.AddLabel("org", "repo", 1, "lgtm", true))
+	require.NoError(t, spc.RemoveLabel("org", "repo", 1, "hold", true))
+	require.NoError(t, spc.CreateComment("org", "repo", 1, true, "hello"))
+	_, err := spc.CreateStatus("org", "repo", "abc", &scm.StatusInput{State: scm.StateSuccess, Label: "lint", Desc: trigger.SkippedStatusDescription})
+	require.NoError(t, err)
+	_, err = spc.CreateStatus("org", "repo", "abc", &scm.StatusInput{State: scm.StatePending, Label: "build", Desc: "Job triggered."})
+	require.NoError(t, err)
+	require.NoError(t, spc.Merge("org", "repo", 1, scmprovider.MergeDetails{}))
+
+	launched, err := ca.LauncherClient.Launch(&v1alpha1.LighthouseJob{
+		Spec: v1alpha1.LighthouseJobSpec{
+			Type:    job.PresubmitJob,
+			Job:     "build",
+			Context: "build",
+			Refs:    &v1alpha1.Refs{Org: "org", Repo: "repo", BaseRef: "master"},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, v1alpha1.TriggeredState, launched.Status.State)
+
+	_, err = ca.LighthouseClient.Create(context.TODO(), &v1alpha1.LighthouseJob{Spec: v1alpha1.LighthouseJobSpec{Job: "build"}}, metav1.CreateOptions{})
+	require.NoError(t, err)
+	_, err = ca.KubernetesClient.CoreV1().ConfigMaps("jx").Create(context.TODO(), &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "config"}}, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	result := recorder.Result()
+	assert.Equal(t, []DryRunJob{{
+		Name:    "build",
+		Type:    job.PresubmitJob,
+		Context: "build",
+		Refs:    &v1alpha1.Refs{Org: "org", Repo: "repo", BaseRef: "master"},
+	}}, result.Jobs)
+	assert.Equal(t, []string{"lint"}, result.SkippedContexts)
+	assert.Len(t, result.Statuses, 2)
+	assert.Equal(t, []DryRunLabel{
+		{Repo: "org/repo", Number: 1, Label: "lgtm"},
+		{Repo: "org/repo", Number: 1, Label: "hold", Removed: true},
+	}, result.Labels)
+	assert.Equal(t, []DryRunComment{{Repo: "org/repo", Number: 1, Body: "hello"}}, result.Comments)
+	assert.Equal(t, []string{"merge org/repo#1", "create LighthouseJob build", "create ConfigMap jx/config"}, result.Actions)
+
+	// nothing should have been changed
+	assert.Empty(t, data.PullRequestLabelsAdded)
+	assert.Empty(t, data.PullRequestLabelsRemoved)
+	assert.Empty(t, data.PullRequestCommentsAdded)
+	assert.Empty(t, data.Statuses)
+	jobs, err := lhClient.LighthouseV1alpha1().LighthouseJobs("jx").List(context.TODO(), metav1.ListOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, jobs.Items)
+	configMaps, err := kubeClient.CoreV1().ConfigMaps("jx").List(context.TODO(), metav1.ListOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, configMaps.Items)
+}
+
+func TestIsDryRunRequest(t *testing.T) {
+	r, err := http.NewRequest(http.MethodPost, "/hook", nil)
+	require.NoError(t, err)
+	assert.False(t, isDryRunRequest(r))
+
+	r.Header.Set(DryRunHeader, "true")
+	assert.True(t, isDryRunRequest(r))
+
+	r.Header.Set(DryRunHeader, "nope")
+	assert.False(t, isDryRunRequest(r))
+}
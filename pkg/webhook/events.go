@@ -50,7 +50,10 @@ type Server struct {
 	FileBrowsers   *filebrowser.FileBrowsers
 	InRepoCache    *lru.Cache
 
-	// Tracks running handlers for graceful shutdown
+	// GitClientFactory is created along with the file browsers and shared with the plugins
+	GitClientFactory gitv2.ClientFactory
+
+	// Tracks running handlers for graceful shutdown and dry-run requests
 	wg sync.WaitGroup
 }
 
@@ -136,7 +139,9 @@ func (s *Server) handlePullRequestCommentEvent(l *logrus.Entry, pc scm.PullReque
 
 func (s *Server) handleGenericComment(l *logrus.Entry, ce *scmprovider.GenericCommentEvent) {
 	// lets invoke the agent creation async as this can take a little while
+	s.wg.Add(1)
 	go func() {
+		defer s.wg.Done()
 		agent, err := s.CreateAgent(l, ce.Repo.Namespace, ce.Repo.Name, ce.HeadSha)
 		if err != nil {
 			agent.Logger.WithError(err).Error("Error creating agent for GenericCommentEvent.")
@@ -196,7 +201,9 @@ func (s *Server) handlePushEvent(l *logrus.Entry, pe *scm.PushHook) {
 	l.Info("Push event.")
 
 	// lets invoke the agent creation async as this can take a little while
+	s.wg.Add(1)
 	go func() {
+		defer s.wg.Done()
 		c := 0
 		ref := pe.After
 		if ref == "" {
@@ -221,7 +228,7 @@ func (s *Server) handlePushEvent(l *logrus.Entry, pe *scm.PushHook) {
 		}
 		// Update periodics from the default branch
 		refBranch := strings.TrimPrefix(pe.Ref, "refs/heads/")
-		if refBranch == pe.Repository().Branch {
+		if refBranch == pe.Repository().Branch && s.PeriodicAgent != nil {
 			s.PeriodicAgent.UpdatePeriodics(s.ClientAgent.KubernetesClient, agent, pe)
 		}
 		l.WithField("count", strconv.Itoa(c)).Info("number of push handlers")
@@ -240,7 +247,9 @@ func (s *Server) handlePullRequestEvent(l *logrus.Entry, pr *scm.PullRequestHook
 	l.Infof("Pull request %s.", action)
 
 	// lets invoke the agent creation async as this can take a little while
+	s.wg.Add(1)
 	go func() {
+		defer s.wg.Done()
 		c := 0
 		repo := pr.PullRequest.Base.Repo
 		if repo.Name == "" {
@@ -317,7 +326,9 @@ func (s *Server) handleReviewEvent(l *logrus.Entry, re scm.ReviewHook) {
 	l.Infof("Review %s.", re.Action)
 
 	// lets invoke the agent creation async as this can take a little while
+	s.wg.Add(1)
 	go func() {
+		defer s.wg.Done()
 		repo := re.PullRequest.Base.Repo
 		agent, err := s.CreateAgent(l, repo.Namespace, repo.Name, re.PullRequest.Sha)
 		if err != nil {
@@ -376,7 +387,9 @@ func (s *Server) handleDeploymentStatusEvent(l *logrus.Entry, ds scm.DeploymentS
 	l.Infof("Deployment %s.", ds.Action)
 
 	// lets invoke the agent creation async as this can take a little while
+	s.wg.Add(1)
 	go func() {
+		defer s.wg.Done()
 		repo := ds.Repo
 		agent, err := s.CreateAgent(l, repo.Namespace, repo.Name, ds.Deployment.Sha)
 		if err != nil {
@@ -398,6 +411,163 @@ func (s *Server) handleDeploymentStatusEvent(l *logrus.Entry, ds scm.DeploymentS
 
 }
 
+// handleIssueEvent handles an issue event
+func (s *Server) handleIssueEvent(l *logrus.Entry, i scm.IssueHook) {
+	l = l.WithFields(logrus.Fields{
+		scmprovider.OrgLogField:  i.Repo.Namespace,
+		scmprovider.RepoLogField: i.Repo.Name,
+		scmprovider.PrLogField:   i.Issue.Number,
+		"author":                 i.Issue.Author.Login,
+		"url":                    i.Issue.Link,
+	})
+	l.Infof("Issue %s.", i.Action)
+
+	// lets invoke the agent creation async as this can take a little while
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		c := 0
+		repo := i.Repo
+		agent, err := s.CreateAgent(l, repo.Namespace, repo.Name, "")
+		if err != nil {
+			agent.Logger.WithError(err).Error("Error creating agent for IssueEvent.")
+			return
+		}
+		agent.InitializeCommentPruner(
+			repo.Namespace,
+			repo.Name,
+			i.Issue.Number,
+		)
+		for p, h := range s.getPlugins(repo.Namespace, repo.Name) {
+			if h.IssueHandler != nil {
+				s.wg.Add(1)
+				c++
+				go func(p string, h plugins.IssueHandler) {
+					defer s.wg.Done()
+					if err := h(agent, i); err != nil {
+						agent.Logger.WithField("plugin", p).WithError(err).Error("Error handling IssueEvent.")
+					}
+				}(p, h.IssueHandler)
+			}
+		}
+		l.WithField("count", strconv.Itoa(c)).Info("number of issue handlers")
+	}()
+}
+
+// handleStatusEvent handles a commit status event
+func (s *Server) handleStatusEvent(l *logrus.Entry, se scm.StatusHook) {
+	l = l.WithFields(logrus.Fields{
+		scmprovider.OrgLogField:  se.Repo.Namespace,
+		scmprovider.RepoLogField: se.Repo.Name,
+		"sender":                 se.Sender.Login,
+	})
+	l.Info("Status event.")
+
+	// lets invoke the agent creation async as this can take a little while
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		c := 0
+		repo := se.Repo
+		agent, err := s.CreateAgent(l, repo.Namespace, repo.Name, "")
+		if err != nil {
+			agent.Logger.WithError(err).Error("Error creating agent for StatusEvent.")
+			return
+		}
+		for p, h := range s.getPlugins(repo.Namespace, repo.Name) {
+			if h.StatusEventHandler != nil {
+				s.wg.Add(1)
+				c++
+				go func(p string, h plugins.StatusEventHandler) {
+					defer s.wg.Done()
+					if err := h(agent, se); err != nil {
+						agent.Logger.WithField("plugin", p).WithError(err).Error("Error handling StatusEvent.")
+					}
+				}(p, h.StatusEventHandler)
+			}
+		}
+		l.WithField("count", strconv.Itoa(c)).Info("number of status handlers")
+	}()
+}
+
+// handleTagEvent handles a tag event
+func (s *Server) handleTagEvent(l *logrus.Entry, th scm.TagHook) {
+	l = l.WithFields(logrus.Fields{
+		scmprovider.OrgLogField:  th.Repo.Namespace,
+		scmprovider.RepoLogField: th.Repo.Name,
+		"tag":                    th.Ref.Name,
+	})
+	if th.Action == scm.ActionDelete {
+		l.Info("Ignoring deletion of tag.")
+		return
+	}
+	l.Infof("Tag %s.", th.Action)
+
+	// lets invoke the agent creation async as this can take a little while
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		c := 0
+		repo := th.Repo
+		agent, err := s.CreateAgent(l, repo.Namespace, repo.Name, th.Ref.Name)
+		if err != nil {
+			agent.Logger.WithError(err).Error("Error creating agent for TagEvent.")
+			return
+		}
+		for p, h := range s.getPlugins(repo.Namespace, repo.Name) {
+			if h.TagEventHandler != nil {
+				s.wg.Add(1)
+				c++
+				go func(p string, h plugins.TagEventHandler) {
+					defer s.wg.Done()
+					if err := h(agent, th); err != nil {
+						agent.Logger.WithField("plugin", p).WithError(err).Error("Error handling TagEvent.")
+					}
+				}(p, h.TagEventHandler)
+			}
+		}
+		l.WithField("count", strconv.Itoa(c)).Info("number of tag handlers")
+	}()
+}
+
+// handleReleaseEvent handles a release event
+func (s *Server) handleReleaseEvent(l *logrus.Entry, rh scmprovider.ReleaseEvent) {
+	l = l.WithFields(logrus.Fields{
+		scmprovider.OrgLogField:  rh.Repo.Namespace,
+		scmprovider.RepoLogField: rh.Repo.Name,
+		"release":                rh.Release.ID,
+		"tag":                    rh.Release.Tag,
+		"url":                    rh.Release.Link,
+	})
+	l.Infof("Release %s.", rh.RawAction)
+
+	// lets invoke the agent creation async as this can take a little while
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		c := 0
+		repo := rh.Repo
+		agent, err := s.CreateAgent(l, repo.Namespace, repo.Name, rh.Release.Tag)
+		if err != nil {
+			agent.Logger.WithError(err).Error("Error creating agent for ReleaseEvent.")
+			return
+		}
+		for p, h := range s.getPlugins(repo.Namespace, repo.Name) {
+			if h.ReleaseEventHandler != nil {
+				s.wg.Add(1)
+				c++
+				go func(p string, h plugins.ReleaseEventHandler) {
+					defer s.wg.Done()
+					if err := h(agent, rh); err != nil {
+						agent.Logger.WithField("plugin", p).WithError(err).Error("Error handling ReleaseEvent.")
+					}
+				}(p, h.ReleaseEventHandler)
+			}
+		}
+		l.WithField("count", strconv.Itoa(c)).Info("number of release handlers")
+	}()
+}
+
 func (s *Server) reportErrorToPullRequest(l *logrus.Entry, agent plugins.Agent, repo scm.Repository, pr *scm.PullRequestHook, err error) {
 	fileLink := repo.Link + "/blob/" + pr.PullRequest.Sha + "/"
 	message := "failed to trigger Pull Request pipeline\n" + util.ErrorToMarkdown(err, fileLink)
@@ -458,6 +628,7 @@ func (s *Server) initializeFileBrowser(token string, gitCloneUser, gitServerURL
 	if err != nil {
 		return errors.Wrapf(err, "failed to create git client factory for server %s", gitServerURL)
 	}
+	s.GitClientFactory = gitFactory
 	fb := filebrowser.NewFileBrowserFromGitClient(gitFactory)
 	s.FileBrowsers, err = filebrowser.NewFileBrowsers(gitServerURL, fb)
 	if err != nil {
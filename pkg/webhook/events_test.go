@@ -0,0 +1,85 @@
+package webhook
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm"
+	scmfake "github.com/jenkins-x/go-scm/scm/driver/fake"
+	"github.com/jenkins-x/lighthouse/pkg/config"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIssueAndStatusEventsDispatch(t *testing.T) {
+	var lock sync.Mutex
+	var issues []scm.IssueHook
+	var statuses []scm.StatusHook
+	plugins.RegisterPlugin("test-issue-and-status", plugins.Plugin{
+		IssueHandler: func(_ plugins.Agent, i scm.IssueHook) error {
+			lock.Lock()
+			defer lock.Unlock()
+			issues = append(issues, i)
+			return nil
+		},
+		StatusEventHandler: func(_ plugins.Agent, s scm.StatusHook) error {
+			lock.Lock()
+			defer lock.Unlock()
+			statuses = append(statuses, s)
+			return nil
+		},
+	})
+
+	configAgent := &config.Agent{}
+	configAgent.Set(&config.Config{})
+	pluginAgent := &plugins.ConfigAgent{}
+	pluginAgent.Set(&plugins.Configuration{
+		Plugins: map[string][]string{"org/repo": {"test-issue-and-status"}},
+	})
+	scmClient, _ := scmfake.NewDefault()
+	o := &WebhooksController{
+		server: &Server{
+			ConfigAgent: configAgent,
+			Plugins:     pluginAgent,
+			Metrics:     NewMetrics(),
+			ClientAgent: &plugins.ClientAgent{
+				BotName:           "bot",
+				SCMProviderClient: scmClient,
+			},
+		},
+	}
+	repo := scm.Repository{Namespace: "org", Name: "repo", FullName: "org/repo"}
+
+	issueCount := testutil.ToFloat64(webhookCounter.WithLabelValues(string(scm.WebhookKindIssue)))
+	statusCount := testutil.ToFloat64(webhookCounter.WithLabelValues(string(scm.WebhookKindStatus)))
+
+	l := logrus.WithField("test", t.Name())
+	_, message, err := o.ProcessWebHook(l, &scm.IssueHook{
+		Action: scm.ActionClose,
+		Repo:   repo,
+		Issue:  scm.Issue{Number: 1, Closed: true},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "processed issue hook", message)
+
+	_, message, err = o.ProcessWebHook(l, &scm.StatusHook{
+		Repo:  repo,
+		Label: scm.Label{Name: "ci/external"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "processed status hook", message)
+
+	o.server.wg.Wait()
+
+	require.Len(t, issues, 1)
+	assert.Equal(t, scm.ActionClose, issues[0].Action)
+	assert.Equal(t, 1, issues[0].Issue.Number)
+	require.Len(t, statuses, 1)
+	assert.Equal(t, "ci/external", statuses[0].Label.Name)
+
+	assert.Equal(t, issueCount+1, testutil.ToFloat64(webhookCounter.WithLabelValues(string(scm.WebhookKindIssue))))
+	assert.Equal(t, statusCount+1, testutil.ToFloat64(webhookCounter.WithLabelValues(string(scm.WebhookKindStatus))))
+}
@@ -19,11 +19,14 @@ package webhook
 // We need to empty import all enabled plugins so that they will be linked into
 // any hook binary.
 import (
-	_ "github.com/jenkins-x/lighthouse/pkg/plugins/approve" // Import all enabled plugins.
+	_ "github.com/jenkins-x/lighthouse/pkg/plugins/abort" // Import all enabled plugins.
+	_ "github.com/jenkins-x/lighthouse/pkg/plugins/approve"
 	_ "github.com/jenkins-x/lighthouse/pkg/plugins/assign"
 	_ "github.com/jenkins-x/lighthouse/pkg/plugins/blockade"
+	_ "github.com/jenkins-x/lighthouse/pkg/plugins/blunderbuss"
 	_ "github.com/jenkins-x/lighthouse/pkg/plugins/branchcleaner"
 	_ "github.com/jenkins-x/lighthouse/pkg/plugins/cat"
+	_ "github.com/jenkins-x/lighthouse/pkg/plugins/cherrypicker"
 	_ "github.com/jenkins-x/lighthouse/pkg/plugins/cherrypickunapproved"
 	_ "github.com/jenkins-x/lighthouse/pkg/plugins/dog"
 	_ "github.com/jenkins-x/lighthouse/pkg/plugins/help"
@@ -33,9 +36,12 @@ import (
 	_ "github.com/jenkins-x/lighthouse/pkg/plugins/lifecycle"
 	_ "github.com/jenkins-x/lighthouse/pkg/plugins/milestone"
 	_ "github.com/jenkins-x/lighthouse/pkg/plugins/milestonestatus"
+	_ "github.com/jenkins-x/lighthouse/pkg/plugins/needsrebase"
 	_ "github.com/jenkins-x/lighthouse/pkg/plugins/override"
 	_ "github.com/jenkins-x/lighthouse/pkg/plugins/owners-label"
 	_ "github.com/jenkins-x/lighthouse/pkg/plugins/pony"
+	_ "github.com/jenkins-x/lighthouse/pkg/plugins/requirematchinglabel"
+	_ "github.com/jenkins-x/lighthouse/pkg/plugins/requiresig"
 	_ "github.com/jenkins-x/lighthouse/pkg/plugins/shrug"
 	_ "github.com/jenkins-x/lighthouse/pkg/plugins/sigmention"
 	_ "github.com/jenkins-x/lighthouse/pkg/plugins/size"
@@ -0,0 +1,143 @@
+package webhook
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/config"
+	"github.com/jenkins-x/lighthouse/pkg/filebrowser"
+	"github.com/jenkins-x/lighthouse/pkg/plugins/trigger"
+	"github.com/jenkins-x/lighthouse/pkg/triggerconfig/inrepo"
+	"github.com/jenkins-x/lighthouse/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// RunPeriodicPath the URL path of the endpoint running a periodic job on demand
+	RunPeriodicPath = "/run-periodic"
+
+	// adminTokenEnvVar the environment variable containing the token required by the admin endpoints.
+	// They are disabled if it is not set.
+	adminTokenEnvVar = "LIGHTHOUSE_ADMIN_TOKEN"
+)
+
+// RunPeriodicRequest is the body of a request to the run periodic endpoint
+type RunPeriodicRequest struct {
+	// Org is the owner of the repository of the periodic
+	Org string `json:"org"`
+	// Repo is the name of the repository of the periodic
+	Repo string `json:"repo"`
+	// Name is the name of the periodic
+	Name string `json:"name"`
+	// Parameters override the values of the parameters of the pipeline
+	Parameters map[string]string `json:"parameters,omitempty"`
+}
+
+// HandleRunPeriodic launches the periodic job described by the JSON body of a POST request and returns the
+// created LighthouseJob
+func (o *WebhooksController) HandleRunPeriodic(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		responseHTTPError(w, http.StatusMethodNotAllowed, fmt.Sprintf("405 Method Not Allowed: %s", r.Method))
+		return
+	}
+	if !authorizedAdminRequest(r) {
+		responseHTTPError(w, http.StatusUnauthorized, "401 Unauthorized")
+		return
+	}
+	req := &RunPeriodicRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		responseHTTPError(w, http.StatusBadRequest, fmt.Sprintf("400 Bad Request: failed to parse the request: %s", err.Error()))
+		return
+	}
+	if req.Org == "" || req.Repo == "" || req.Name == "" {
+		responseHTTPError(w, http.StatusBadRequest, "400 Bad Request: the org, repo and name of the periodic are required")
+		return
+	}
+
+	l := logrus.WithFields(map[string]interface{}{
+		"Operation": "RunPeriodic",
+		"Namespace": req.Org,
+		"Name":      req.Repo,
+		"Periodic":  req.Name,
+	})
+	cfg, err := o.periodicConfig(req.Org, req.Repo)
+	if err != nil {
+		l.WithError(err).Error("failed to load the configuration")
+		responseHTTPError(w, http.StatusInternalServerError, fmt.Sprintf("500 Internal Server Error: %s", err.Error()))
+		return
+	}
+	p, err := trigger.FindPeriodic(cfg.Periodics, req.Org, req.Repo, req.Name)
+	if err != nil {
+		responseHTTPError(w, http.StatusConflict, fmt.Sprintf("409 Conflict: %s", err.Error()))
+		return
+	}
+	if p == nil {
+		responseHTTPError(w, http.StatusNotFound, fmt.Sprintf("404 Not Found: no periodic job named %s in %s/%s", req.Name, req.Org, req.Repo))
+		return
+	}
+
+	c := trigger.Client{
+		Config:         cfg,
+		LauncherClient: o.launcher,
+		Logger:         l,
+	}
+	if o.DryRun || isDryRunRequest(r) {
+		c.LauncherClient = &dryRunLauncher{recorder: newDryRunRecorder()}
+	}
+	lhjob, err := trigger.RunPeriodic(c, req.Org, req.Repo, req.Name, req.Parameters)
+	if err != nil {
+		if errors.Is(err, trigger.ErrParametersNotSupported) {
+			responseHTTPError(w, http.StatusBadRequest, fmt.Sprintf("400 Bad Request: %s", err.Error()))
+			return
+		}
+		l.WithError(err).Error("failed to run the periodic")
+		responseHTTPError(w, http.StatusInternalServerError, fmt.Sprintf("500 Internal Server Error: %s", err.Error()))
+		return
+	}
+	writeJSON(w, lhjob)
+}
+
+// periodicConfig returns the configuration of the repository, including its in repository configuration
+func (o *WebhooksController) periodicConfig(org, repo string) (*config.Config, error) {
+	cfg := o.server.ConfigAgent.Config()
+	if cfg == nil {
+		return nil, errors.New("no config available. maybe the ConfigMap got deleted")
+	}
+	if !cfg.InRepoConfigEnabled(scm.Join(org, repo)) {
+		return cfg, nil
+	}
+	if o.server.FileBrowsers == nil {
+		_, _, serverURL, _, err := util.GetSCMClient("", o.server.ConfigAgent.Config)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create SCM client")
+		}
+		gitCloneUser, token, err := getCredentials(util.GetGitHubAppSecretDir(), serverURL, org, o.server.ConfigAgent.Config)
+		if err != nil {
+			return nil, err
+		}
+		if err := o.server.initializeFileBrowser(token, gitCloneUser, o.gitServerURL); err != nil {
+			return nil, err
+		}
+	}
+	cfg, _, err := inrepo.Generate(o.server.FileBrowsers, filebrowser.NewFetchCache(), inrepo.NewResolverCache(), cfg, o.server.Plugins.Config(), org, repo, "")
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to calculate in repo config")
+	}
+	return cfg, nil
+}
+
+// authorizedAdminRequest returns true if the request has the bearer token required by the admin endpoints
+func authorizedAdminRequest(r *http.Request) bool {
+	token := os.Getenv(adminTokenEnvVar)
+	if token == "" {
+		return false
+	}
+	actual := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return subtle.ConstantTimeCompare([]byte(actual), []byte(token)) == 1
+}
@@ -0,0 +1,101 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	fakelauncher "github.com/jenkins-x/lighthouse/pkg/launcher/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+func TestHandleRunPeriodic(t *testing.T) {
+	t.Setenv(adminTokenEnvVar, "admin-token")
+	t.Setenv("HMAC_TOKEN", "hmac-token")
+
+	nightly := job.Periodic{
+		Base: job.Base{
+			Name:           "nightly",
+			Agent:          job.TektonPipelineAgent,
+			MaxConcurrency: 1,
+		},
+		Cron: "0 4 * * *",
+	}
+	nightly.SetSourceRepo("org/repo")
+	global := job.Periodic{Base: job.Base{Name: "global"}, Cron: "0 4 * * *"}
+	failing := job.Periodic{Base: job.Base{Name: "failing"}, Cron: "0 4 * * *"}
+	failing.SetSourceRepo("org/repo")
+	cfg := &config.Config{}
+	cfg.Periodics = []job.Periodic{global, nightly, failing}
+	configAgent := &config.Agent{}
+	configAgent.Set(cfg)
+	launcher := fakelauncher.NewLauncher()
+	launcher.FailJobs = sets.NewString("failing")
+	o := &WebhooksController{
+		server:   &Server{ConfigAgent: configAgent},
+		launcher: launcher,
+	}
+
+	request := func(method, token string, body interface{}) *httptest.ResponseRecorder {
+		data, err := json.Marshal(body)
+		require.NoError(t, err)
+		req := httptest.NewRequest(method, RunPeriodicPath, bytes.NewReader(data))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		o.HandleRunPeriodic(w, req)
+		return w
+	}
+
+	valid := &RunPeriodicRequest{Org: "org", Repo: "repo", Name: "nightly"}
+	assert.Equal(t, http.StatusMethodNotAllowed, request(http.MethodGet, "admin-token", valid).Code)
+	assert.Equal(t, http.StatusUnauthorized, request(http.MethodPost, "", valid).Code)
+	assert.Equal(t, http.StatusUnauthorized, request(http.MethodPost, "wrong", valid).Code)
+	assert.Equal(t, http.StatusUnauthorized, request(http.MethodPost, "hmac-token", valid).Code, "the HMAC token should not be accepted")
+	assert.Equal(t, http.StatusBadRequest, request(http.MethodPost, "admin-token", &RunPeriodicRequest{Org: "org", Repo: "repo"}).Code)
+	assert.Equal(t, http.StatusNotFound, request(http.MethodPost, "admin-token", &RunPeriodicRequest{Org: "org", Repo: "repo", Name: "weekly"}).Code)
+	assert.Equal(t, http.StatusNotFound, request(http.MethodPost, "admin-token", &RunPeriodicRequest{Org: "org", Repo: "repo", Name: "global"}).Code)
+	assert.Equal(t, http.StatusNotFound, request(http.MethodPost, "admin-token", &RunPeriodicRequest{Org: "org", Repo: "other", Name: "nightly"}).Code)
+	assert.Equal(t, http.StatusBadRequest, request(http.MethodPost, "admin-token", &RunPeriodicRequest{Org: "org", Repo: "repo", Name: "nightly", Parameters: map[string]string{"A": "b"}}).Code)
+	assert.Equal(t, http.StatusInternalServerError, request(http.MethodPost, "admin-token", &RunPeriodicRequest{Org: "org", Repo: "repo", Name: "failing"}).Code)
+	assert.Empty(t, launcher.Pipelines)
+
+	w := request(http.MethodPost, "admin-token", valid)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	lhjob := &v1alpha1.LighthouseJob{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), lhjob))
+	assert.Equal(t, job.PeriodicJob, lhjob.Spec.Type)
+	assert.Equal(t, "nightly", lhjob.Spec.Job)
+	assert.Equal(t, 1, lhjob.Spec.MaxConcurrency)
+	assert.Equal(t, "org", lhjob.Spec.Refs.Org)
+	assert.Equal(t, "repo", lhjob.Spec.Refs.Repo)
+	require.Len(t, launcher.Pipelines, 1)
+
+	cfg.Periodics = append(cfg.Periodics, nightly)
+	assert.Equal(t, http.StatusConflict, request(http.MethodPost, "admin-token", valid).Code)
+	cfg.Periodics = cfg.Periodics[:3]
+
+	o.DryRun = true
+	require.Equal(t, http.StatusOK, request(http.MethodPost, "admin-token", valid).Code)
+	assert.Len(t, launcher.Pipelines, 1, "no job should be launched in dry-run mode")
+}
+
+func TestHandleRunPeriodicWithoutAdminToken(t *testing.T) {
+	t.Setenv(adminTokenEnvVar, "")
+	t.Setenv("HMAC_TOKEN", "hmac-token")
+
+	o := &WebhooksController{launcher: fakelauncher.NewLauncher()}
+	req := httptest.NewRequest(http.MethodPost, RunPeriodicPath, bytes.NewReader([]byte(`{"org": "org", "repo": "repo", "name": "nightly"}`)))
+	req.Header.Set("Authorization", "Bearer hmac-token")
+	w := httptest.NewRecorder()
+	o.HandleRunPeriodic(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code, "the endpoint should be disabled without an admin token")
+}
@@ -2,6 +2,7 @@ package webhook
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -20,8 +21,11 @@ import (
 	"github.com/jenkins-x/lighthouse/pkg/git"
 	"github.com/jenkins-x/lighthouse/pkg/launcher"
 	"github.com/jenkins-x/lighthouse/pkg/metrics"
+	"github.com/jenkins-x/lighthouse/pkg/periodic"
 	"github.com/jenkins-x/lighthouse/pkg/plugins"
 	"github.com/jenkins-x/lighthouse/pkg/plugins/trigger"
+	"github.com/jenkins-x/lighthouse/pkg/pubsub"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
 	"github.com/jenkins-x/lighthouse/pkg/util"
 	"github.com/jenkins-x/lighthouse/pkg/version"
 	"github.com/jenkins-x/lighthouse/pkg/watcher"
@@ -31,9 +35,20 @@ import (
 	kubeclient "k8s.io/client-go/kubernetes"
 )
 
+const (
+	webhookOperation = "Webhook"
+	pollOperation    = "Pollhook"
+
+	// periodicCatchUpEnvVar is the environment variable of the policy applied to the schedules of the
+	// periodics missed while no replica was scheduling them, see periodic.CatchUpPolicy
+	periodicCatchUpEnvVar = "LIGHTHOUSE_PERIODIC_CATCH_UP"
+)
+
 // WebhooksController holds the command line arguments
 type WebhooksController struct {
 	ConfigMapWatcher *watcher.ConfigMapWatcher
+	// DryRun processes every event in dry-run mode, recording the changes instead of making them
+	DryRun bool
 
 	path                    string
 	namespace               string
@@ -44,19 +59,24 @@ type WebhooksController struct {
 	gitServerURL            string
 	gitClient               git.Client
 	launcher                launcher.PipelineLauncher
+	kubeClient              kubeclient.Interface
 	disabledExternalPlugins []string
 	logWebHooks             bool
+	deliveries              *DeliveryLog
+	externalPlugins         *util.ExternalPluginDispatcher
 }
 
 // NewWebhooksController creates and configures the controller
 func NewWebhooksController(path, namespace, botName, pluginFilename, configFilename string) (*WebhooksController, error) {
 	o := &WebhooksController{
-		path:           path,
-		namespace:      namespace,
-		pluginFilename: pluginFilename,
-		configFilename: configFilename,
-		botName:        botName,
-		logWebHooks:    os.Getenv("LIGHTHOUSE_LOG_WEBHOOKS") == "true",
+		path:            path,
+		namespace:       namespace,
+		pluginFilename:  pluginFilename,
+		configFilename:  configFilename,
+		botName:         botName,
+		logWebHooks:     os.Getenv("LIGHTHOUSE_LOG_WEBHOOKS") == "true",
+		deliveries:      newDeliveryLogFromEnv(),
+		externalPlugins: util.NewExternalPluginDispatcher(util.NewDeadLetterStoreFromEnv()),
 	}
 	if o.logWebHooks {
 		logrus.Info("enabling webhook logging")
@@ -78,6 +98,7 @@ func NewWebhooksController(path, namespace, botName, pluginFilename, configFilen
 	o.gitClient = gitClient
 
 	o.launcher = launcher.NewLauncher(lhClient, o.namespace)
+	o.kubeClient = kubeClient
 
 	return o, nil
 }
@@ -90,6 +111,53 @@ func (o *WebhooksController) CleanupGitClientDir() {
 	}
 }
 
+// PullPubSubMessages triggers the jobs requested by the messages of the Pub/Sub subscriptions
+// listed in the configuration until the context is done
+func (o *WebhooksController) PullPubSubMessages(ctx context.Context) {
+	if o.DryRun {
+		logrus.Info("not pulling Pub/Sub messages in dry-run mode")
+		return
+	}
+	subscriber := &pubsub.Subscriber{
+		ConfigAgent:    o.server.ConfigAgent,
+		LauncherClient: o.launcher,
+	}
+	pubsub.NewPullServer(subscriber, pubsub.NewRESTClient()).Run(ctx)
+}
+
+// RunPeriodicScheduler launches the periodic jobs when they are due, while this replica is elected as
+// the scheduler, until the context is done
+func (o *WebhooksController) RunPeriodicScheduler(ctx context.Context) {
+	if o.DryRun {
+		logrus.Info("not scheduling periodics in dry-run mode")
+		return
+	}
+	catchUp, err := periodic.ParseCatchUpPolicy(os.Getenv(periodicCatchUpEnvVar))
+	if err != nil {
+		logrus.WithError(err).Errorf("invalid $%s, using the %s policy", periodicCatchUpEnvVar, periodic.CatchUpOnce)
+		catchUp = periodic.CatchUpOnce
+	}
+	lock, err := periodic.NewLeaseLock(o.kubeClient, o.namespace)
+	if err != nil {
+		logrus.WithError(err).Error("failed to create the periodic scheduler lease lock, periodics will not be scheduled")
+		return
+	}
+	scheduler := periodic.NewScheduler(
+		o.kubeClient.CoreV1().ConfigMaps(o.namespace),
+		o.kubeClient.BatchV1().CronJobs(o.namespace),
+		o.launcher,
+		catchUp,
+	)
+	scheduler.RunWithLeaderElection(ctx, lock)
+}
+
+// StopExternalPluginDeliveries cancels the deliveries to the external plugins and their retries once the context
+// is done, so that they do not delay the shutdown
+func (o *WebhooksController) StopExternalPluginDeliveries(ctx context.Context) {
+	<-ctx.Done()
+	o.externalPlugins.Shutdown()
+}
+
 // Health returns either HTTP 204 if the service is healthy, otherwise nothing ('cos it's dead).
 func (o *WebhooksController) Health(w http.ResponseWriter, r *http.Request) {
 	logrus.Debug("Health check")
@@ -132,45 +200,55 @@ func (o *WebhooksController) isReady() bool {
 
 // HandleWebhookRequests handles incoming webhook events
 func (o *WebhooksController) HandleWebhookRequests(w http.ResponseWriter, r *http.Request) {
-	o.handleWebhookOrPollRequest(w, r, "Webhook", func(scmClient *scm.Client, r *http.Request) (scm.Webhook, error) {
-		return scmClient.Webhooks.Parse(r, o.secretFn)
-	})
+	o.handleWebhookOrPollRequest(w, r, webhookOperation, "", o.parseWebhookRequest)
 }
 
 // HandlePollingRequests handles incoming polling events
 func (o *WebhooksController) HandlePollingRequests(w http.ResponseWriter, r *http.Request) {
-	o.handleWebhookOrPollRequest(w, r, "Pollhook", func(scmClient *scm.Client, r *http.Request) (scm.Webhook, error) {
-		data, err := io.ReadAll(r.Body)
-		if err != nil {
-			return nil, errors.Wrapf(err, "failed to read poll payload")
-		}
-		wh := &scm.WebhookWrapper{}
-		err = json.Unmarshal(data, wh)
-		if err != nil {
-			return nil, errors.Wrapf(err, "failed to unmarshal WebhookWrapper payload")
-		}
-		hook, err := wh.ToWebhook()
-		if err != nil {
-			return nil, err
-		}
+	o.handleWebhookOrPollRequest(w, r, pollOperation, "", o.parsePollRequest)
+}
 
-		key, err := o.secretFn(hook)
-		if err != nil {
-			return hook, err
-		} else if key == "" {
-			return hook, nil
-		}
+func (o *WebhooksController) parseWebhookRequest(scmClient *scm.Client, r *http.Request) (scm.Webhook, error) {
+	return scmClient.Webhooks.Parse(r, o.secretFn)
+}
 
-		sig := r.Header.Get("X-Hub-Signature")
-		if !hmac.ValidatePrefix(data, []byte(key), sig) {
-			return hook, scm.ErrSignatureInvalid
-		}
+func (o *WebhooksController) parsePollRequest(scmClient *scm.Client, r *http.Request) (scm.Webhook, error) {
+	return parsePollRequest(r, o.secretFn)
+}
+
+// parsePollRequest parses the webhook sent by the poller, checking its signature with the key returned by secretFn
+func parsePollRequest(r *http.Request, secretFn func(scm.Webhook) (string, error)) (scm.Webhook, error) {
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read poll payload")
+	}
+	wh := &scm.WebhookWrapper{}
+	err = json.Unmarshal(data, wh)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal WebhookWrapper payload")
+	}
+	hook, err := wh.ToWebhook()
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := secretFn(hook)
+	if err != nil {
 		return hook, err
-	})
+	} else if key == "" {
+		return hook, nil
+	}
+
+	sig := r.Header.Get("X-Hub-Signature")
+	if !hmac.ValidatePrefix(data, []byte(key), sig) {
+		return hook, scm.ErrSignatureInvalid
+	}
+	return hook, err
 }
 
-// handleWebhookOrPollRequest handles incoming events
-func (o *WebhooksController) handleWebhookOrPollRequest(w http.ResponseWriter, r *http.Request, operation string, parseWebhook func(scmClient *scm.Client, r *http.Request) (scm.Webhook, error)) {
+// handleWebhookOrPollRequest handles incoming events, recording them in the delivery log.
+// replayOf is the GUID of the delivery being replayed, if any.
+func (o *WebhooksController) handleWebhookOrPollRequest(w http.ResponseWriter, r *http.Request, operation, replayOf string, parseWebhook func(scmClient *scm.Client, r *http.Request) (scm.Webhook, error)) {
 	if r.Method != http.MethodPost {
 		// liveness probe etc
 		logrus.WithField("method", r.Method).Debug("invalid http method so returning 200")
@@ -195,6 +273,18 @@ func (o *WebhooksController) handleWebhookOrPollRequest(w http.ResponseWriter, r
 	}
 
 	r.Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
+
+	var delivery *Delivery
+	if o.deliveries != nil {
+		delivery = newDelivery(operation, replayOf, r.Header, bodyBytes)
+		rw := &deliveryResponseWriter{ResponseWriter: w}
+		w = rw
+		defer func() {
+			rw.complete(delivery)
+			o.deliveries.Add(delivery)
+		}()
+	}
+
 	_, scmClient, serverURL, _, err := util.GetSCMClient("", cfg)
 	if err != nil {
 		logrus.Errorf("failed to create SCM scmClient: %s", err.Error())
@@ -215,6 +305,14 @@ func (o *WebhooksController) handleWebhookOrPollRequest(w http.ResponseWriter, r
 		responseHTTPError(w, http.StatusInternalServerError, "500 Internal Server Error: No webhook could be parsed")
 		return
 	}
+	if rh, ok := webhook.(*scm.ReleaseHook); ok {
+		webhook = &scmprovider.ReleaseEvent{ReleaseHook: *rh, RawAction: payloadAction(bodyBytes)}
+	}
+	if delivery != nil {
+		delivery.Kind = string(webhook.Kind())
+		delivery.Repository = webhook.Repository().FullName
+		delivery.verified = true
+	}
 
 	ghaSecretDir := util.GetGitHubAppSecretDir()
 
@@ -235,7 +333,7 @@ func (o *WebhooksController) handleWebhookOrPollRequest(w http.ResponseWriter, r
 	})
 	util.AddAuthToSCMClient(scmClient, token, ghaSecretDir != "")
 
-	o.server.ClientAgent = &plugins.ClientAgent{
+	clientAgent := &plugins.ClientAgent{
 		BotName:           util.GetBotName(cfg),
 		SCMProviderClient: scmClient,
 		KubernetesClient:  kubeClient,
@@ -251,6 +349,7 @@ func (o *WebhooksController) handleWebhookOrPollRequest(w http.ResponseWriter, r
 			return
 		}
 	}
+	clientAgent.GitClientFactory = o.server.GitClientFactory
 	entry := logrus.WithField(operation, webhook.Kind())
 	if o.disabledExternalPlugins == nil {
 		o.disabledExternalPlugins, err = externalplugincfg.LoadDisabledPlugins(entry, kubeClient, o.namespace)
@@ -260,14 +359,21 @@ func (o *WebhooksController) handleWebhookOrPollRequest(w http.ResponseWriter, r
 			return
 		}
 	}
+	external := util.ExternalPluginsForEvent(o.server.Plugins, string(webhook.Kind()), webhook.Repository().FullName, o.disabledExternalPlugins)
+
+	if o.DryRun || isDryRunRequest(r) {
+		o.handleDryRun(w, entry.WithField("DryRun", true), webhook, clientAgent, external)
+		return
+	}
 
+	o.server.ClientAgent = clientAgent
 	l, output, err := o.ProcessWebHook(entry, webhook)
 	if err != nil {
 		responseHTTPError(w, http.StatusInternalServerError, fmt.Sprintf("500 Internal Server Error: %s", err.Error()))
 	}
 	// Demux events only to external plugins that require this event.
-	if external := util.ExternalPluginsForEvent(o.server.Plugins, string(webhook.Kind()), webhook.Repository().FullName, o.disabledExternalPlugins); len(external) > 0 {
-		go util.CallExternalPluginsWithWebhook(l, external, webhook, util.HMACToken(), &o.server.wg)
+	if len(external) > 0 {
+		go o.externalPlugins.CallWithWebhook(l, external, webhook, util.HMACToken(), &o.server.wg)
 	}
 
 	_, err = w.Write([]byte(output))
@@ -296,6 +402,10 @@ func getCredentials(ghaSecretDir string, serverURL string, owner string, cfg fun
 
 // ProcessWebHook process a webhook
 func (o *WebhooksController) ProcessWebHook(l *logrus.Entry, webhook scm.Webhook) (*logrus.Entry, string, error) {
+	return o.processWebHook(o.server, l, webhook)
+}
+
+func (o *WebhooksController) processWebHook(server *Server, l *logrus.Entry, webhook scm.Webhook) (*logrus.Entry, string, error) {
 	repository := webhook.Repository()
 	fields := map[string]interface{}{
 		"Namespace": repository.Namespace,
@@ -308,8 +418,8 @@ func (o *WebhooksController) ProcessWebHook(l *logrus.Entry, webhook scm.Webhook
 	}
 
 	// increase webhook counter
-	if o.server.Metrics != nil && o.server.Metrics.WebhookCounter != nil {
-		o.server.Metrics.WebhookCounter.With(map[string]string{
+	if server.Metrics != nil && server.Metrics.WebhookCounter != nil {
+		server.Metrics.WebhookCounter.With(map[string]string{
 			"event_type": string(webhook.Kind()),
 		}).Inc()
 	}
@@ -327,8 +437,8 @@ func (o *WebhooksController) ProcessWebHook(l *logrus.Entry, webhook scm.Webhook
 	}
 	// If we are in GitHub App mode and have a populated config, check if the repository for this webhook is one we actually
 	// know about and error out if not.
-	if util.GetGitHubAppSecretDir() != "" && o.server.ConfigAgent != nil {
-		cfg := o.server.ConfigAgent.Config()
+	if util.GetGitHubAppSecretDir() != "" && server.ConfigAgent != nil {
+		cfg := server.ConfigAgent.Config()
 		if cfg != nil {
 			if len(cfg.GetPostsubmits(repository)) == 0 && len(cfg.GetPresubmits(repository)) == 0 {
 				l.Infof("webhook from unconfigured repository %s, returning error", repository.Link)
@@ -349,7 +459,7 @@ func (o *WebhooksController) ProcessWebHook(l *logrus.Entry, webhook scm.Webhook
 
 		l.Info("invoking Push handler")
 
-		o.server.handlePushEvent(l, pushHook)
+		server.handlePushEvent(l, pushHook)
 		return l, "processed push hook", nil
 	}
 	prHook, ok := webhook.(*scm.PullRequestHook)
@@ -366,7 +476,7 @@ func (o *WebhooksController) ProcessWebHook(l *logrus.Entry, webhook scm.Webhook
 
 		l.Info("invoking PR handler")
 
-		o.server.handlePullRequestEvent(l, prHook)
+		server.handlePullRequestEvent(l, prHook)
 		return l, "processed PR hook", nil
 	}
 	branchHook, ok := webhook.(*scm.BranchHook)
@@ -381,7 +491,7 @@ func (o *WebhooksController) ProcessWebHook(l *logrus.Entry, webhook scm.Webhook
 
 		l.Info("invoking branch handler")
 
-		o.server.handleBranchEvent(l, branchHook)
+		server.handleBranchEvent(l, branchHook)
 		return l, "processed branch hook", nil
 	}
 	issueCommentHook, ok := webhook.(*scm.IssueCommentHook)
@@ -402,7 +512,7 @@ func (o *WebhooksController) ProcessWebHook(l *logrus.Entry, webhook scm.Webhook
 
 		l.Info("invoking Issue Comment handler")
 
-		o.server.handleIssueCommentEvent(l, *issueCommentHook)
+		server.handleIssueCommentEvent(l, *issueCommentHook)
 		return l, "processed issue comment hook", nil
 	}
 	prCommentHook, ok := webhook.(*scm.PullRequestCommentHook)
@@ -427,7 +537,7 @@ func (o *WebhooksController) ProcessWebHook(l *logrus.Entry, webhook scm.Webhook
 
 		l.Info("invoking Issue Comment handler")
 
-		o.server.handlePullRequestCommentEvent(l, *prCommentHook)
+		server.handlePullRequestCommentEvent(l, *prCommentHook)
 		return l, "processed PR comment hook", nil
 	}
 	prReviewHook, ok := webhook.(*scm.ReviewHook)
@@ -448,9 +558,77 @@ func (o *WebhooksController) ProcessWebHook(l *logrus.Entry, webhook scm.Webhook
 
 		l.Info("invoking PR Review handler")
 
-		o.server.handleReviewEvent(l, *prReviewHook)
+		server.handleReviewEvent(l, *prReviewHook)
 		return l, "processed PR review hook", nil
 	}
+	tagHook, ok := webhook.(*scm.TagHook)
+	if ok {
+		sender := tagHook.Sender
+		fields["Action"] = tagHook.Action.String()
+		fields["Ref.Name"] = tagHook.Ref.Name
+		fields["Ref.Sha"] = tagHook.Ref.Sha
+		fields["Sender.Name"] = sender.Name
+		fields["Sender.Login"] = sender.Login
+		l = l.WithFields(fields)
+
+		l.Info("invoking Tag handler")
+
+		server.handleTagEvent(l, *tagHook)
+		return l, "processed tag hook", nil
+	}
+	if rh, ok := webhook.(*scm.ReleaseHook); ok {
+		webhook = &scmprovider.ReleaseEvent{ReleaseHook: *rh}
+	}
+	releaseHook, ok := webhook.(*scmprovider.ReleaseEvent)
+	if ok {
+		release := releaseHook.Release
+		sender := releaseHook.Sender
+		fields["Action"] = releaseHook.RawAction
+		fields["Release.ID"] = release.ID
+		fields["Release.Tag"] = release.Tag
+		fields["Release.Title"] = release.Title
+		fields["Release.Draft"] = release.Draft
+		fields["Sender.Name"] = sender.Name
+		fields["Sender.Login"] = sender.Login
+		l = l.WithFields(fields)
+
+		l.Info("invoking Release handler")
+
+		server.handleReleaseEvent(l, *releaseHook)
+		return l, "processed release hook", nil
+	}
+	issueHook, ok := webhook.(*scm.IssueHook)
+	if ok {
+		action := issueHook.Action
+		issue := issueHook.Issue
+		sender := issueHook.Sender
+		fields["Action"] = action.String()
+		fields["Issue.Number"] = issue.Number
+		fields["Issue.Title"] = issue.Title
+		fields["Issue.Body"] = issue.Body
+		fields["Sender.Body"] = sender.Name
+		fields["Sender.Login"] = sender.Login
+		fields["Kind"] = "IssueHook"
+		l = l.WithFields(fields)
+
+		l.Info("invoking Issue handler")
+
+		server.handleIssueEvent(l, *issueHook)
+		return l, "processed issue hook", nil
+	}
+	statusHook, ok := webhook.(*scm.StatusHook)
+	if ok {
+		sender := statusHook.Sender
+		fields["Label"] = statusHook.Label.Name
+		fields["Sender.Name"] = sender.Name
+		fields["Sender.Login"] = sender.Login
+		l = l.WithFields(fields)
+
+		l.Info("invoking Status handler")
+
+		server.handleStatusEvent(l, *statusHook)
+		return l, "processed status hook", nil
+	}
 	deploymentStatusHook, ok := webhook.(*scm.DeploymentStatusHook)
 	if ok {
 		action := deploymentStatusHook.Action
@@ -463,7 +641,7 @@ func (o *WebhooksController) ProcessWebHook(l *logrus.Entry, webhook scm.Webhook
 
 		l.Info("invoking PR Review handler")
 
-		o.server.handleDeploymentStatusEvent(l, *deploymentStatusHook)
+		server.handleDeploymentStatusEvent(l, *deploymentStatusHook)
 		return l, "processed PR review hook", nil
 	}
 	l.Debugf("unknown kind %s webhook %#v", webhook.Kind(), webhook)
@@ -544,6 +722,17 @@ func (o *WebhooksController) createHookServer(kc kubeclient.Interface) (*Server,
 	return server, nil
 }
 
+// payloadAction returns the action of a webhook payload, or an empty string if it has none
+func payloadAction(body []byte) string {
+	payload := struct {
+		Action string `json:"action"`
+	}{}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return ""
+	}
+	return payload.Action
+}
+
 func responseHTTPError(w http.ResponseWriter, statusCode int, response string) {
 	logrus.WithFields(logrus.Fields{
 		"response":    response,